@@ -4,11 +4,12 @@ go 1.24.11
 
 require (
 	github.com/deepgram/deepgram-go-sdk/v3 v3.5.0
+	github.com/dvonthenen/websocket v1.5.1-dyv.2
 	github.com/plexusone/omnivoice-core v0.5.0
+	k8s.io/klog/v2 v2.130.1
 )
 
 require (
-	github.com/dvonthenen/websocket v1.5.1-dyv.2 // indirect
 	github.com/fatih/color v1.18.0 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
 	github.com/gorilla/schema v1.4.1 // indirect
@@ -16,5 +17,4 @@ require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.41.0 // indirect
-	k8s.io/klog/v2 v2.130.1 // indirect
 )
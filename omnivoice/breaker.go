@@ -0,0 +1,118 @@
+package omnivoice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// ErrCircuitOpen is returned by providers instead of contacting Deepgram
+// while their circuit breaker is open.
+var ErrCircuitOpen = errors.New("deepgram: circuit breaker open")
+
+// CircuitBreaker fails requests fast after repeated Deepgram failures.
+//
+// After FailureThreshold consecutive failures the breaker opens and Allow
+// returns ErrCircuitOpen. Once ResetTimeout has passed it lets a single
+// trial request through; success closes the breaker and failure opens it
+// again for another ResetTimeout.
+//
+// Only failures that indicate Deepgram is unhealthy count: network errors,
+// 5xx responses, and 429 rate limiting. Other 4xx responses and context
+// cancellation describe the request, not the service, and are ignored.
+//
+// A nil *CircuitBreaker allows every request.
+type CircuitBreaker struct {
+	failureThreshold int
+	resetTimeout     time.Duration
+
+	// now returns the current time; replaced in tests
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	trial    bool
+}
+
+// NewCircuitBreaker returns a breaker that opens after failureThreshold
+// consecutive failures and half-opens after resetTimeout. A threshold of
+// zero or less returns nil, which disables the breaker.
+func NewCircuitBreaker(failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		resetTimeout:     resetTimeout,
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen if
+// not. Every allowed request must be followed by a call to Record.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.resetTimeout {
+		return ErrCircuitOpen
+	}
+
+	// Half-open: let one trial request through
+	b.trial = true
+	return nil
+}
+
+// Record records the outcome of a request allowed by Allow.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	trial := b.trial
+	b.trial = false
+
+	if !isServiceFailure(err) {
+		if err == nil || trial {
+			b.failures = 0
+			b.open = false
+		}
+		return
+	}
+
+	b.failures++
+	if trial || b.failures >= b.failureThreshold {
+		b.open = true
+		b.openedAt = b.now()
+	}
+}
+
+// isServiceFailure reports whether err indicates that Deepgram is
+// unavailable rather than that the request was rejected.
+func isServiceFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *interfaces.StatusError
+	if errors.As(err, &se) && se.Resp != nil {
+		code := se.Resp.StatusCode
+		return code >= 500 || code == http.StatusTooManyRequests
+	}
+	return true
+}
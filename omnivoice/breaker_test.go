@@ -0,0 +1,97 @@
+package omnivoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+func statusErr(code int) error {
+	return &interfaces.StatusError{Resp: &http.Response{StatusCode: code}}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	fail := func() {
+		t.Helper()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() = %v, want nil", err)
+		}
+		b.Record(statusErr(http.StatusServiceUnavailable))
+	}
+
+	// Opens after two consecutive failures
+	fail()
+	fail()
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() after threshold = %v, want ErrCircuitOpen", err)
+	}
+
+	// Half-opens after the reset timeout, allowing a single trial
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after reset = %v, want trial", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() during trial = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed trial reopens immediately
+	b.Record(errors.New("connection refused"))
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() after failed trial = %v, want ErrCircuitOpen", err)
+	}
+
+	// A successful trial closes the breaker
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after second reset = %v, want trial", err)
+	}
+	b.Record(nil)
+	fail()
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() after recovery and one failure = %v, want nil", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresRequestErrors(t *testing.T) {
+	b := NewCircuitBreaker(1, time.Minute)
+
+	for _, err := range []error{
+		statusErr(http.StatusBadRequest),
+		statusErr(http.StatusUnauthorized),
+		fmt.Errorf("wrapped: %w", context.Canceled),
+	} {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() = %v, want nil", err)
+		}
+		b.Record(err)
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() = %v, want nil after request errors", err)
+	}
+
+	b.Record(statusErr(http.StatusTooManyRequests))
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() after 429 = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Minute)
+	if b != nil {
+		t.Fatalf("NewCircuitBreaker(0) = %v, want nil", b)
+	}
+	b.Record(errors.New("boom"))
+	if err := b.Allow(); err != nil {
+		t.Errorf("nil breaker Allow() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,58 @@
+package omnivoice
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
+)
+
+// CallbackTokenHeader is the header Deepgram sets on callback requests to
+// the identifier of the API key that submitted the transcription.
+const CallbackTokenHeader = "dg-token"
+
+// ErrInvalidCallbackSignature is returned by VerifyCallbackSignature when a
+// callback request does not carry the expected Deepgram token.
+var ErrInvalidCallbackSignature = errors.New("invalid Deepgram callback signature")
+
+// VerifyCallbackSignature checks that a callback request came from Deepgram
+// by comparing its dg-token header, in constant time, with secret, the
+// identifier of the API key used to submit the transcription.
+//
+// Deepgram authenticates callbacks with this token rather than an HMAC of
+// the payload, so body is not covered by the check; serve the callback URL
+// over HTTPS so the body cannot be altered in transit. body is accepted so
+// callers do not need to change if Deepgram adds payload signing.
+func VerifyCallbackSignature(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return errors.New("callback secret is required")
+	}
+
+	token := header.Get(CallbackTokenHeader)
+	if token == "" {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidCallbackSignature, CallbackTokenHeader)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrInvalidCallbackSignature
+	}
+
+	return nil
+}
+
+// ParseCallback parses the transcription result Deepgram posts to a callback
+// URL. The result's RequestID is the ID SubmitTranscription returned for
+// the request, for matching deliveries to submitted jobs.
+func ParseCallback(body []byte) (*TranscriptionResult, error) {
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid Deepgram callback body: %w", err)
+	}
+	if resp.Results == nil {
+		return nil, errors.New("invalid Deepgram callback body: missing results")
+	}
+
+	return PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{}), nil
+}
@@ -0,0 +1,81 @@
+package omnivoice
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const sampleCallbackBody = `{
+	"metadata": {"request_id": "5d1a2b3c-0000-4000-8000-123456789abc", "duration": 2.5},
+	"results": {"channels": [{"alternatives": [{
+		"transcript": "hello callback",
+		"confidence": 0.98,
+		"words": [
+			{"word": "hello", "start": 0.1, "end": 0.5, "confidence": 0.99},
+			{"word": "callback", "start": 0.6, "end": 1.2, "confidence": 0.97}
+		]
+	}]}]}
+}`
+
+func TestVerifyCallbackSignature(t *testing.T) {
+	const keyID = "2f6a8c4e-1b3d-4e5f-9a7b-0c1d2e3f4a5b"
+	body := []byte(sampleCallbackBody)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"matching token", keyID, false},
+		{"wrong token", "00000000-0000-0000-0000-000000000000", true},
+		{"missing token", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.token != "" {
+				header.Set(CallbackTokenHeader, tt.token)
+			}
+
+			err := VerifyCallbackSignature(header, body, keyID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyCallbackSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidCallbackSignature) {
+				t.Errorf("VerifyCallbackSignature() error = %v, want ErrInvalidCallbackSignature", err)
+			}
+		})
+	}
+
+	if err := VerifyCallbackSignature(http.Header{}, body, ""); err == nil {
+		t.Error("VerifyCallbackSignature() with empty secret should fail")
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	result, err := ParseCallback([]byte(sampleCallbackBody))
+	if err != nil {
+		t.Fatalf("ParseCallback() error = %v", err)
+	}
+	if result.Text != "hello callback" {
+		t.Errorf("Text = %q, want %q", result.Text, "hello callback")
+	}
+	if result.RequestID != "5d1a2b3c-0000-4000-8000-123456789abc" {
+		t.Errorf("RequestID = %q, want the submitted request's ID", result.RequestID)
+	}
+	if result.Duration != 2500*time.Millisecond {
+		t.Errorf("Duration = %v, want 2.5s", result.Duration)
+	}
+	if len(result.Segments) != 1 || len(result.Segments[0].Words) != 2 {
+		t.Errorf("Segments = %+v, want one segment with two words", result.Segments)
+	}
+
+	for _, body := range []string{"not json", `{"metadata":{}}`} {
+		if _, err := ParseCallback([]byte(body)); err == nil {
+			t.Errorf("ParseCallback(%q) should fail", body)
+		}
+	}
+}
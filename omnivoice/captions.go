@@ -0,0 +1,166 @@
+package omnivoice
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// CaptionFormat is a subtitle file format written by CaptionWriter.
+type CaptionFormat string
+
+const (
+	// CaptionWebVTT writes WebVTT, as used by HTML5 video.
+	CaptionWebVTT CaptionFormat = "webvtt"
+
+	// CaptionSRT writes SubRip (SRT).
+	CaptionSRT CaptionFormat = "srt"
+)
+
+// CaptionWriter turns the events of a streaming transcription into
+// subtitle cues. Final transcripts are collected into a cue until the
+// utterance ends, signaled by an EventSpeechEnd event, and the cue is then
+// written with the timing of its first and last words. Interim transcripts
+// and transcripts without word timings are ignored.
+//
+// Close writes any cue still open. A CaptionWriter is not safe for
+// concurrent use.
+type CaptionWriter struct {
+	w      io.Writer
+	format CaptionFormat
+
+	// cues is the number of cues written so far
+	cues int
+
+	// header records whether the WebVTT header has been written
+	header bool
+
+	// text, start, and end describe the cue being collected
+	text       []string
+	start, end time.Duration
+
+	err error
+}
+
+// NewCaptionWriter returns a CaptionWriter writing cues in format to w. It
+// returns an error for an unknown format.
+func NewCaptionWriter(w io.Writer, format CaptionFormat) (*CaptionWriter, error) {
+	switch format {
+	case CaptionWebVTT, CaptionSRT:
+	default:
+		return nil, fmt.Errorf("unsupported caption format %q (supported: %s, %s)", format, CaptionWebVTT, CaptionSRT)
+	}
+	return &CaptionWriter{w: w, format: format}, nil
+}
+
+// WriteCaptions writes the events received from events to w as subtitle
+// cues in format until events is closed, then closes the CaptionWriter.
+// It returns the first write error, after which the remaining events are
+// drained.
+func WriteCaptions(w io.Writer, format CaptionFormat, events <-chan stt.StreamEvent) error {
+	cw, err := NewCaptionWriter(w, format)
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		_ = cw.WriteEvent(event)
+	}
+	return cw.Close()
+}
+
+// WriteEvent adds event to the captions, writing a cue when it ends an
+// utterance or the stream. Once a write has failed, WriteEvent returns
+// that error without doing anything.
+func (c *CaptionWriter) WriteEvent(event stt.StreamEvent) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	switch {
+	case event.Type == stt.EventTranscript:
+		if event.IsFinal && event.Segment != nil && len(event.Segment.Words) > 0 {
+			c.add(event.Segment)
+		}
+	case event.Type == stt.EventSpeechEnd || event.Type == EventClose:
+		c.flush()
+	}
+	return c.err
+}
+
+// Close writes the open cue, if any, and for WebVTT the header of a file
+// without cues. It does not close the underlying writer.
+func (c *CaptionWriter) Close() error {
+	if c.err != nil {
+		return c.err
+	}
+	c.flush()
+	c.writeHeader()
+	return c.err
+}
+
+// add appends the text and timing of a final segment to the open cue.
+func (c *CaptionWriter) add(segment *stt.Segment) {
+	text := strings.TrimSpace(segment.Text)
+	if text == "" {
+		return
+	}
+
+	words := segment.Words
+	if len(c.text) == 0 {
+		c.start = words[0].StartTime
+	}
+	c.end = words[len(words)-1].EndTime
+	c.text = append(c.text, text)
+}
+
+// flush writes the open cue, if any, and starts a new one.
+func (c *CaptionWriter) flush() {
+	if len(c.text) == 0 {
+		return
+	}
+	text := strings.Join(c.text, " ")
+	start, end := c.start, c.end
+	c.text = nil
+
+	// Players drop cues that end before they start
+	if end <= start {
+		end = start + time.Millisecond
+	}
+
+	c.writeHeader()
+	c.cues++
+	c.printf("%d\n%s --> %s\n%s\n\n", c.cues, c.timestamp(start), c.timestamp(end), text)
+}
+
+// writeHeader writes the WebVTT header once; SRT has none.
+func (c *CaptionWriter) writeHeader() {
+	if c.header || c.format != CaptionWebVTT {
+		return
+	}
+	c.header = true
+	c.printf("WEBVTT\n\n")
+}
+
+// timestamp formats d as a cue timestamp: HH:MM:SS.mmm for WebVTT and
+// HH:MM:SS,mmm for SRT.
+func (c *CaptionWriter) timestamp(d time.Duration) string {
+	sep := "."
+	if c.format == CaptionSRT {
+		sep = ","
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, sep, ms%1000)
+}
+
+// printf writes to the underlying writer, recording the first error.
+func (c *CaptionWriter) printf(format string, args ...any) {
+	if c.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(c.w, format, args...); err != nil {
+		c.err = fmt.Errorf("failed to write captions: %w", err)
+	}
+}
@@ -0,0 +1,132 @@
+package omnivoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// finalEvent returns a final transcript event for words spaced 500ms apart
+// starting at start.
+func finalEvent(start time.Duration, words ...string) stt.StreamEvent {
+	segment := &stt.Segment{Text: strings.Join(words, " ")}
+	for i, w := range words {
+		at := start + time.Duration(i)*500*time.Millisecond
+		segment.Words = append(segment.Words, stt.Word{Text: w, StartTime: at, EndTime: at + 400*time.Millisecond})
+	}
+	return stt.StreamEvent{Type: stt.EventTranscript, IsFinal: true, Transcript: segment.Text, Segment: segment}
+}
+
+// captionEvents is a stream of two utterances, the first finalized in two
+// parts, with interim results and a final without words mixed in.
+func captionEvents() []stt.StreamEvent {
+	interim := finalEvent(time.Second, "Hello")
+	interim.IsFinal = false
+	return []stt.StreamEvent{
+		{Type: stt.EventSpeechStart},
+		interim,
+		finalEvent(time.Second, "Hello", "there."),
+		finalEvent(2*time.Second, "How", "are", "you?"),
+		{Type: stt.EventSpeechEnd, SpeechEnded: true},
+		{Type: stt.EventTranscript, IsFinal: true},
+		finalEvent(time.Hour+65*time.Second+250*time.Millisecond, "Fine."),
+	}
+}
+
+func TestWriteCaptions(t *testing.T) {
+	tests := []struct {
+		format CaptionFormat
+		want   string
+	}{
+		{
+			format: CaptionWebVTT,
+			want: "WEBVTT\n\n" +
+				"1\n00:00:01.000 --> 00:00:03.400\nHello there. How are you?\n\n" +
+				"2\n01:01:05.250 --> 01:01:05.650\nFine.\n\n",
+		},
+		{
+			format: CaptionSRT,
+			want: "1\n00:00:01,000 --> 00:00:03,400\nHello there. How are you?\n\n" +
+				"2\n01:01:05,250 --> 01:01:05,650\nFine.\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			events := make(chan stt.StreamEvent, 10)
+			for _, event := range captionEvents() {
+				events <- event
+			}
+			close(events)
+
+			var out strings.Builder
+			if err := WriteCaptions(&out, tt.format, events); err != nil {
+				t.Fatalf("WriteCaptions() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteCaptions() wrote\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptionWriter_CloseEvent(t *testing.T) {
+	var out strings.Builder
+	cw, err := NewCaptionWriter(&out, CaptionSRT)
+	if err != nil {
+		t.Fatalf("NewCaptionWriter() error = %v", err)
+	}
+
+	_ = cw.WriteEvent(finalEvent(0, "Goodbye."))
+	if out.Len() != 0 {
+		t.Fatalf("cue written before the utterance ended: %q", out.String())
+	}
+	if err := cw.WriteEvent(stt.StreamEvent{Type: EventClose}); err != nil {
+		t.Fatalf("WriteEvent() error = %v", err)
+	}
+	if want := "1\n00:00:00,000 --> 00:00:00,400\nGoodbye.\n\n"; out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestCaptionWriter_Empty(t *testing.T) {
+	var out strings.Builder
+	cw, err := NewCaptionWriter(&out, CaptionWebVTT)
+	if err != nil {
+		t.Fatalf("NewCaptionWriter() error = %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if out.String() != "WEBVTT\n\n" {
+		t.Errorf("empty WebVTT = %q, want just the header", out.String())
+	}
+}
+
+func TestNewCaptionWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewCaptionWriter(&strings.Builder{}, "ass"); err == nil {
+		t.Error("NewCaptionWriter() with unknown format succeeded")
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestCaptionWriter_WriteError(t *testing.T) {
+	cw, err := NewCaptionWriter(failingWriter{}, CaptionSRT)
+	if err != nil {
+		t.Fatalf("NewCaptionWriter() error = %v", err)
+	}
+	_ = cw.WriteEvent(finalEvent(0, "Hi."))
+	if err := cw.WriteEvent(stt.StreamEvent{Type: stt.EventSpeechEnd}); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("WriteEvent() error = %v, want the write error", err)
+	}
+	if err := cw.Close(); err == nil {
+		t.Error("Close() after a write error = nil")
+	}
+}
@@ -0,0 +1,45 @@
+package omnivoice
+
+import (
+	"context"
+	"slices"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// correlationIDKey is the context key for the request correlation ID.
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying the given correlation ID.
+// The STT provider attaches the ID to Deepgram requests as a tag; Deepgram's
+// speech synthesis API has no tags. Both the STT and TTS providers include
+// it in the errors they return and deliver on streams, so requests can be
+// traced end to end.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, or ""
+// if none is set.
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// withParameters returns a copy of ctx that adds params to the query of
+// the SDK's requests, after any custom parameters already on ctx.
+func withParameters(ctx context.Context, params map[string][]string) context.Context {
+	merged := map[string][]string{}
+	if existing, ok := ctx.Value(interfaces.ParametersContext{}).(map[string][]string); ok {
+		for k, vs := range existing {
+			merged[k] = slices.Clone(vs)
+		}
+	}
+	for k, vs := range params {
+		merged[k] = append(merged[k], vs...)
+	}
+	return interfaces.WithCustomParameters(ctx, merged)
+}
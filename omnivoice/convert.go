@@ -1,6 +1,14 @@
 package omnivoice
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
@@ -8,6 +16,23 @@ import (
 	"github.com/plexusone/omnivoice-core/stt"
 )
 
+// DefaultSTTModel is the transcription model used when the config does not
+// specify one.
+const DefaultSTTModel = "nova-2"
+
+// ListenContext returns ctx carrying the query parameters for config that
+// the SDK's PreRecordedTranscriptionOptions and LiveTranscriptionOptions
+// have no field for, currently ExtDictation. The SDK adds them to the
+// request URL of batch and streaming transcription alike. Custom
+// parameters already in ctx are kept. ctx is returned unchanged when there
+// is nothing to add.
+func ListenContext(ctx context.Context, config stt.TranscriptionConfig) context.Context {
+	if !extensionBool(config, ExtDictation) {
+		return ctx
+	}
+	return withParameters(ctx, map[string][]string{"dictation": {"true"}})
+}
+
 // ConfigToLiveTranscriptionOptions converts OmniVoice TranscriptionConfig to Deepgram options.
 func ConfigToLiveTranscriptionOptions(config stt.TranscriptionConfig) *interfaces.LiveTranscriptionOptions {
 	opts := &interfaces.LiveTranscriptionOptions{
@@ -22,28 +47,35 @@ func ConfigToLiveTranscriptionOptions(config stt.TranscriptionConfig) *interface
 
 		// Features
 		Punctuate:   config.EnablePunctuation,
-		SmartFormat: true, // Enable smart formatting
+		SmartFormat: smartFormat(config),
 	}
 
-	// Set defaults for telephony if not specified
+	// Default the sample rate to suit the encoding if not specified
 	if opts.SampleRate == 0 {
-		opts.SampleRate = 8000
+		opts.SampleRate = liveSampleRate(opts.Encoding)
 	}
 	if opts.Channels == 0 {
 		opts.Channels = 1
 	}
 	if opts.Model == "" {
-		opts.Model = "nova-2" // Best general model
+		opts.Model = DefaultSTTModel
 	}
 	if opts.Language == "" {
 		opts.Language = "en-US"
 	}
 
 	// Enable interim results for streaming
-	opts.InterimResults = true
+	opts.InterimResults = interimResults(config)
 
-	// Enable utterance detection for natural turn-taking
-	opts.UtteranceEndMs = "1000" // 1 second silence = end of utterance
+	// Enable utterance detection for natural turn-taking, which Deepgram
+	// only supports alongside interim results
+	silence, ok := utteranceEnd(config)
+	if !ok {
+		silence = DefaultUtteranceEnd
+	}
+	if opts.InterimResults || ok {
+		opts.UtteranceEndMs = strconv.FormatInt(silence.Milliseconds(), 10)
+	}
 
 	// Enable diarization if requested
 	if config.EnableSpeakerDiarization {
@@ -53,47 +85,114 @@ func ConfigToLiveTranscriptionOptions(config stt.TranscriptionConfig) *interface
 		}
 	}
 
-	// Add keywords for boosting
-	if len(config.Keywords) > 0 {
-		opts.Keywords = config.Keywords
-	}
+	// Add keywords for boosting, within Deepgram's practical limits
+	opts.Keywords = prepareKeywords(config.Keywords)
+
+	// Measurements are pre-recorded only, but still imply numerals
+	opts.Numerals, _ = numberFormatting(config)
+
+	opts.ProfanityFilter = extensionBool(config, ExtProfanityFilter)
+	opts.Redact = Redaction(config)
+	opts.Alternatives = alternatives(config)
 
 	return opts
 }
 
+// liveSampleRate returns the default streaming sample rate for a Deepgram
+// encoding: 16 kHz for linear16 audio and 8 kHz telephony audio otherwise.
+func liveSampleRate(encoding string) int {
+	if encoding == "linear16" {
+		return 16000
+	}
+	return 8000
+}
+
+// encodingAliases maps OmniVoice encoding names to Deepgram encoding strings.
+var encodingAliases = map[string]string{
+	"mulaw":     "mulaw",
+	"ulaw":      "mulaw",
+	"g711u":     "mulaw",
+	"pcm_mulaw": "mulaw",
+	"alaw":      "alaw",
+	"g711a":     "alaw",
+	"pcm_alaw":  "alaw",
+	"linear16":  "linear16",
+	"pcm":       "linear16",
+	"pcm_s16le": "linear16",
+	"flac":      "flac",
+	"opus":      "opus",
+	"speex":     "speex",
+	"mp3":       "mp3",
+	"webm":      "webm",
+}
+
+// passthroughEncodings are encodings documented by Deepgram that have no
+// OmniVoice alias. They are forwarded to Deepgram unchanged.
+var passthroughEncodings = map[string]bool{
+	"linear32": true,
+	"amr-nb":   true,
+	"amr-wb":   true,
+	"ogg-opus": true,
+	"g729":     true,
+}
+
 // mapEncoding maps OmniVoice encoding names to Deepgram encoding strings.
+// Unknown names are forwarded unchanged; use ValidateEncoding to reject them.
 func mapEncoding(encoding string) string {
-	switch encoding {
-	case "mulaw", "ulaw", "g711u", "pcm_mulaw":
-		return "mulaw"
-	case "alaw", "g711a", "pcm_alaw":
-		return "alaw"
-	case "linear16", "pcm", "pcm_s16le":
-		return "linear16"
-	case "flac":
-		return "flac"
-	case "opus":
-		return "opus"
-	case "speex":
-		return "speex"
-	case "mp3":
-		return "mp3"
-	case "webm":
-		return "webm"
-	default:
+	if encoding == "" {
 		// Default to linear16 for PCM
-		if encoding == "" {
-			return "linear16"
-		}
-		return encoding
+		return "linear16"
+	}
+	if dg, ok := encodingAliases[encoding]; ok {
+		return dg
+	}
+	return encoding
+}
+
+// SupportedEncodings returns the encoding names accepted by ValidateEncoding,
+// in sorted order.
+func SupportedEncodings() []string {
+	names := make([]string, 0, len(encodingAliases)+len(passthroughEncodings))
+	for name := range encodingAliases {
+		names = append(names, name)
+	}
+	for name := range passthroughEncodings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateEncoding returns an error wrapping stt.ErrInvalidConfig if
+// encoding is neither an OmniVoice encoding name nor a Deepgram encoding
+// that is passed through unchanged. An empty encoding is valid and selects
+// linear16.
+func ValidateEncoding(encoding string) error {
+	if encoding == "" || passthroughEncodings[encoding] {
+		return nil
+	}
+	if _, ok := encodingAliases[encoding]; ok {
+		return nil
 	}
+	return fmt.Errorf("%w: unsupported encoding %q (supported: %s)",
+		stt.ErrInvalidConfig, encoding, strings.Join(SupportedEncodings(), ", "))
 }
 
 // MessageResponseToStreamEvent converts a Deepgram MessageResponse to an OmniVoice stream event.
+// A message without alternatives, as Deepgram sends for silence, yields an
+// EventTranscript with an empty Transcript and nil Segment that keeps the
+// message's IsFinal flag.
+//
+// Word and segment times are relative to the start of the stream, so they
+// increase monotonically across the messages of a session even when the
+// word timings received are relative to each message.
 func MessageResponseToStreamEvent(result *MessageResponse) stt.StreamEvent {
-	if result == nil || len(result.Channel.Alternatives) == 0 {
+	if result == nil {
 		return stt.StreamEvent{Type: stt.EventTranscript}
 	}
+	if len(result.Channel.Alternatives) == 0 {
+		return stt.StreamEvent{Type: stt.EventTranscript, IsFinal: result.IsFinal}
+	}
 
 	alt := result.Channel.Alternatives[0]
 
@@ -110,20 +209,9 @@ func MessageResponseToStreamEvent(result *MessageResponse) stt.StreamEvent {
 			Confidence: float64(alt.Confidence),
 		}
 
+		offset := wordOffset(result)
 		for _, w := range alt.Words {
-			word := stt.Word{
-				Text:       w.Word,
-				Confidence: float64(w.Confidence),
-				StartTime:  time.Duration(w.Start * float64(time.Second)),
-				EndTime:    time.Duration(w.End * float64(time.Second)),
-			}
-
-			// Include speaker if diarization is enabled
-			if w.Speaker != nil {
-				word.Speaker = formatSpeaker(*w.Speaker)
-			}
-
-			segment.Words = append(segment.Words, word)
+			segment.Words = append(segment.Words, liveWord(w, offset))
 		}
 
 		// Set segment timing from first and last word
@@ -138,13 +226,182 @@ func MessageResponseToStreamEvent(result *MessageResponse) stt.StreamEvent {
 	return event
 }
 
+// liveWord converts a stream word, shifting its timings by offset seconds.
+func liveWord(w Word, offset float64) stt.Word {
+	word := stt.Word{
+		Text:       w.Word,
+		Confidence: w.Confidence,
+		StartTime:  time.Duration((offset + w.Start) * float64(time.Second)),
+		EndTime:    time.Duration((offset + w.End) * float64(time.Second)),
+	}
+
+	// Include speaker if diarization is enabled
+	if w.Speaker != nil {
+		word.Speaker = formatSpeaker(*w.Speaker)
+	}
+	return word
+}
+
+// liveAlternatives returns the alternatives of a stream message with more
+// than one, or nil.
+func liveAlternatives(result *MessageResponse) []TranscriptAlternative {
+	alts := result.Channel.Alternatives
+	if len(alts) < 2 {
+		return nil
+	}
+
+	offset := wordOffset(result)
+	out := make([]TranscriptAlternative, len(alts))
+	for i, alt := range alts {
+		out[i] = TranscriptAlternative{Transcript: alt.Transcript, Confidence: alt.Confidence}
+		if len(alt.Words) > 0 {
+			out[i].Words = make([]WordInfo, len(alt.Words))
+		}
+		for j, w := range alt.Words {
+			word := liveWord(w, offset)
+			out[i].Words[j] = WordInfo{
+				Word:           word,
+				ID:             WordID(word.StartTime),
+				Index:          j,
+				PunctuatedWord: w.PunctuatedWord,
+				Language:       w.Language,
+			}
+		}
+	}
+	return out
+}
+
+// wordOffset returns the offset in seconds to add to the word timings of
+// result so that they are relative to the start of the stream.
+//
+// Deepgram reports word timings relative to the stream, but some SDK
+// versions and proxies rebase them onto the message, so that timings reset
+// with every message. Timings that begin before the message itself are
+// taken to be message-relative and shifted by the message Start.
+func wordOffset(result *MessageResponse) float64 {
+	// Tolerance for rounding in Deepgram's word and message timings
+	const tolerance = 0.01
+
+	words := result.Channel.Alternatives[0].Words
+	if result.Start <= 0 || len(words) == 0 || words[0].Start >= result.Start-tolerance {
+		return 0
+	}
+	return result.Start
+}
+
+// MessageResponseToEvent converts a Deepgram MessageResponse to a Deepgram
+// stream event, adding stable word IDs to the core OmniVoice event.
+func MessageResponseToEvent(result *MessageResponse, opts ConvertOptions) StreamEvent {
+	event := StreamEvent{StreamEvent: MessageResponseToStreamEvent(result)}
+	if result != nil {
+		event.FromFinalize = result.FromFinalize
+		event.Alternatives = liveAlternatives(result)
+	}
+
+	if opts.FormatLocale != "" {
+		event.Transcript = FormatDates(event.Transcript, opts.FormatLocale)
+	}
+
+	if event.Segment == nil {
+		return event
+	}
+
+	if opts.FormatLocale != "" {
+		event.Segment.Text = FormatDates(event.Segment.Text, opts.FormatLocale)
+	}
+
+	if opts.DebugWords {
+		event.DebugWords = result.Channel.Alternatives[0].Words
+	}
+
+	words := result.Channel.Alternatives[0].Words
+
+	if opts.DiarizationGrouping == DiarizationPerTurn {
+		event.Turns = speakerTurns(event.Segment.Words, func(i int) string {
+			return displayWord(words[i].PunctuatedWord, words[i].Word)
+		})
+		if len(event.Turns) == 1 {
+			event.Segment.Speaker = event.Turns[0].Speaker
+		}
+		if opts.FormatLocale != "" {
+			for i := range event.Turns {
+				event.Turns[i].Text = FormatDates(event.Turns[i].Text, opts.FormatLocale)
+			}
+		}
+	}
+
+	event.Words = make([]WordInfo, len(event.Segment.Words))
+	for i, w := range event.Segment.Words {
+		event.Words[i] = WordInfo{
+			Word:           w,
+			ID:             WordID(w.StartTime),
+			Index:          i,
+			PunctuatedWord: words[i].PunctuatedWord,
+			Language:       words[i].Language,
+		}
+	}
+
+	return event
+}
+
+// warningMessage is the JSON form of a Deepgram stream warning.
+type warningMessage struct {
+	Type        string `json:"type"`
+	WarnCode    string `json:"warn_code"`
+	WarnMsg     string `json:"warn_msg"`
+	Variant     string `json:"variant"`
+	Description string `json:"description"`
+}
+
+// ParseWarning parses raw as a Deepgram stream warning message, reporting
+// false if it is not one.
+func ParseWarning(raw []byte) (*Warning, bool) {
+	var msg warningMessage
+	if err := json.Unmarshal(raw, &msg); err != nil || !strings.EqualFold(msg.Type, "warning") {
+		return nil, false
+	}
+
+	w := &Warning{Code: msg.WarnCode, Message: msg.Description}
+	if w.Code == "" {
+		w.Code = msg.Variant
+	}
+	if w.Message == "" {
+		w.Message = msg.WarnMsg
+	}
+	return w, true
+}
+
 // MessageResponse mirrors the Deepgram MessageResponse structure.
 // This allows us to decouple from Deepgram's internal types.
 type MessageResponse struct {
-	Channel  Channel `json:"channel,omitempty"`
-	IsFinal  bool    `json:"is_final,omitempty"`
-	Duration float64 `json:"duration,omitempty"`
-	Start    float64 `json:"start,omitempty"`
+	Channel      Channel `json:"channel,omitempty"`
+	IsFinal      bool    `json:"is_final,omitempty"`
+	FromFinalize bool    `json:"from_finalize,omitempty"`
+	Duration     float64 `json:"duration,omitempty"`
+	Start        float64 `json:"start,omitempty"`
+}
+
+// MetadataResponse mirrors the Deepgram stream MetadataResponse structure.
+type MetadataResponse struct {
+	RequestID string               `json:"request_id,omitempty"`
+	Duration  float64              `json:"duration,omitempty"`
+	Channels  int                  `json:"channels,omitempty"`
+	Models    []string             `json:"models,omitempty"`
+	ModelInfo map[string]ModelInfo `json:"model_info,omitempty"`
+}
+
+// MetadataResponseToEvent converts Deepgram stream metadata to an
+// EventMetadata event.
+func MetadataResponseToEvent(md *MetadataResponse) StreamEvent {
+	return StreamEvent{
+		StreamEvent: stt.StreamEvent{Type: EventMetadata},
+		Metadata: &Metadata{
+			RequestID: md.RequestID,
+			Duration:  time.Duration(md.Duration * float64(time.Second)),
+			Channels:  md.Channels,
+			Models:    modelInfos(md.Models, md.ModelInfo),
+		},
+	}
 }
 
 // Channel represents a transcription channel.
@@ -161,34 +418,47 @@ type Alternative struct {
 
 // Word represents a transcribed word with timing.
 type Word struct {
-	Word       string  `json:"word,omitempty"`
-	Start      float64 `json:"start,omitempty"`
-	End        float64 `json:"end,omitempty"`
-	Confidence float64 `json:"confidence,omitempty"`
-	Speaker    *int    `json:"speaker,omitempty"`
+	Word              string   `json:"word,omitempty"`
+	PunctuatedWord    string   `json:"punctuated_word,omitempty"`
+	Start             float64  `json:"start,omitempty"`
+	End               float64  `json:"end,omitempty"`
+	Confidence        float64  `json:"confidence,omitempty"`
+	Speaker           *int     `json:"speaker,omitempty"`
+	SpeakerConfidence *float64 `json:"speaker_confidence,omitempty"`
+	Language          string   `json:"language,omitempty"`
 }
 
-// formatSpeaker formats a speaker ID for OmniVoice.
-func formatSpeaker(speaker int) string {
-	return "speaker_" + itoa(speaker)
+// ConvertOptions controls optional detail added when converting Deepgram
+// responses.
+type ConvertOptions struct {
+	// DebugWords attaches Deepgram's unmodified word objects to results and
+	// events.
+	DebugWords bool
+
+	// FormatLocale is a BCP-47 locale whose date order is applied to
+	// transcript text with FormatDates. Empty leaves dates as Deepgram
+	// formats them.
+	FormatLocale string
+
+	// DiarizationGrouping selects how diarized words are grouped. With
+	// DiarizationPerTurn, batch results have one segment per speaker turn
+	// and stream events list their turns in Turns. Empty is
+	// DiarizationPerWord.
+	DiarizationGrouping DiarizationGrouping
 }
 
-// itoa converts an int to string without importing strconv.
-func itoa(i int) string {
-	if i == 0 {
-		return "0"
-	}
-	if i < 0 {
-		return "-" + itoa(-i)
-	}
-	var b [20]byte
-	n := len(b)
-	for i > 0 {
-		n--
-		b[n] = byte('0' + i%10)
-		i /= 10
+// displayWord returns the punctuated form of a word if Deepgram provided
+// one, and the bare word otherwise.
+func displayWord(punctuated, word string) string {
+	if punctuated != "" {
+		return punctuated
 	}
-	return string(b[n:])
+	return word
+}
+
+// formatSpeaker formats a speaker ID for OmniVoice.
+func formatSpeaker(speaker int) string {
+	return "speaker_" + strconv.Itoa(speaker)
 }
 
 // ConfigToPreRecordedOptions converts OmniVoice TranscriptionConfig to Deepgram pre-recorded options.
@@ -200,15 +470,17 @@ func ConfigToPreRecordedOptions(config stt.TranscriptionConfig) *interfaces.PreR
 
 		// Features
 		Punctuate:   config.EnablePunctuation,
-		SmartFormat: true,
+		SmartFormat: smartFormat(config),
 		Utterances:  true, // Always enable for segment boundaries
 	}
 
 	// Set defaults
 	if opts.Model == "" {
-		opts.Model = "nova-2"
+		opts.Model = DefaultSTTModel
 	}
-	if opts.Language == "" {
+	if detectLanguage(config) {
+		opts.DetectLanguage = true
+	} else if opts.Language == "" {
 		opts.Language = "en-US"
 	}
 
@@ -217,25 +489,112 @@ func ConfigToPreRecordedOptions(config stt.TranscriptionConfig) *interfaces.PreR
 		opts.Diarize = true
 	}
 
-	// Add keywords for boosting
-	if len(config.Keywords) > 0 {
-		opts.Keywords = config.Keywords
-	}
+	// Add keywords for boosting, within Deepgram's practical limits
+	opts.Keywords = prepareKeywords(config.Keywords)
+
+	opts.Numerals, opts.Measurements = numberFormatting(config)
+
+	opts.ProfanityFilter = extensionBool(config, ExtProfanityFilter)
+	opts.Redact = Redaction(config)
+	opts.Search = extensionStrings(config, ExtSearch)
+	opts.Replace = extensionStrings(config, ExtReplace)
+	opts.Summarize = summarizeVersion(config)
+	opts.Topics = extensionBool(config, ExtTopics)
+	opts.Intents = extensionBool(config, ExtIntents)
+	opts.Sentiment = extensionBool(config, ExtSentiment)
+	opts.DetectEntities = extensionBool(config, ExtEntities)
+	opts.Alternatives = alternatives(config)
+	opts.Paragraphs = extensionBool(config, ExtParagraphs)
 
 	return opts
 }
 
+// ApplyAudioFormat sets the encoding fields of opts for audio beginning with
+// header. Containerized audio is described by its Content-Type, which is
+// returned, and any explicit encoding is cleared so Deepgram reads the
+// container header. For headerless audio the encoding, sample rate, and
+// channels are taken from config and an empty Content-Type is returned.
+func ApplyAudioFormat(opts *interfaces.PreRecordedTranscriptionOptions, header []byte, config stt.TranscriptionConfig) string {
+	if format, ok := DetectAudioFormat(header); ok {
+		opts.Encoding = ""
+		opts.SampleRate = 0
+		opts.Channels = 0
+		return format.MimeType
+	}
+
+	if config.Encoding != "" {
+		opts.Encoding = mapEncoding(config.Encoding)
+		opts.SampleRate = config.SampleRate
+		opts.Channels = config.Channels
+	}
+	return ""
+}
+
 // PreRecordedResponseToResult converts a Deepgram PreRecordedResponse to OmniVoice TranscriptionResult.
+// Segments are in chronological order; see
+// PreRecordedResponseToTranscriptionResult.
 func PreRecordedResponseToResult(resp *restinterfaces.PreRecordedResponse) *stt.TranscriptionResult {
+	return &PreRecordedResponseToTranscriptionResult(resp, ConvertOptions{}).TranscriptionResult
+}
+
+// PreRecordedResponseToTranscriptionResult converts a Deepgram
+// PreRecordedResponse to a Deepgram TranscriptionResult, adding the detail
+// selected by opts to the core OmniVoice result.
+//
+// The result is never nil. A response without speech, or a nil response,
+// yields a result with empty Text and nil Segments.
+//
+// Segments are sorted by start time, whichever part of the response they
+// were built from, so they can be rendered in order. Segments starting at
+// the same time keep Deepgram's order.
+func PreRecordedResponseToTranscriptionResult(resp *restinterfaces.PreRecordedResponse, opts ConvertOptions) *TranscriptionResult {
+	out := &TranscriptionResult{}
 	if resp == nil || resp.Results == nil {
-		return &stt.TranscriptionResult{}
+		return out
 	}
 
-	result := &stt.TranscriptionResult{}
+	result := &out.TranscriptionResult
+
+	if len(resp.Results.Channels) > 0 && len(resp.Results.Channels[0].Alternatives) > 0 {
+		words := resp.Results.Channels[0].Alternatives[0].Words
+		out.Words = restWordInfos(words)
 
-	// Get duration from metadata
+		// Attach the unmodified words of the first alternative
+		if opts.DebugWords {
+			out.DebugWords = restWords(words)
+		}
+
+		if entities := resp.Results.Channels[0].Alternatives[0].Entities; entities != nil {
+			out.Entities = restEntities(*entities, out.Words)
+		}
+
+		out.Alternatives = restAlternatives(resp.Results.Channels[0].Alternatives)
+
+		if paragraphs := resp.Results.Channels[0].Alternatives[0].Paragraphs; paragraphs != nil {
+			out.Paragraphs = restParagraphs(paragraphs.Paragraphs)
+		}
+	}
+
+	// Get duration and model details from metadata
 	if resp.Metadata != nil {
 		result.Duration = time.Duration(resp.Metadata.Duration * float64(time.Second))
+		out.RequestID = resp.Metadata.RequestID
+		out.Models = restModelInfos(resp.Metadata)
+		if len(out.Models) > 0 {
+			out.ModelName = out.Models[0].Name
+			out.ModelVersion = out.Models[0].Version
+		}
+	}
+
+	out.Summary = summary(resp.Results)
+	if resp.Results.Topics != nil {
+		out.Topics = topics(resp.Results.Topics.Segments, out.Words)
+	}
+	if resp.Results.Intents != nil {
+		out.Intents = intents(resp.Results.Intents.Segments, out.Words)
+	}
+	if resp.Results.Sentiments != nil {
+		out.Sentiments, out.OverallSentiment = sentiments(resp.Results.Sentiments, out.Words)
 	}
 
 	// Process channels - typically use first channel
@@ -248,6 +607,10 @@ func PreRecordedResponseToResult(resp *restinterfaces.PreRecordedResponse) *stt.
 			result.LanguageConfidence = channel.LanguageConfidence
 		}
 
+		if channel.Search != nil {
+			out.Searches = searchResults(*channel.Search)
+		}
+
 		// Get transcript from first alternative
 		if len(channel.Alternatives) > 0 {
 			alt := channel.Alternatives[0]
@@ -280,6 +643,16 @@ func PreRecordedResponseToResult(resp *restinterfaces.PreRecordedResponse) *stt.
 				}
 
 				result.Segments = append(result.Segments, segment)
+			} else if alt.Paragraphs != nil && len(alt.Paragraphs.Paragraphs) > 0 {
+				// Without words, paragraphs still carry segment timing
+				result.Segments = paragraphSegments(alt.Paragraphs.Paragraphs)
+			} else if alt.Transcript != "" {
+				// Without any timing data the transcript spans the whole audio
+				result.Segments = append(result.Segments, stt.Segment{
+					Text:       alt.Transcript,
+					EndTime:    result.Duration,
+					Confidence: alt.Confidence,
+				})
 			}
 		}
 	}
@@ -319,5 +692,312 @@ func PreRecordedResponseToResult(resp *restinterfaces.PreRecordedResponse) *stt.
 		}
 	}
 
-	return result
+	// Regroup diarized words into speaker turns if requested
+	if opts.DiarizationGrouping == DiarizationPerTurn && len(resp.Results.Channels) > 0 && len(resp.Results.Channels[0].Alternatives) > 0 {
+		words := resp.Results.Channels[0].Alternatives[0].Words
+		if len(words) > 0 && words[0].Speaker != nil {
+			converted := make([]stt.Word, len(out.Words))
+			for i, w := range out.Words {
+				converted[i] = w.Word
+			}
+			result.Segments = speakerTurns(converted, func(i int) string {
+				return displayWord(words[i].PunctuatedWord, words[i].Word)
+			})
+		}
+	}
+
+	// Deepgram does not promise ordered utterances or paragraphs
+	slices.SortStableFunc(result.Segments, func(a, b stt.Segment) int {
+		return cmp.Compare(a.StartTime, b.StartTime)
+	})
+
+	// Reorder dates in the transcript text for the requested locale
+	if opts.FormatLocale != "" {
+		result.Text = FormatDates(result.Text, opts.FormatLocale)
+		for i := range result.Segments {
+			result.Segments[i].Text = FormatDates(result.Segments[i].Text, opts.FormatLocale)
+		}
+	}
+
+	return out
+}
+
+// restModelInfos returns the models in the metadata of a batch response.
+func restModelInfos(md *restinterfaces.Metadata) []ModelInfo {
+	details := make(map[string]ModelInfo, len(md.ModelInfo))
+	for uuid, info := range md.ModelInfo {
+		details[uuid] = ModelInfo{Name: info.Name, Version: info.Version, Arch: info.Arch}
+	}
+	return modelInfos(md.Models, details)
+}
+
+// modelInfos returns the models in uuids, followed by any only present in
+// details, sorted by UUID. A model listed without details has only its
+// UUID set.
+func modelInfos(uuids []string, details map[string]ModelInfo) []ModelInfo {
+	var models []ModelInfo
+	seen := make(map[string]bool, len(uuids))
+	add := func(uuid string) {
+		if seen[uuid] {
+			return
+		}
+		seen[uuid] = true
+		info := details[uuid]
+		info.UUID = uuid
+		models = append(models, info)
+	}
+
+	for _, uuid := range uuids {
+		add(uuid)
+	}
+	for _, uuid := range slices.Sorted(maps.Keys(details)) {
+		add(uuid)
+	}
+	return models
+}
+
+// summary returns the transcript summary in results: the v2 summary, or
+// the v1 summaries of the first channel's top alternative joined with
+// spaces. It returns "" if there is none.
+func summary(results *restinterfaces.Result) string {
+	if results.Summary != nil {
+		return results.Summary.Short
+	}
+	if len(results.Channels) == 0 || len(results.Channels[0].Alternatives) == 0 {
+		return ""
+	}
+	summaries := results.Channels[0].Alternatives[0].Summaries
+	if summaries == nil {
+		return ""
+	}
+	texts := make([]string, 0, len(*summaries))
+	for _, s := range *summaries {
+		texts = append(texts, s.Summary)
+	}
+	return strings.Join(texts, " ")
+}
+
+// topics converts the topics of Deepgram's transcript segments.
+func topics(segments []restinterfaces.Segment, words []WordInfo) []Topic {
+	var out []Topic
+	for _, seg := range segments {
+		if seg.Topics == nil {
+			continue
+		}
+		for _, t := range *seg.Topics {
+			out = append(out, Topic{Label: t.Topic, Confidence: t.ConfidenceScore, Span: textSpan(seg, words)})
+		}
+	}
+	return out
+}
+
+// intents converts the intents of Deepgram's transcript segments.
+func intents(segments []restinterfaces.Segment, words []WordInfo) []Intent {
+	var out []Intent
+	for _, seg := range segments {
+		if seg.Intents == nil {
+			continue
+		}
+		for _, i := range *seg.Intents {
+			out = append(out, Intent{Label: i.Intent, Confidence: i.ConfidenceScore, Span: textSpan(seg, words)})
+		}
+	}
+	return out
+}
+
+// sentiments converts Deepgram's sentiment analysis to per-span
+// sentiments and the overall sentiment, which is nil if absent.
+func sentiments(s *restinterfaces.Sentiments, words []WordInfo) ([]Sentiment, *Sentiment) {
+	var out []Sentiment
+	for _, seg := range s.Segments {
+		if seg.Sentiment == nil {
+			continue
+		}
+		sentiment := Sentiment{Label: *seg.Sentiment, Span: textSpan(seg, words)}
+		if seg.SentimentScore != nil {
+			sentiment.Score = *seg.SentimentScore
+		}
+		out = append(out, sentiment)
+	}
+
+	var overall *Sentiment
+	if s.Average.Sentiment != "" {
+		overall = &Sentiment{Label: s.Average.Sentiment, Score: s.Average.SentimentScore}
+	}
+	return out, overall
+}
+
+// restAlternatives returns the alternatives of a batch channel with more
+// than one, or nil.
+func restAlternatives(alts []restinterfaces.Alternative) []TranscriptAlternative {
+	if len(alts) < 2 {
+		return nil
+	}
+
+	out := make([]TranscriptAlternative, len(alts))
+	for i, alt := range alts {
+		out[i] = TranscriptAlternative{
+			Transcript: alt.Transcript,
+			Confidence: alt.Confidence,
+			Words:      restWordInfos(alt.Words),
+		}
+	}
+	return out
+}
+
+// restEntities converts Deepgram entities. Deepgram's end_word is one past
+// the entity's last word, unlike the inclusive EndWord of TextSpan.
+func restEntities(entities []restinterfaces.Entity, words []WordInfo) []Entity {
+	out := make([]Entity, 0, len(entities))
+	for _, e := range entities {
+		out = append(out, Entity{
+			Label:      e.Label,
+			Value:      e.Value,
+			Confidence: e.Confidence,
+			Span:       wordSpan(e.Value, int(e.StartWord), int(e.EndWord)-1, words),
+		})
+	}
+	return out
+}
+
+// textSpan returns the transcript span of a Deepgram segment.
+func textSpan(seg restinterfaces.Segment, words []WordInfo) TextSpan {
+	return wordSpan(seg.Text, seg.StartWord, seg.EndWord, words)
+}
+
+// wordSpan returns the span of text from word start to word end
+// inclusive, timed by words when they cover it.
+func wordSpan(text string, start, end int, words []WordInfo) TextSpan {
+	span := TextSpan{Text: text, StartWord: start, EndWord: end}
+	if start >= 0 && start <= end && end < len(words) {
+		span.Start = words[start].StartTime
+		span.End = words[end].EndTime
+	}
+	return span
+}
+
+// searchResults converts Deepgram search results.
+func searchResults(searches []restinterfaces.Search) []SearchResult {
+	results := make([]SearchResult, 0, len(searches))
+	for _, search := range searches {
+		hits := make([]SearchHit, 0, len(search.Hits))
+		for _, hit := range search.Hits {
+			hits = append(hits, SearchHit{
+				Confidence: hit.Confidence,
+				Start:      time.Duration(hit.Start * float64(time.Second)),
+				End:        time.Duration(hit.End * float64(time.Second)),
+				Snippet:    hit.Snippet,
+			})
+		}
+		results = append(results, SearchResult{Query: search.Query, Hits: hits})
+	}
+	return results
+}
+
+// restParagraphs converts Deepgram paragraphs, or returns nil if there
+// are none.
+func restParagraphs(paragraphs []restinterfaces.Paragraph) []Paragraph {
+	if len(paragraphs) == 0 {
+		return nil
+	}
+
+	out := make([]Paragraph, len(paragraphs))
+	for i, para := range paragraphs {
+		texts := make([]string, len(para.Sentences))
+		sentences := make([]Sentence, len(para.Sentences))
+		for j, s := range para.Sentences {
+			texts[j] = s.Text
+			sentences[j] = Sentence{
+				Text:  s.Text,
+				Start: time.Duration(s.Start * float64(time.Second)),
+				End:   time.Duration(s.End * float64(time.Second)),
+			}
+		}
+
+		out[i] = Paragraph{
+			Text:      strings.Join(texts, " "),
+			Start:     time.Duration(para.Start * float64(time.Second)),
+			End:       time.Duration(para.End * float64(time.Second)),
+			Sentences: sentences,
+		}
+		if para.Speaker != nil {
+			out[i].Speaker = formatSpeaker(*para.Speaker)
+		}
+	}
+	return out
+}
+
+// paragraphSegments converts Deepgram paragraphs to segments, one per
+// paragraph, using the paragraph timing and the text of its sentences.
+func paragraphSegments(paragraphs []restinterfaces.Paragraph) []stt.Segment {
+	segments := make([]stt.Segment, 0, len(paragraphs))
+	for _, para := range paragraphs {
+		texts := make([]string, 0, len(para.Sentences))
+		for _, sentence := range para.Sentences {
+			texts = append(texts, sentence.Text)
+		}
+
+		segment := stt.Segment{
+			Text:      strings.Join(texts, " "),
+			StartTime: time.Duration(para.Start * float64(time.Second)),
+			EndTime:   time.Duration(para.End * float64(time.Second)),
+		}
+		if para.Speaker != nil {
+			segment.Speaker = formatSpeaker(*para.Speaker)
+		}
+
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// restWords converts Deepgram pre-recorded words to the mirrored Word type.
+func restWords(words []restinterfaces.Word) []Word {
+	if len(words) == 0 {
+		return nil
+	}
+
+	out := make([]Word, len(words))
+	for i, w := range words {
+		out[i] = Word{
+			Word:              w.Word,
+			PunctuatedWord:    w.PunctuatedWord,
+			Start:             w.Start,
+			End:               w.End,
+			Confidence:        w.Confidence,
+			Speaker:           w.Speaker,
+			SpeakerConfidence: w.SpeakerConfidence,
+			Language:          w.Language,
+		}
+	}
+	return out
+}
+
+// restWordInfos converts Deepgram pre-recorded words to WordInfo.
+func restWordInfos(words []restinterfaces.Word) []WordInfo {
+	if len(words) == 0 {
+		return nil
+	}
+
+	out := make([]WordInfo, len(words))
+	for i, w := range words {
+		word := stt.Word{
+			Text:       w.Word,
+			StartTime:  time.Duration(w.Start * float64(time.Second)),
+			EndTime:    time.Duration(w.End * float64(time.Second)),
+			Confidence: w.Confidence,
+		}
+		if w.Speaker != nil {
+			word.Speaker = formatSpeaker(*w.Speaker)
+		}
+
+		out[i] = WordInfo{
+			Word:           word,
+			ID:             WordID(word.StartTime),
+			Index:          i,
+			PunctuatedWord: w.PunctuatedWord,
+			Language:       w.Language,
+		}
+	}
+	return out
 }
@@ -0,0 +1,1012 @@
+package omnivoice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestMessageResponseToEvent_StableWordIDs(t *testing.T) {
+	// Successive interim results for the same audio: Deepgram revises
+	// "hello word" to "hello world" and then appends a word.
+	messages := []*MessageResponse{
+		{Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "hello word",
+			Words: []Word{
+				{Word: "hello", Start: 0.24, End: 0.56, Confidence: 0.8},
+				{Word: "word", Start: 0.64, End: 0.96, Confidence: 0.4},
+			},
+		}}}},
+		{Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "hello world",
+			Words: []Word{
+				{Word: "hello", Start: 0.24, End: 0.56, Confidence: 0.9},
+				{Word: "world", Start: 0.64, End: 1.02, Confidence: 0.9},
+			},
+		}}}},
+		{IsFinal: true, Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "hello world again",
+			Words: []Word{
+				{Word: "hello", Start: 0.24, End: 0.56, Confidence: 0.95},
+				{Word: "world", Start: 0.64, End: 1.02, Confidence: 0.95},
+				{Word: "again", Start: 1.1, End: 1.5, Confidence: 0.95},
+			},
+		}}}},
+	}
+
+	var events []StreamEvent
+	for _, m := range messages {
+		events = append(events, MessageResponseToEvent(m, ConvertOptions{}))
+	}
+
+	first, second, final := events[0].Words, events[1].Words, events[2].Words
+	if len(first) != 2 || len(second) != 2 || len(final) != 3 {
+		t.Fatalf("word counts = %d, %d, %d, want 2, 2, 3", len(first), len(second), len(final))
+	}
+
+	for i := range first {
+		if first[i].ID != second[i].ID || second[i].ID != final[i].ID {
+			t.Errorf("word %d IDs = %q, %q, %q, want stable", i, first[i].ID, second[i].ID, final[i].ID)
+		}
+		if final[i].Index != i {
+			t.Errorf("word %d Index = %d", i, final[i].Index)
+		}
+	}
+	if second[1].Text != "world" {
+		t.Errorf("revised word = %q, want %q", second[1].Text, "world")
+	}
+	if final[2].ID == final[1].ID {
+		t.Errorf("new word reused ID %q", final[2].ID)
+	}
+	if got, want := final[0].ID, "w240"; got != want {
+		t.Errorf("ID = %q, want %q", got, want)
+	}
+}
+
+func TestMessageResponseToEvent_NoAlternatives(t *testing.T) {
+	event := MessageResponseToEvent(&MessageResponse{}, ConvertOptions{})
+	if event.Words != nil {
+		t.Errorf("Words = %v, want nil", event.Words)
+	}
+}
+
+func TestPreRecordedResponseToResult_ParagraphTimingWithoutWords(t *testing.T) {
+	fixture := `{
+		"metadata": {"request_id": "abc", "duration": 9.5},
+		"results": {"channels": [{"alternatives": [{
+			"transcript": "Hello there. How are you? Fine thanks.",
+			"confidence": 0.97,
+			"paragraphs": {"paragraphs": [
+				{"start": 0.5, "end": 4.25, "speaker": 0, "sentences": [
+					{"text": "Hello there.", "start": 0.5, "end": 1.5},
+					{"text": "How are you?", "start": 2.0, "end": 4.25}
+				]},
+				{"start": 5.0, "end": 6.75, "speaker": 1, "sentences": [
+					{"text": "Fine thanks.", "start": 5.0, "end": 6.75}
+				]}
+			]}
+		}]}]}
+	}`
+
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToResult(&resp)
+	if len(result.Segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(result.Segments))
+	}
+
+	first, second := result.Segments[0], result.Segments[1]
+	if first.Text != "Hello there. How are you?" {
+		t.Errorf("first segment text = %q", first.Text)
+	}
+	if first.StartTime != 500*time.Millisecond || first.EndTime != 4250*time.Millisecond {
+		t.Errorf("first segment timing = %v-%v, want 500ms-4.25s", first.StartTime, first.EndTime)
+	}
+	if second.StartTime != 5*time.Second || second.EndTime != 6750*time.Millisecond {
+		t.Errorf("second segment timing = %v-%v, want 5s-6.75s", second.StartTime, second.EndTime)
+	}
+	if first.Speaker != "speaker_0" || second.Speaker != "speaker_1" {
+		t.Errorf("speakers = %q, %q", first.Speaker, second.Speaker)
+	}
+	if first.Words != nil {
+		t.Errorf("words = %v, want nil", first.Words)
+	}
+}
+
+func TestPreRecordedResponseToResult_TranscriptOnly(t *testing.T) {
+	fixture := `{
+		"metadata": {"duration": 3.0},
+		"results": {"channels": [{"alternatives": [{"transcript": "just text", "confidence": 0.9}]}]}
+	}`
+
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToResult(&resp)
+	if len(result.Segments) != 1 {
+		t.Fatalf("got %d segments, want 1", len(result.Segments))
+	}
+	if seg := result.Segments[0]; seg.StartTime != 0 || seg.EndTime != 3*time.Second {
+		t.Errorf("segment timing = %v-%v, want 0s-3s", seg.StartTime, seg.EndTime)
+	}
+}
+
+func TestMessageResponseToEvent_DebugWords(t *testing.T) {
+	msg := &MessageResponse{Channel: Channel{Alternatives: []Alternative{{
+		Transcript: "hola",
+		Words: []Word{
+			{Word: "hola", PunctuatedWord: "Hola.", Start: 0.1, End: 0.4, Confidence: 0.8, Language: "es"},
+		},
+	}}}}
+
+	if got := MessageResponseToEvent(msg, ConvertOptions{}).DebugWords; got != nil {
+		t.Errorf("DebugWords without option = %v, want nil", got)
+	}
+
+	got := MessageResponseToEvent(msg, ConvertOptions{DebugWords: true}).DebugWords
+	if len(got) != 1 {
+		t.Fatalf("DebugWords = %v, want 1 word", got)
+	}
+	if got[0].PunctuatedWord != "Hola." || got[0].Language != "es" || got[0].Confidence != 0.8 {
+		t.Errorf("DebugWords[0] = %+v", got[0])
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_DebugWords(t *testing.T) {
+	fixture := `{
+		"metadata": {"duration": 1.0},
+		"results": {"channels": [{"alternatives": [{
+			"transcript": "bonjour",
+			"words": [{"word": "bonjour", "punctuated_word": "Bonjour!", "start": 0.1, "end": 0.6,
+				"confidence": 0.91, "speaker": 0, "speaker_confidence": 0.7, "language": "fr"}]
+		}]}]}
+	}`
+
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	if got := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{}).DebugWords; got != nil {
+		t.Errorf("DebugWords without option = %v, want nil", got)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{DebugWords: true})
+	if len(result.DebugWords) != 1 {
+		t.Fatalf("DebugWords = %v, want 1 word", result.DebugWords)
+	}
+	w := result.DebugWords[0]
+	if w.PunctuatedWord != "Bonjour!" || w.Language != "fr" || w.Speaker == nil || w.SpeakerConfidence == nil {
+		t.Errorf("DebugWords[0] = %+v", w)
+	}
+	if result.Text != "bonjour" {
+		t.Errorf("Text = %q, want %q", result.Text, "bonjour")
+	}
+}
+
+func TestPreRecordedResponseToResult_Silence(t *testing.T) {
+	fixtures := map[string]string{
+		"empty alternative": `{"metadata":{"duration":2.0},"results":{"channels":[{"alternatives":[{"transcript":"","confidence":0,"words":[]}]}],"utterances":[]}}`,
+		"no alternatives":   `{"metadata":{"duration":2.0},"results":{"channels":[{"alternatives":[]}]}}`,
+		"no channels":       `{"metadata":{"duration":2.0},"results":{"channels":[]}}`,
+		"no results":        `{"metadata":{"duration":2.0}}`,
+	}
+
+	for name, fixture := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			var resp restinterfaces.PreRecordedResponse
+			if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+				t.Fatalf("unmarshal fixture: %v", err)
+			}
+
+			result := PreRecordedResponseToResult(&resp)
+			if result == nil {
+				t.Fatal("PreRecordedResponseToResult() = nil")
+			}
+			if result.Text != "" || result.Segments != nil {
+				t.Errorf("result = %+v, want empty Text and nil Segments", result)
+			}
+		})
+	}
+
+	if PreRecordedResponseToResult(nil) == nil {
+		t.Error("PreRecordedResponseToResult(nil) = nil")
+	}
+}
+
+func TestMessageResponseToStreamEvent_Silence(t *testing.T) {
+	event := MessageResponseToStreamEvent(&MessageResponse{IsFinal: true})
+	if event.Type != stt.EventTranscript || event.Transcript != "" || event.Segment != nil {
+		t.Errorf("event = %+v, want empty transcript event", event)
+	}
+	if !event.IsFinal {
+		t.Error("event.IsFinal = false, want the message's IsFinal")
+	}
+}
+
+func TestWordLanguage_Multilingual(t *testing.T) {
+	msg := &MessageResponse{IsFinal: true, Channel: Channel{Alternatives: []Alternative{{
+		Transcript: "hello amigo bonjour",
+		Words: []Word{
+			{Word: "hello", Start: 0.1, End: 0.4, Language: "en"},
+			{Word: "amigo", Start: 0.5, End: 0.9, Language: "es"},
+			{Word: "bonjour", Start: 1.0, End: 1.5, Language: "fr"},
+		},
+	}}}}
+
+	event := MessageResponseToEvent(msg, ConvertOptions{})
+	for i, want := range []string{"en", "es", "fr"} {
+		if got := event.Words[i].Language; got != want {
+			t.Errorf("stream word %d Language = %q, want %q", i, got, want)
+		}
+	}
+
+	fixture := `{"results": {"channels": [{"alternatives": [{
+		"transcript": "hello amigo bonjour",
+		"languages": ["en", "es", "fr"],
+		"words": [
+			{"word": "hello", "start": 0.1, "end": 0.4, "language": "en"},
+			{"word": "amigo", "start": 0.5, "end": 0.9, "language": "es"},
+			{"word": "bonjour", "start": 1.0, "end": 1.5, "language": "fr"}
+		]
+	}]}]}}`
+
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if len(result.Words) != 3 {
+		t.Fatalf("batch Words = %v, want 3 words", result.Words)
+	}
+	for i, want := range []string{"en", "es", "fr"} {
+		if got := result.Words[i].Language; got != want {
+			t.Errorf("batch word %d Language = %q, want %q", i, got, want)
+		}
+		if result.Words[i].ID != event.Words[i].ID {
+			t.Errorf("batch word %d ID = %q, want %q", i, result.Words[i].ID, event.Words[i].ID)
+		}
+	}
+}
+
+func TestDefaultSTTModel(t *testing.T) {
+	if got := ConfigToLiveTranscriptionOptions(stt.TranscriptionConfig{}).Model; got != DefaultSTTModel {
+		t.Errorf("live Model = %q, want %q", got, DefaultSTTModel)
+	}
+	if got := ConfigToPreRecordedOptions(stt.TranscriptionConfig{}).Model; got != DefaultSTTModel {
+		t.Errorf("prerecorded Model = %q, want %q", got, DefaultSTTModel)
+	}
+	if got := ConfigToPreRecordedOptions(stt.TranscriptionConfig{Model: "whisper"}).Model; got != "whisper" {
+		t.Errorf("prerecorded Model = %q, want explicit model", got)
+	}
+}
+
+func TestMessageResponseToEvent_FromFinalize(t *testing.T) {
+	// Result Deepgram sends after a Finalize request
+	fixture := `{
+		"type": "Results",
+		"channel": {"alternatives": [{"transcript": "cut off mid", "confidence": 0.9,
+			"words": [{"word": "cut", "start": 3.1, "end": 3.3}, {"word": "off", "start": 3.3, "end": 3.5}, {"word": "mid", "start": 3.5, "end": 3.7}]}]},
+		"is_final": true,
+		"from_finalize": true,
+		"start": 3.0,
+		"duration": 0.8
+	}`
+
+	var msg MessageResponse
+	if err := json.Unmarshal([]byte(fixture), &msg); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	event := MessageResponseToEvent(&msg, ConvertOptions{})
+	if !event.FromFinalize || !event.IsFinal {
+		t.Errorf("FromFinalize = %v, IsFinal = %v, want both true", event.FromFinalize, event.IsFinal)
+	}
+
+	msg.FromFinalize = false
+	if MessageResponseToEvent(&msg, ConvertOptions{}).FromFinalize {
+		t.Error("natural final marked FromFinalize")
+	}
+}
+
+func TestValidateEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+		wantDG   string
+	}{
+		{"", false, "linear16"},
+		{"pcm", false, "linear16"},
+		{"g711u", false, "mulaw"},
+		{"opus", false, "opus"},
+		{"amr-wb", false, "amr-wb"},
+		{"ogg-opus", false, "ogg-opus"},
+		{"linear32", false, "linear32"},
+		{"lienar16", true, ""},
+		{"mp4", true, ""},
+		{"PCM", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			err := ValidateEncoding(tt.encoding)
+			if tt.wantErr {
+				if !errors.Is(err, stt.ErrInvalidConfig) {
+					t.Fatalf("ValidateEncoding(%q) = %v, want ErrInvalidConfig", tt.encoding, err)
+				}
+				if !strings.Contains(err.Error(), tt.encoding) || !strings.Contains(err.Error(), "linear16") {
+					t.Errorf("error %q should name the encoding and list supported encodings", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateEncoding(%q) = %v, want nil", tt.encoding, err)
+			}
+			if got := ConfigToLiveTranscriptionOptions(stt.TranscriptionConfig{Encoding: tt.encoding}).Encoding; got != tt.wantDG {
+				t.Errorf("live Encoding = %q, want %q", got, tt.wantDG)
+			}
+		})
+	}
+}
+
+func TestMessageResponseToStreamEvent_MonotonicWordTimings(t *testing.T) {
+	message := func(start float64, words ...Word) *MessageResponse {
+		return &MessageResponse{
+			IsFinal:  true,
+			Start:    start,
+			Duration: 2,
+			Channel:  Channel{Alternatives: []Alternative{{Transcript: "x", Words: words}}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		messages []*MessageResponse
+	}{
+		{
+			name: "stream-relative",
+			messages: []*MessageResponse{
+				message(0, Word{Word: "one", Start: 0.1, End: 0.5}, Word{Word: "two", Start: 0.6, End: 1.0}),
+				message(2, Word{Word: "three", Start: 2.1, End: 2.5}, Word{Word: "four", Start: 2.6, End: 3.0}),
+				message(4, Word{Word: "five", Start: 4.2, End: 4.8}),
+			},
+		},
+		{
+			name: "message-relative",
+			messages: []*MessageResponse{
+				message(0, Word{Word: "one", Start: 0.1, End: 0.5}, Word{Word: "two", Start: 0.6, End: 1.0}),
+				message(2, Word{Word: "three", Start: 0.1, End: 0.5}, Word{Word: "four", Start: 0.6, End: 1.0}),
+				message(4, Word{Word: "five", Start: 0.2, End: 0.8}),
+			},
+		},
+	}
+
+	want := []time.Duration{100, 600, 2100, 2600, 4200}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var starts []time.Duration
+			var last time.Duration
+			for _, m := range tt.messages {
+				event := MessageResponseToStreamEvent(m)
+				for _, w := range event.Segment.Words {
+					if w.StartTime < last {
+						t.Errorf("word %q starts at %v, before previous end %v", w.Text, w.StartTime, last)
+					}
+					last = w.EndTime
+					starts = append(starts, w.StartTime/time.Millisecond)
+				}
+				if event.Segment.StartTime != event.Segment.Words[0].StartTime {
+					t.Errorf("segment start %v != first word start", event.Segment.StartTime)
+				}
+			}
+			if len(starts) != len(want) {
+				t.Fatalf("got %d words, want %d", len(starts), len(want))
+			}
+			for i := range want {
+				if starts[i] != want[i] {
+					t.Errorf("word %d start = %dms, want %dms", i, starts[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigToLiveTranscriptionOptions_DefaultSampleRate(t *testing.T) {
+	tests := []struct {
+		config stt.TranscriptionConfig
+		want   int
+	}{
+		{stt.TranscriptionConfig{}, 16000},
+		{stt.TranscriptionConfig{Encoding: "linear16"}, 16000},
+		{stt.TranscriptionConfig{Encoding: "pcm"}, 16000},
+		{stt.TranscriptionConfig{Encoding: "mulaw"}, 8000},
+		{stt.TranscriptionConfig{Encoding: "g711a"}, 8000},
+		{stt.TranscriptionConfig{Encoding: "mulaw", SampleRate: 16000}, 16000},
+		{stt.TranscriptionConfig{Encoding: "linear16", SampleRate: 48000}, 48000},
+	}
+	for _, tt := range tests {
+		if got := ConfigToLiveTranscriptionOptions(tt.config).SampleRate; got != tt.want {
+			t.Errorf("encoding %q rate %d: SampleRate = %d, want %d", tt.config.Encoding, tt.config.SampleRate, got, tt.want)
+		}
+	}
+}
+
+func TestParseWarning(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   *Warning
+		wantOK bool
+	}{
+		{`{"type":"Warning","variant":"DEPRECATED","description":"old param"}`, &Warning{Code: "DEPRECATED", Message: "old param"}, true},
+		{`{"type":"warning","warn_code":"W1","warn_msg":"slow down"}`, &Warning{Code: "W1", Message: "slow down"}, true},
+		{`{"type":"Results"}`, nil, false},
+		{`garbage`, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWarning([]byte(tt.raw))
+		if ok != tt.wantOK || (tt.want != nil && *got != *tt.want) {
+			t.Errorf("ParseWarning(%s) = %+v, %v; want %+v, %v", tt.raw, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_WordFields(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(diarizedFixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if len(result.Words) != 5 {
+		t.Fatalf("Words = %v, want 5 words", result.Words)
+	}
+
+	w := result.Words[2]
+	want := WordInfo{
+		Word: stt.Word{
+			Text:       "hello",
+			StartTime:  time.Second,
+			EndTime:    1300 * time.Millisecond,
+			Confidence: 0.8,
+			Speaker:    "speaker_1",
+		},
+		ID:             "w1000",
+		Index:          2,
+		PunctuatedWord: "Hello,",
+	}
+	if w != want {
+		t.Errorf("Words[2] = %+v, want %+v", w, want)
+	}
+
+	for i, w := range result.Words {
+		if w.PunctuatedWord == "" || w.Speaker == "" || w.EndTime <= w.StartTime || w.Confidence == 0 {
+			t.Errorf("Words[%d] = %+v, want all fields populated", i, w)
+		}
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_SegmentOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+	}{
+		{
+			name: "utterances",
+			fixture: `{"metadata":{"duration":6.0},"results":{"channels":[{"alternatives":[{"transcript":"one two three"}]}],"utterances":[
+				{"start":4.0,"end":5.0,"transcript":"three"},
+				{"start":0.5,"end":1.5,"transcript":"one"},
+				{"start":2.0,"end":3.0,"transcript":"two"}
+			]}}`,
+		},
+		{
+			name: "paragraphs",
+			fixture: `{"metadata":{"duration":6.0},"results":{"channels":[{"alternatives":[{"transcript":"one two three","paragraphs":{"paragraphs":[
+				{"start":2.0,"end":3.0,"sentences":[{"text":"two"}]},
+				{"start":4.0,"end":5.0,"sentences":[{"text":"three"}]},
+				{"start":0.5,"end":1.5,"sentences":[{"text":"one"}]}
+			]}}]}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp restinterfaces.PreRecordedResponse
+			if err := json.Unmarshal([]byte(tt.fixture), &resp); err != nil {
+				t.Fatalf("unmarshal fixture: %v", err)
+			}
+
+			result := PreRecordedResponseToResult(&resp)
+
+			var texts []string
+			for i, seg := range result.Segments {
+				texts = append(texts, seg.Text)
+				if i > 0 && seg.StartTime < result.Segments[i-1].StartTime {
+					t.Errorf("Segments[%d] starts at %v, before the previous segment", i, seg.StartTime)
+				}
+			}
+			if want := []string{"one", "two", "three"}; !slices.Equal(texts, want) {
+				t.Errorf("segment texts = %v, want %v", texts, want)
+			}
+		})
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Models(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		wantName    string
+		wantVersion string
+		wantModels  []ModelInfo
+	}{
+		{
+			name: "single model",
+			metadata: `{"models":["1abfe86b"],"model_info":{
+				"1abfe86b":{"name":"general-nova-3","version":"2024-12-20.0","arch":"nova-3"}}}`,
+			wantName:    "general-nova-3",
+			wantVersion: "2024-12-20.0",
+			wantModels: []ModelInfo{
+				{UUID: "1abfe86b", Name: "general-nova-3", Version: "2024-12-20.0", Arch: "nova-3"},
+			},
+		},
+		{
+			name: "multiple models",
+			metadata: `{"models":["c0d1a568","1abfe86b"],"model_info":{
+				"1abfe86b":{"name":"general-nova-3","version":"2024-12-20.0","arch":"nova-3"},
+				"c0d1a568":{"name":"general-nova-2","version":"2024-01-18.26916","arch":"nova-2"},
+				"9f2b7d3e":{"name":"language-detection","version":"2024-05-01.0","arch":"lid"}}}`,
+			wantName:    "general-nova-2",
+			wantVersion: "2024-01-18.26916",
+			wantModels: []ModelInfo{
+				{UUID: "c0d1a568", Name: "general-nova-2", Version: "2024-01-18.26916", Arch: "nova-2"},
+				{UUID: "1abfe86b", Name: "general-nova-3", Version: "2024-12-20.0", Arch: "nova-3"},
+				{UUID: "9f2b7d3e", Name: "language-detection", Version: "2024-05-01.0", Arch: "lid"},
+			},
+		},
+		{
+			name:       "model without details",
+			metadata:   `{"models":["1abfe86b"]}`,
+			wantModels: []ModelInfo{{UUID: "1abfe86b"}},
+		},
+		{
+			name:     "no models",
+			metadata: `{"request_id":"c2f1a9e0","duration":1.0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp restinterfaces.PreRecordedResponse
+			fixture := `{"metadata":` + tt.metadata + `,"results":{"channels":[]}}`
+			if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+				t.Fatalf("unmarshal fixture: %v", err)
+			}
+
+			result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+			if result.ModelName != tt.wantName || result.ModelVersion != tt.wantVersion {
+				t.Errorf("model = %q %q, want %q %q", result.ModelName, result.ModelVersion, tt.wantName, tt.wantVersion)
+			}
+			if !slices.Equal(result.Models, tt.wantModels) {
+				t.Errorf("Models = %+v, want %+v", result.Models, tt.wantModels)
+			}
+		})
+	}
+}
+
+func TestConfigToPreRecordedOptions_SearchReplace(t *testing.T) {
+	opts := ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: map[string]any{
+		ExtSearch:  []string{"refund", "cancel"},
+		ExtReplace: []string{"acme:ACME"},
+	}})
+	if want := []string{"refund", "cancel"}; !slices.Equal(opts.Search, want) {
+		t.Errorf("Search = %q, want %q", opts.Search, want)
+	}
+	if want := []string{"acme:ACME"}; !slices.Equal(opts.Replace, want) {
+		t.Errorf("Replace = %q, want %q", opts.Replace, want)
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Searches(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"metadata":{"duration":12.0},"results":{"channels":[{
+		"search":[
+			{"query":"refund","hits":[{"confidence":0.94,"start":3.25,"end":3.75,"snippet":"i want a refund"}]},
+			{"query":"cancel","hits":[]}
+		],
+		"alternatives":[{"transcript":"i want a refund"}]}]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	want := []SearchResult{
+		{Query: "refund", Hits: []SearchHit{{Confidence: 0.94, Start: 3250 * time.Millisecond, End: 3750 * time.Millisecond, Snippet: "i want a refund"}}},
+		{Query: "cancel", Hits: []SearchHit{}},
+	}
+	if len(result.Searches) != len(want) {
+		t.Fatalf("Searches = %+v, want %+v", result.Searches, want)
+	}
+	for i := range want {
+		if result.Searches[i].Query != want[i].Query || !slices.Equal(result.Searches[i].Hits, want[i].Hits) {
+			t.Errorf("Searches[%d] = %+v, want %+v", i, result.Searches[i], want[i])
+		}
+	}
+}
+
+func TestConfigToPreRecordedOptions_Summarize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"unset", nil, ""},
+		{"enabled", true, DefaultSummarizeVersion},
+		{"disabled", false, ""},
+		{"version", "v1", "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := map[string]any{}
+			if tt.value != nil {
+				ext[ExtSummarize] = tt.value
+			}
+			if got := ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: ext}).Summarize; got != tt.want {
+				t.Errorf("Summarize = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Summary(t *testing.T) {
+	tests := []struct {
+		name    string
+		results string
+		want    string
+	}{
+		{
+			name:    "v2",
+			results: `{"summary":{"result":"success","short":"The caller asked for a refund."},"channels":[{"alternatives":[{"transcript":"i want a refund"}]}]}`,
+			want:    "The caller asked for a refund.",
+		},
+		{
+			name:    "v1",
+			results: `{"channels":[{"alternatives":[{"transcript":"i want a refund","summaries":[{"summary":"Refund requested.","start_word":0,"end_word":3},{"summary":"Agent agreed.","start_word":4,"end_word":6}]}]}]}`,
+			want:    "Refund requested. Agent agreed.",
+		},
+		{
+			name:    "none",
+			results: `{"channels":[{"alternatives":[{"transcript":"hi"}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp restinterfaces.PreRecordedResponse
+			if err := json.Unmarshal([]byte(`{"results":`+tt.results+`}`), &resp); err != nil {
+				t.Fatalf("unmarshal fixture: %v", err)
+			}
+
+			if got := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{}).Summary; got != tt.want {
+				t.Errorf("Summary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_TopicsIntents(t *testing.T) {
+	opts := ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: map[string]any{ExtTopics: true, ExtIntents: true}})
+	if !opts.Topics || !opts.Intents {
+		t.Errorf("Topics = %v, Intents = %v, want both set", opts.Topics, opts.Intents)
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{
+		"channels":[{"alternatives":[{"transcript":"my bill is wrong please refund me"}]}],
+		"topics":{"segments":[
+			{"text":"my bill is wrong","start_word":0,"end_word":3,"topics":[{"topic":"Billing","confidence_score":0.87}]}
+		]},
+		"intents":{"segments":[
+			{"text":"please refund me","start_word":4,"end_word":6,"intents":[
+				{"intent":"Request refund","confidence_score":0.93},
+				{"intent":"Complain","confidence_score":0.41}]},
+			{"text":"thanks","start_word":7,"end_word":7}
+		]}}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	wantTopics := []Topic{
+		{Label: "Billing", Confidence: 0.87, Span: TextSpan{Text: "my bill is wrong", StartWord: 0, EndWord: 3}},
+	}
+	if !slices.Equal(result.Topics, wantTopics) {
+		t.Errorf("Topics = %+v, want %+v", result.Topics, wantTopics)
+	}
+	refund := TextSpan{Text: "please refund me", StartWord: 4, EndWord: 6}
+	wantIntents := []Intent{
+		{Label: "Request refund", Confidence: 0.93, Span: refund},
+		{Label: "Complain", Confidence: 0.41, Span: refund},
+	}
+	if !slices.Equal(result.Intents, wantIntents) {
+		t.Errorf("Intents = %+v, want %+v", result.Intents, wantIntents)
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Sentiments(t *testing.T) {
+	if !ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: map[string]any{ExtSentiment: true}}).Sentiment {
+		t.Error("Sentiment not set on the options")
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{
+		"channels":[{"alternatives":[{"transcript":"this is ridiculous thank you","words":[
+			{"word":"this","start":0.5,"end":0.7},
+			{"word":"is","start":0.7,"end":0.8},
+			{"word":"ridiculous","start":0.8,"end":1.4},
+			{"word":"thank","start":2.0,"end":2.2},
+			{"word":"you","start":2.2,"end":2.4}]}]}],
+		"sentiments":{
+			"segments":[
+				{"text":"this is ridiculous","start_word":0,"end_word":2,"sentiment":"negative","sentiment_score":-0.72},
+				{"text":"thank you","start_word":3,"end_word":4,"sentiment":"positive","sentiment_score":0.6}
+			],
+			"average":{"sentiment":"neutral","sentiment_score":-0.06}
+		}}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	want := []Sentiment{
+		{Label: "negative", Score: -0.72, Span: TextSpan{Text: "this is ridiculous", StartWord: 0, EndWord: 2, Start: 500 * time.Millisecond, End: 1400 * time.Millisecond}},
+		{Label: "positive", Score: 0.6, Span: TextSpan{Text: "thank you", StartWord: 3, EndWord: 4, Start: 2 * time.Second, End: 2400 * time.Millisecond}},
+	}
+	if !slices.Equal(result.Sentiments, want) {
+		t.Errorf("Sentiments = %+v, want %+v", result.Sentiments, want)
+	}
+	if o := result.OverallSentiment; o == nil || o.Label != "neutral" || o.Score != -0.06 {
+		t.Errorf("OverallSentiment = %+v, want neutral -0.06", o)
+	}
+
+	empty := PreRecordedResponseToTranscriptionResult(&restinterfaces.PreRecordedResponse{Results: &restinterfaces.Result{}}, ConvertOptions{})
+	if empty.Sentiments != nil || empty.OverallSentiment != nil {
+		t.Errorf("sentiments without analysis = %+v, %+v, want none", empty.Sentiments, empty.OverallSentiment)
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Entities(t *testing.T) {
+	if !ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: map[string]any{ExtEntities: true}}).DetectEntities {
+		t.Error("DetectEntities not set on the options")
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{"channels":[{"alternatives":[{
+		"transcript":"call jane doe on monday",
+		"words":[
+			{"word":"call","start":0.1,"end":0.3},
+			{"word":"jane","start":0.4,"end":0.6},
+			{"word":"doe","start":0.6,"end":0.9},
+			{"word":"on","start":1.0,"end":1.1},
+			{"word":"monday","start":1.1,"end":1.5}],
+		"entities":[
+			{"label":"NAME","value":"jane doe","confidence":0.98,"start_word":1,"end_word":3},
+			{"label":"DATE","value":"monday","confidence":0.91,"start_word":4,"end_word":5}]}]}]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	want := []Entity{
+		{Label: "NAME", Value: "jane doe", Confidence: 0.98, Span: TextSpan{Text: "jane doe", StartWord: 1, EndWord: 2, Start: 400 * time.Millisecond, End: 900 * time.Millisecond}},
+		{Label: "DATE", Value: "monday", Confidence: 0.91, Span: TextSpan{Text: "monday", StartWord: 4, EndWord: 4, Start: 1100 * time.Millisecond, End: 1500 * time.Millisecond}},
+	}
+	if !slices.Equal(result.Entities, want) {
+		t.Errorf("Entities = %+v, want %+v", result.Entities, want)
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	config := stt.TranscriptionConfig{Extensions: map[string]any{ExtAlternatives: 3}}
+	if got := ConfigToPreRecordedOptions(config).Alternatives; got != 3 {
+		t.Errorf("prerecorded Alternatives = %d, want 3", got)
+	}
+	if got := ConfigToLiveTranscriptionOptions(config).Alternatives; got != 3 {
+		t.Errorf("live Alternatives = %d, want 3", got)
+	}
+	if got := ConfigToPreRecordedOptions(stt.TranscriptionConfig{}).Alternatives; got != 0 {
+		t.Errorf("default Alternatives = %d, want 0 for Deepgram's default", got)
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{"channels":[{"alternatives":[
+		{"transcript":"recognize speech","confidence":0.91,"words":[
+			{"word":"recognize","start":0.1,"end":0.6},{"word":"speech","start":0.6,"end":1.0}]},
+		{"transcript":"wreck a nice beach","confidence":0.42,"words":[
+			{"word":"wreck","start":0.1,"end":0.3},{"word":"a","start":0.3,"end":0.35},
+			{"word":"nice","start":0.35,"end":0.6},{"word":"beach","start":0.6,"end":1.0}]}]}]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if result.Text != "recognize speech" {
+		t.Errorf("Text = %q, want the top alternative", result.Text)
+	}
+	if len(result.Alternatives) != 2 {
+		t.Fatalf("got %d alternatives, want 2", len(result.Alternatives))
+	}
+	if alt := result.Alternatives[1]; alt.Transcript != "wreck a nice beach" || alt.Confidence != 0.42 || len(alt.Words) != 4 {
+		t.Errorf("Alternatives[1] = %+v", alt)
+	}
+
+	event := MessageResponseToEvent(&MessageResponse{IsFinal: true, Channel: Channel{Alternatives: []Alternative{
+		{Transcript: "recognize speech", Confidence: 0.91, Words: []Word{{Word: "recognize", Start: 0.1, End: 0.6}, {Word: "speech", Start: 0.6, End: 1.0}}},
+		{Transcript: "wreck a nice beach", Confidence: 0.42, Words: []Word{{Word: "wreck", Start: 0.1, End: 0.3}}},
+	}}}, ConvertOptions{})
+	if event.Transcript != "recognize speech" {
+		t.Errorf("Transcript = %q, want the top alternative", event.Transcript)
+	}
+	if len(event.Alternatives) != 2 {
+		t.Fatalf("got %d event alternatives, want 2", len(event.Alternatives))
+	}
+	if alt := event.Alternatives[1]; alt.Transcript != "wreck a nice beach" || len(alt.Words) != 1 || alt.Words[0].EndTime != 300*time.Millisecond {
+		t.Errorf("event Alternatives[1] = %+v", alt)
+	}
+
+	single := MessageResponseToEvent(&MessageResponse{Channel: Channel{Alternatives: []Alternative{{Transcript: "hi"}}}}, ConvertOptions{})
+	if single.Alternatives != nil {
+		t.Errorf("Alternatives = %+v for a single alternative, want nil", single.Alternatives)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	detect := map[string]any{ExtDetectLanguage: true}
+	tests := []struct {
+		name         string
+		config       stt.TranscriptionConfig
+		wantDetect   bool
+		wantLanguage string
+	}{
+		{"default", stt.TranscriptionConfig{}, false, "en-US"},
+		{"detect", stt.TranscriptionConfig{Extensions: detect}, true, ""},
+		{"explicit language skips detection", stt.TranscriptionConfig{Language: "de", Extensions: detect}, false, "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ConfigToPreRecordedOptions(tt.config)
+			if opts.DetectLanguage != tt.wantDetect || opts.Language != tt.wantLanguage {
+				t.Errorf("DetectLanguage = %v, Language = %q; want %v, %q", opts.DetectLanguage, opts.Language, tt.wantDetect, tt.wantLanguage)
+			}
+		})
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{"channels":[{"detected_language":"es","language_confidence":0.97,
+		"alternatives":[{"transcript":"hola a todos"}]}]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if result.Language != "es" || result.LanguageConfidence != 0.97 {
+		t.Errorf("Language = %q (%v), want es (0.97)", result.Language, result.LanguageConfidence)
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_Paragraphs(t *testing.T) {
+	if !ConfigToPreRecordedOptions(stt.TranscriptionConfig{Extensions: map[string]any{ExtParagraphs: true}}).Paragraphs {
+		t.Error("Paragraphs not set on the options")
+	}
+
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"results":{"channels":[{"alternatives":[{
+		"transcript":"Hello. How are you? Fine, thanks.",
+		"paragraphs":{"paragraphs":[
+			{"start":0.1,"end":2.5,"speaker":0,"num_words":4,"sentences":[
+				{"text":"Hello.","start":0.1,"end":0.6},
+				{"text":"How are you?","start":0.9,"end":2.5}]},
+			{"start":3.0,"end":4.2,"speaker":1,"num_words":2,"sentences":[
+				{"text":"Fine, thanks.","start":3.0,"end":4.2}]}]}}]}]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if len(result.Paragraphs) != 2 {
+		t.Fatalf("got %d paragraphs, want 2", len(result.Paragraphs))
+	}
+	first := result.Paragraphs[0]
+	if first.Text != "Hello. How are you?" || first.Start != 100*time.Millisecond || first.End != 2500*time.Millisecond || first.Speaker != "speaker_0" {
+		t.Errorf("Paragraphs[0] = %+v", first)
+	}
+	wantSentences := []Sentence{
+		{Text: "Hello.", Start: 100 * time.Millisecond, End: 600 * time.Millisecond},
+		{Text: "How are you?", Start: 900 * time.Millisecond, End: 2500 * time.Millisecond},
+	}
+	if !slices.Equal(first.Sentences, wantSentences) {
+		t.Errorf("Sentences = %+v, want %+v", first.Sentences, wantSentences)
+	}
+	if second := result.Paragraphs[1]; second.Speaker != "speaker_1" || second.Text != "Fine, thanks." {
+		t.Errorf("Paragraphs[1] = %+v", second)
+	}
+
+	var plain restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(`{"results":{"channels":[{"alternatives":[{"transcript":"hi"}]}]}}`), &plain); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	if got := PreRecordedResponseToTranscriptionResult(&plain, ConvertOptions{}).Paragraphs; got != nil {
+		t.Errorf("Paragraphs = %+v without a paragraphs block, want nil", got)
+	}
+}
+
+func TestPreRecordedResponseToTranscriptionResult_RequestID(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	fixture := `{"metadata":{"request_id":"c2f1a9e0","duration":1.5},"results":{"channels":[]}}`
+	if err := json.Unmarshal([]byte(fixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+	if result.RequestID != "c2f1a9e0" {
+		t.Errorf("RequestID = %q, want %q", result.RequestID, "c2f1a9e0")
+	}
+}
+
+func TestMetadataResponseToEvent(t *testing.T) {
+	event := MetadataResponseToEvent(&MetadataResponse{
+		RequestID: "c2f1a9e0",
+		Duration:  2.5,
+		Channels:  1,
+		Models:    []string{"1abfe86b"},
+		ModelInfo: map[string]ModelInfo{
+			"1abfe86b": {Name: "general-nova-3", Version: "2024-12-20.0", Arch: "nova-3"},
+		},
+	})
+
+	if event.Type != EventMetadata {
+		t.Errorf("Type = %q, want %q", event.Type, EventMetadata)
+	}
+	md := event.Metadata
+	if md == nil {
+		t.Fatal("Metadata = nil")
+	}
+	if md.RequestID != "c2f1a9e0" || md.Duration != 2500*time.Millisecond || md.Channels != 1 {
+		t.Errorf("Metadata = %+v", *md)
+	}
+	want := []ModelInfo{{UUID: "1abfe86b", Name: "general-nova-3", Version: "2024-12-20.0", Arch: "nova-3"}}
+	if !slices.Equal(md.Models, want) {
+		t.Errorf("Models = %+v, want %+v", md.Models, want)
+	}
+}
+
+func TestListenContext(t *testing.T) {
+	ctx := context.Background()
+	if got := ListenContext(ctx, stt.TranscriptionConfig{}); got != ctx {
+		t.Error("ListenContext() without dictation changed the context")
+	}
+
+	parent := interfaces.WithCustomParameters(ctx, map[string][]string{"mip_opt_out": {"true"}})
+	got := ListenContext(parent, stt.TranscriptionConfig{Extensions: map[string]any{ExtDictation: true}})
+
+	params, ok := got.Value(interfaces.ParametersContext{}).(map[string][]string)
+	if !ok {
+		t.Fatal("ListenContext() carries no custom parameters")
+	}
+	if want := []string{"true"}; !slices.Equal(params["dictation"], want) {
+		t.Errorf("dictation = %v, want %v", params["dictation"], want)
+	}
+	if want := []string{"true"}; !slices.Equal(params["mip_opt_out"], want) {
+		t.Errorf("mip_opt_out = %v, want %v kept from the parent", params["mip_opt_out"], want)
+	}
+}
@@ -1,82 +1,258 @@
 package omnivoice
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
 	"github.com/plexusone/omnivoice-core/tts"
 )
 
+// SpeakContext returns ctx carrying the query parameters for config that
+// the SDK's SpeakOptions and WSSpeakOptions have no field for, currently
+// the ExtExtra metadata. The SDK adds them to the request URL of REST and
+// WebSocket synthesis alike. Custom parameters already in ctx are kept.
+// ctx is returned unchanged when there is nothing to add.
+func SpeakContext(ctx context.Context, config tts.SynthesisConfig) context.Context {
+	extra, _ := config.Extensions[ExtExtra].(map[string]string)
+	if len(extra) == 0 {
+		return ctx
+	}
+
+	params := map[string][]string{}
+	for _, key := range slices.Sorted(maps.Keys(extra)) {
+		params["extra"] = append(params["extra"], key+":"+extra[key])
+	}
+
+	return withParameters(ctx, params)
+}
+
 // ConfigToSpeakOptions converts OmniVoice SynthesisConfig to Deepgram SpeakOptions.
+// A config naming no model or voice gets the LanguageVoices default for its
+// ExtLanguage.
+// It does not check the config; call ValidateSynthesisConfig first.
 func ConfigToSpeakOptions(config tts.SynthesisConfig) *interfaces.SpeakOptions {
 	opts := &interfaces.SpeakOptions{
-		Model:      config.Model,
+		Model:      ttsModel(config),
 		Encoding:   mapTTSEncoding(config.OutputFormat),
 		SampleRate: config.SampleRate,
+		BitRate:    BitRate(config),
 	}
 
-	// If VoiceID provided but Model not set, use VoiceID as model
-	// Deepgram uses the model name as the voice identifier
-	if opts.Model == "" && config.VoiceID != "" {
-		opts.Model = config.VoiceID
+	// Wrap Opus in an Ogg container so the audio is playable by browsers
+	// and WebRTC stacks rather than being a bare packet stream
+	if opts.Encoding == "opus" {
+		opts.Container = "ogg"
 	}
 
-	// Default model if none specified
-	if opts.Model == "" {
-		opts.Model = DefaultTTSModel
+	// Fixed-rate encodings can't honor other rates; don't send them
+	if rate, fixed := fixedSampleRates[opts.Encoding]; fixed && opts.SampleRate != rate {
+		opts.SampleRate = 0
 	}
 
 	return opts
 }
 
-// ConfigToWSSpeakOptions converts OmniVoice SynthesisConfig to Deepgram WSSpeakOptions.
-func ConfigToWSSpeakOptions(config tts.SynthesisConfig) *interfaces.WSSpeakOptions {
-	opts := &interfaces.WSSpeakOptions{
-		Model:      config.Model,
-		Encoding:   mapTTSEncoding(config.OutputFormat),
-		SampleRate: config.SampleRate,
+// fixedSampleRates holds the output sample rate of Deepgram encodings whose
+// rate cannot be configured. MP3 and AAC rates are derived from the bit rate.
+var fixedSampleRates = map[string]int{
+	"mp3":  22050,
+	"aac":  22050,
+	"opus": 48000,
+}
+
+// bitRateRange is the inclusive range of bit rates Deepgram accepts for an
+// encoding; values, when set, lists the only ones it accepts.
+type bitRateRange struct {
+	min, max int
+	values   []int
+}
+
+// allows reports whether rate is within r.
+func (r bitRateRange) allows(rate int) bool {
+	if r.values != nil {
+		return slices.Contains(r.values, rate)
 	}
+	return rate >= r.min && rate <= r.max
+}
 
-	// If VoiceID provided but Model not set, use VoiceID as model
-	if opts.Model == "" && config.VoiceID != "" {
-		opts.Model = config.VoiceID
+// String describes r for error messages.
+func (r bitRateRange) String() string {
+	if r.values != nil {
+		s := make([]string, len(r.values))
+		for i, v := range r.values {
+			s[i] = strconv.Itoa(v)
+		}
+		return strings.Join(s, " or ")
 	}
+	return fmt.Sprintf("%d to %d", r.min, r.max)
+}
+
+// ttsBitRates holds the bit rates Deepgram accepts for the encodings whose
+// bit rate can be configured.
+var ttsBitRates = map[string]bitRateRange{
+	"mp3":  {values: []int{32000, 48000}},
+	"opus": {min: 4000, max: 650000},
+	"aac":  {min: 4000, max: 192000},
+}
+
+// defaultSampleRates holds Deepgram's default output sample rate for
+// encodings with a configurable rate.
+var defaultSampleRates = map[string]int{
+	"linear16": 24000,
+	"flac":     48000,
+	"mulaw":    8000,
+	"alaw":     8000,
+}
 
-	// Default model if none specified
-	if opts.Model == "" {
-		opts.Model = DefaultTTSModel
+// EffectiveSampleRate returns the sample rate of the audio Deepgram produces
+// for opts, accounting for encodings that ignore the requested rate.
+func EffectiveSampleRate(opts *interfaces.SpeakOptions) int {
+	if rate, ok := fixedSampleRates[opts.Encoding]; ok {
+		return rate
+	}
+	if opts.SampleRate > 0 {
+		return opts.SampleRate
 	}
+	if rate, ok := defaultSampleRates[opts.Encoding]; ok {
+		return rate
+	}
+	return defaultSampleRates["linear16"]
+}
 
-	return opts
+// pcmBytesPerSample holds the sample size of the headerless PCM encodings,
+// whose duration can be derived from their length.
+var pcmBytesPerSample = map[string]int{
+	"linear16": 2,
+	"mulaw":    1,
+	"alaw":     1,
+}
+
+// PCMDuration returns the playback duration of n bytes of mono audio in
+// the given Deepgram encoding at sampleRate. It returns false for encodings
+// other than linear16, mulaw, and alaw, whose duration cannot be derived
+// from their length.
+func PCMDuration(encoding string, sampleRate, n int) (time.Duration, bool) {
+	size, ok := pcmBytesPerSample[encoding]
+	if !ok || sampleRate <= 0 {
+		return 0, false
+	}
+	samples := int64(n / size)
+	return time.Duration(samples * int64(time.Second) / int64(sampleRate)), true
+}
+
+// ConfigToWSSpeakOptions converts OmniVoice SynthesisConfig to Deepgram WSSpeakOptions.
+func ConfigToWSSpeakOptions(config tts.SynthesisConfig) *interfaces.WSSpeakOptions {
+	return &interfaces.WSSpeakOptions{
+		Model:      ttsModel(config),
+		Encoding:   mapTTSEncoding(config.OutputFormat),
+		SampleRate: config.SampleRate,
+		BitRate:    BitRate(config),
+	}
+}
+
+// ttsEncodingAliases maps OmniVoice output format names to Deepgram
+// encoding strings.
+var ttsEncodingAliases = map[string]string{
+	"mp3":       "mp3",
+	"linear16":  "linear16",
+	"pcm":       "linear16",
+	"pcm_s16le": "linear16",
+	"wav":       "linear16",
+	"mulaw":     "mulaw",
+	"ulaw":      "mulaw",
+	"g711u":     "mulaw",
+	"pcm_mulaw": "mulaw",
+	"alaw":      "alaw",
+	"g711a":     "alaw",
+	"pcm_alaw":  "alaw",
+	"opus":      "opus",
+	"ogg_opus":  "opus",
+	"ogg":       "opus",
+	"flac":      "flac",
+	"aac":       "aac",
 }
 
 // mapTTSEncoding maps OmniVoice output format names to Deepgram encoding strings.
 func mapTTSEncoding(format string) string {
-	switch format {
-	case "mp3":
-		return "mp3"
-	case "linear16", "pcm", "pcm_s16le", "wav":
-		return "linear16"
-	case "mulaw", "ulaw", "g711u", "pcm_mulaw":
-		return "mulaw"
-	case "alaw", "g711a", "pcm_alaw":
-		return "alaw"
-	case "opus":
-		return "opus"
-	case "flac":
-		return "flac"
-	case "aac":
-		return "aac"
-	default:
+	if format == "" {
 		// Default to linear16 for PCM
-		if format == "" {
-			return "linear16"
-		}
-		return format
+		return "linear16"
 	}
+	if dg, ok := ttsEncodingAliases[format]; ok {
+		return dg
+	}
+	return format
 }
 
 // DefaultTTSModel is the default TTS model to use.
 const DefaultTTSModel = "aura-asteria-en"
 
+// ModelEncodings restricts the Deepgram output encodings allowed for TTS
+// models, keyed by model name or by a model name prefix such as "aura-2-";
+// the longest matching key applies. ValidateSynthesisConfig rejects other
+// encodings for a listed model rather than leaving Deepgram to fail the
+// request. Deepgram documents the same encodings for all its Aura models,
+// so the map is empty by default and no model is checked; entries may be
+// added, before any synthesis starts, to restrict a model.
+var ModelEncodings = map[string][]string{}
+
+// modelEncodings returns the encodings ModelEncodings lists for model, or
+// false when no key matches it.
+func modelEncodings(model string) ([]string, bool) {
+	var match string
+	found := false
+	for key := range ModelEncodings {
+		if strings.HasPrefix(model, key) && len(key) >= len(match) {
+			match, found = key, true
+		}
+	}
+	return ModelEncodings[match], found
+}
+
+// LanguageVoices maps BCP-47 language tags to the voice used for a
+// language when a config sets ExtLanguage but neither Model nor VoiceID.
+// Languages not listed use DefaultTTSModel. The map may be changed to
+// prefer other voices, before any synthesis starts.
+var LanguageVoices = map[string]string{
+	"en-US": "aura-asteria-en",
+	"en-GB": "aura-helios-en",
+	"en-IE": "aura-angus-en",
+}
+
+// ttsModel returns the model config selects: Model, then VoiceID, since
+// Deepgram uses the model name as the voice identifier, then the default
+// voice for its ExtLanguage.
+func ttsModel(config tts.SynthesisConfig) string {
+	switch {
+	case config.Model != "":
+		return config.Model
+	case config.VoiceID != "":
+		return config.VoiceID
+	default:
+		return languageVoice(SynthesisLanguage(config))
+	}
+}
+
+// languageVoice returns the LanguageVoices entry for language, matching
+// the tag case-insensitively, or DefaultTTSModel if there is none.
+func languageVoice(language string) string {
+	if voice, ok := LanguageVoices[language]; ok {
+		return voice
+	}
+	for tag, voice := range LanguageVoices {
+		if strings.EqualFold(tag, language) {
+			return voice
+		}
+	}
+	return DefaultTTSModel
+}
+
 // Voice represents a Deepgram TTS voice.
 type Voice struct {
 	ID       string
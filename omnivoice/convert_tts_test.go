@@ -1,8 +1,12 @@
 package omnivoice
 
 import (
+	"context"
+	"slices"
 	"testing"
+	"time"
 
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
 	"github.com/plexusone/omnivoice-core/tts"
 )
 
@@ -76,6 +80,34 @@ func TestConfigToSpeakOptions(t *testing.T) {
 			wantEncoding:   "linear16",
 			wantSampleRate: 22050,
 		},
+		{
+			name:           "language default en-GB",
+			config:         tts.SynthesisConfig{Extensions: map[string]any{ExtLanguage: "en-GB"}},
+			wantModel:      "aura-helios-en",
+			wantEncoding:   "linear16",
+			wantSampleRate: 0,
+		},
+		{
+			name:           "language default en-IE",
+			config:         tts.SynthesisConfig{Extensions: map[string]any{ExtLanguage: "en-ie"}},
+			wantModel:      "aura-angus-en",
+			wantEncoding:   "linear16",
+			wantSampleRate: 0,
+		},
+		{
+			name:           "unlisted language falls back",
+			config:         tts.SynthesisConfig{Extensions: map[string]any{ExtLanguage: "en-AU"}},
+			wantModel:      DefaultTTSModel,
+			wantEncoding:   "linear16",
+			wantSampleRate: 0,
+		},
+		{
+			name:           "voiceID takes precedence over language",
+			config:         tts.SynthesisConfig{VoiceID: "aura-orion-en", Extensions: map[string]any{ExtLanguage: "en-GB"}},
+			wantModel:      "aura-orion-en",
+			wantEncoding:   "linear16",
+			wantSampleRate: 0,
+		},
 		{
 			name: "full config",
 			config: tts.SynthesisConfig{
@@ -151,6 +183,93 @@ func TestConfigToWSSpeakOptions(t *testing.T) {
 	}
 }
 
+func TestConfigToSpeakOptions_OggContainer(t *testing.T) {
+	tests := []struct {
+		format        string
+		wantContainer string
+	}{
+		{"opus", "ogg"},
+		{"ogg_opus", "ogg"},
+		{"mp3", ""},
+		{"linear16", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			opts := ConfigToSpeakOptions(tts.SynthesisConfig{OutputFormat: tt.format})
+			if opts.Container != tt.wantContainer {
+				t.Errorf("Container = %q, want %q", opts.Container, tt.wantContainer)
+			}
+		})
+	}
+}
+
+func TestConfigToSpeakOptions_MP3IgnoresSampleRate(t *testing.T) {
+	opts := ConfigToSpeakOptions(tts.SynthesisConfig{OutputFormat: "mp3", SampleRate: 24000})
+
+	if opts.SampleRate != 0 {
+		t.Errorf("SampleRate = %d, want 0 (not sent for mp3)", opts.SampleRate)
+	}
+	if got := EffectiveSampleRate(opts); got != 22050 {
+		t.Errorf("EffectiveSampleRate() = %d, want 22050", got)
+	}
+}
+
+func TestConfigToSpeakOptions_BitRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tts.SynthesisConfig
+		want   int
+	}{
+		{"unset", tts.SynthesisConfig{OutputFormat: "mp3"}, 0},
+		{"mp3", tts.SynthesisConfig{OutputFormat: "mp3", Extensions: map[string]any{ExtBitRate: 32000}}, 32000},
+		{"opus", tts.SynthesisConfig{OutputFormat: "ogg_opus", Extensions: map[string]any{ExtBitRate: 24000}}, 24000},
+		{"aac", tts.SynthesisConfig{OutputFormat: "aac", Extensions: map[string]any{ExtBitRate: 96000}}, 96000},
+		{"ignored for linear16", tts.SynthesisConfig{Extensions: map[string]any{ExtBitRate: 48000}}, 0},
+		{"ignored for mulaw", tts.SynthesisConfig{OutputFormat: "ulaw", Extensions: map[string]any{ExtBitRate: 48000}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConfigToSpeakOptions(tt.config).BitRate; got != tt.want {
+				t.Errorf("SpeakOptions.BitRate = %d, want %d", got, tt.want)
+			}
+			if got := ConfigToWSSpeakOptions(tt.config).BitRate; got != tt.want {
+				t.Errorf("WSSpeakOptions.BitRate = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveSampleRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tts.SynthesisConfig
+		want   int
+	}{
+		{"linear16 default", tts.SynthesisConfig{}, 24000},
+		{"linear16 requested", tts.SynthesisConfig{SampleRate: 16000}, 16000},
+		{"wav default", tts.SynthesisConfig{OutputFormat: "wav"}, 24000},
+		{"mulaw default", tts.SynthesisConfig{OutputFormat: "mulaw"}, 8000},
+		{"mulaw requested", tts.SynthesisConfig{OutputFormat: "ulaw", SampleRate: 16000}, 16000},
+		{"alaw default", tts.SynthesisConfig{OutputFormat: "alaw"}, 8000},
+		{"flac default", tts.SynthesisConfig{OutputFormat: "flac"}, 48000},
+		{"mp3 default", tts.SynthesisConfig{OutputFormat: "mp3"}, 22050},
+		{"mp3 requested rate ignored", tts.SynthesisConfig{OutputFormat: "mp3", SampleRate: 44100}, 22050},
+		{"aac default", tts.SynthesisConfig{OutputFormat: "aac"}, 22050},
+		{"opus fixed", tts.SynthesisConfig{OutputFormat: "opus"}, 48000},
+		{"opus requested rate ignored", tts.SynthesisConfig{OutputFormat: "ogg_opus", SampleRate: 16000}, 48000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveSampleRate(ConfigToSpeakOptions(tt.config)); got != tt.want {
+				t.Errorf("EffectiveSampleRate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMapTTSEncoding(t *testing.T) {
 	tests := []struct {
 		input string
@@ -169,6 +288,8 @@ func TestMapTTSEncoding(t *testing.T) {
 		{"g711a", "alaw"},
 		{"pcm_alaw", "alaw"},
 		{"opus", "opus"},
+		{"ogg_opus", "opus"},
+		{"ogg", "opus"},
 		{"flac", "flac"},
 		{"aac", "aac"},
 		{"", "linear16"},
@@ -245,3 +366,62 @@ func TestDeepgramVoices(t *testing.T) {
 		t.Errorf("Default TTS model %q not found in DeepgramVoices", DefaultTTSModel)
 	}
 }
+
+func TestLanguageVoices(t *testing.T) {
+	for language, id := range LanguageVoices {
+		i := slices.IndexFunc(DeepgramVoices, func(v Voice) bool { return v.ID == id })
+		if i < 0 {
+			t.Errorf("LanguageVoices[%q] = %q, not in DeepgramVoices", language, id)
+			continue
+		}
+		if got := DeepgramVoices[i].Language; got != language {
+			t.Errorf("LanguageVoices[%q] = %q, which speaks %s", language, id, got)
+		}
+	}
+}
+
+func TestPCMDuration(t *testing.T) {
+	tests := []struct {
+		encoding   string
+		sampleRate int
+		n          int
+		want       time.Duration
+		wantOK     bool
+	}{
+		{"linear16", 24000, 48000, time.Second, true},
+		{"linear16", 16000, 3200, 100 * time.Millisecond, true},
+		{"mulaw", 8000, 800, 100 * time.Millisecond, true},
+		{"alaw", 8000, 4000, 500 * time.Millisecond, true},
+		{"mp3", 22050, 1000, 0, false},
+		{"linear16", 0, 1000, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := PCMDuration(tt.encoding, tt.sampleRate, tt.n)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("PCMDuration(%q, %d, %d) = %v, %v; want %v, %v", tt.encoding, tt.sampleRate, tt.n, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSpeakContext(t *testing.T) {
+	ctx := context.Background()
+	if got := SpeakContext(ctx, tts.SynthesisConfig{}); got != ctx {
+		t.Error("SpeakContext() without extra changed the context")
+	}
+
+	parent := interfaces.WithCustomParameters(ctx, map[string][]string{"mip_opt_out": {"true"}})
+	got := SpeakContext(parent, tts.SynthesisConfig{Extensions: map[string]any{
+		ExtExtra: map[string]string{"tenant": "acme", "job": "1234"},
+	}})
+
+	params, ok := got.Value(interfaces.ParametersContext{}).(map[string][]string)
+	if !ok {
+		t.Fatal("SpeakContext() carries no custom parameters")
+	}
+	if want := []string{"job:1234", "tenant:acme"}; !slices.Equal(params["extra"], want) {
+		t.Errorf("extra = %v, want %v", params["extra"], want)
+	}
+	if want := []string{"true"}; !slices.Equal(params["mip_opt_out"], want) {
+		t.Errorf("mip_opt_out = %v, want %v kept from the parent", params["mip_opt_out"], want)
+	}
+}
@@ -0,0 +1,17 @@
+package omnivoice
+
+import "unicode/utf8"
+
+// EstimateCharacters returns the number of billable characters Deepgram
+// will charge for synthesizing text, so that callers can check a budget
+// before calling Synthesize.
+//
+// Deepgram bills every character of the text as sent, including spaces,
+// newlines, and punctuation, and counts each Unicode code point once
+// regardless of its encoded length. The estimate therefore matches the
+// CharacterCount Deepgram reports for the same text. Text is not trimmed
+// or normalized before counting, since it is not before sending; invalid
+// UTF-8 counts one character per invalid byte.
+func EstimateCharacters(text string) int {
+	return utf8.RuneCountInString(text)
+}
@@ -0,0 +1,27 @@
+package omnivoice
+
+import "testing"
+
+func TestEstimateCharacters(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"plain", "Hello", 5},
+		{"spaces and punctuation count", "Hello, world!", 13},
+		{"surrounding whitespace counts", "  Hi  ", 6},
+		{"newlines count", "Line one.\nLine two.", 19},
+		{"multi-byte runes count once", "Café déjà vu", 12},
+		{"CJK", "こんにちは", 5},
+		{"emoji", "Nice 👍", 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateCharacters(tt.text); got != tt.want {
+				t.Errorf("EstimateCharacters(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package omnivoice
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numericDate matches the month/day/year dates produced by Deepgram's smart
+// formatting, such as 3/15/2024.
+var numericDate = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`)
+
+// yearFirstLanguages write dates year first.
+var yearFirstLanguages = map[string]bool{
+	"ja": true,
+	"ko": true,
+	"zh": true,
+}
+
+// monthFirstRegions write dates month first.
+var monthFirstRegions = map[string]bool{
+	"US": true,
+	"PH": true,
+}
+
+// FormatDates rewrites the month/day/year dates Deepgram's smart formatting
+// produces into the date order of the BCP-47 locale: day first, as in
+// 15/03/2024, for most locales, and year first, as in 2024/03/15, for
+// Chinese, Japanese, and Korean. Text is returned unchanged for US and
+// Philippine locales, English without a region, an empty locale, and dates
+// that are not valid month/day combinations.
+//
+// Deepgram's smart formatting has no locale parameter, so this is applied
+// to transcripts after they are received.
+func FormatDates(text, locale string) string {
+	lang, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	lang = strings.ToLower(lang)
+	region = strings.ToUpper(region)
+	if lang == "" || monthFirstRegions[region] || (lang == "en" && region == "") {
+		return text
+	}
+	yearFirst := yearFirstLanguages[lang]
+
+	return numericDate.ReplaceAllStringFunc(text, func(date string) string {
+		m := numericDate.FindStringSubmatch(date)
+		month, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return date
+		}
+		if yearFirst {
+			return fmt.Sprintf("%s/%02d/%02d", m[3], month, day)
+		}
+		return fmt.Sprintf("%02d/%02d/%s", day, month, m[3])
+	})
+}
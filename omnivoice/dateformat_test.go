@@ -0,0 +1,47 @@
+package omnivoice
+
+import (
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestFormatDates(t *testing.T) {
+	tests := []struct {
+		text   string
+		locale string
+		want   string
+	}{
+		{"Due on 3/15/2024.", "en-GB", "Due on 15/03/2024."},
+		{"From 12/1/2023 to 1/31/2024", "de-DE", "From 01/12/2023 to 31/01/2024"},
+		{"Due on 3/15/2024.", "en_AU", "Due on 15/03/2024."},
+		{"Due on 3/15/2024.", "ja-JP", "Due on 2024/03/15."},
+		{"Due on 3/15/2024.", "en-US", "Due on 3/15/2024."},
+		{"Due on 3/15/2024.", "en", "Due on 3/15/2024."},
+		{"Due on 3/15/2024.", "", "Due on 3/15/2024."},
+		{"Score was 13/15/2024", "en-GB", "Score was 13/15/2024"},
+		{"Ratio 3/4 and 10/20", "fr-FR", "Ratio 3/4 and 10/20"},
+	}
+	for _, tt := range tests {
+		if got := FormatDates(tt.text, tt.locale); got != tt.want {
+			t.Errorf("FormatDates(%q, %q) = %q, want %q", tt.text, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestMessageResponseToEvent_FormatLocale(t *testing.T) {
+	msg := &MessageResponse{
+		IsFinal: true,
+		Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "see you on 7/4/2025",
+			Words:      []Word{{Word: "see", Start: 0.1, End: 0.3}},
+		}}},
+	}
+
+	config := stt.TranscriptionConfig{Extensions: map[string]any{ExtFormatLocale: "en-GB"}}
+	event := MessageResponseToEvent(msg, ConvertOptions{FormatLocale: FormatLocale(config)})
+
+	if want := "see you on 04/07/2025"; event.Transcript != want || event.Segment.Text != want {
+		t.Errorf("Transcript = %q, Segment.Text = %q, want %q", event.Transcript, event.Segment.Text, want)
+	}
+}
@@ -0,0 +1,37 @@
+package omnivoice
+
+import "bytes"
+
+// AudioFormat describes a self-describing audio container.
+type AudioFormat struct {
+	// Name is the short format name (wav, mp3, ogg, flac).
+	Name string
+
+	// MimeType is the Content-Type to send with the audio.
+	MimeType string
+}
+
+// AudioSniffLen is the number of leading bytes DetectAudioFormat inspects.
+const AudioSniffLen = 12
+
+// DetectAudioFormat inspects the leading bytes of audio for a known container
+// signature. It reports false for headerless audio such as raw PCM, whose
+// encoding must come from the configuration instead.
+//
+// Only unambiguous magic numbers are recognized. MP3 is detected by its ID3
+// tag; a bare MPEG frame sync is not, since raw linear16 audio beginning
+// with a small negative sample has the same leading bytes.
+func DetectAudioFormat(header []byte) (AudioFormat, bool) {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return AudioFormat{Name: "wav", MimeType: "audio/wav"}, true
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioFormat{Name: "mp3", MimeType: "audio/mpeg"}, true
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return AudioFormat{Name: "ogg", MimeType: "audio/ogg"}, true
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return AudioFormat{Name: "flac", MimeType: "audio/flac"}, true
+	default:
+		return AudioFormat{}, false
+	}
+}
@@ -0,0 +1,57 @@
+package omnivoice
+
+import (
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestDetectAudioFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+		wantOK bool
+	}{
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), "audio/wav", true},
+		{"mp3 with ID3 tag", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), "audio/mpeg", true},
+		{"mp3 frame sync without tag", []byte{0xFF, 0xFB, 0x90, 0x64, 0x00}, "", false},
+		{"ogg", []byte("OggS\x00\x02\x00\x00"), "audio/ogg", true},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), "audio/flac", true},
+		{"raw pcm", []byte{0x00, 0x00, 0x12, 0x03, 0xF0, 0xFF, 0x40, 0x01}, "", false},
+		{"raw pcm with negative first sample", []byte{0xFF, 0xFF, 0xE2, 0xFF, 0x10, 0x00}, "", false},
+		{"riff without wave", []byte("RIFF\x24\x00\x00\x00AVI "), "", false},
+		{"empty", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := DetectAudioFormat(tt.header)
+			if ok != tt.wantOK || format.MimeType != tt.want {
+				t.Errorf("DetectAudioFormat() = %q, %v, want %q, %v", format.MimeType, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApplyAudioFormat(t *testing.T) {
+	config := stt.TranscriptionConfig{Encoding: "pcm", SampleRate: 16000, Channels: 1}
+
+	opts := ConfigToPreRecordedOptions(config)
+	if got := ApplyAudioFormat(opts, []byte("fLaC\x00\x00\x00\x22"), config); got != "audio/flac" {
+		t.Errorf("ApplyAudioFormat(flac) = %q, want audio/flac", got)
+	}
+	if opts.Encoding != "" || opts.SampleRate != 0 {
+		t.Errorf("container audio should not set encoding, got %q/%d", opts.Encoding, opts.SampleRate)
+	}
+
+	for _, raw := range [][]byte{{0x00, 0x01, 0x02, 0x03}, {0xFF, 0xFF, 0xE2, 0xFF}} {
+		opts = ConfigToPreRecordedOptions(config)
+		if got := ApplyAudioFormat(opts, raw, config); got != "" {
+			t.Errorf("ApplyAudioFormat(% x) = %q, want empty", raw, got)
+		}
+		if opts.Encoding != "linear16" || opts.SampleRate != 16000 || opts.Channels != 1 {
+			t.Errorf("raw audio % x options = %q/%d/%d, want linear16/16000/1", raw, opts.Encoding, opts.SampleRate, opts.Channels)
+		}
+	}
+}
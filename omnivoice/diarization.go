@@ -0,0 +1,65 @@
+package omnivoice
+
+import (
+	"strings"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// DiarizationGrouping selects how diarized transcripts are structured.
+type DiarizationGrouping string
+
+const (
+	// DiarizationPerWord tags each word with its speaker and leaves segment
+	// boundaries as Deepgram reports them. This is the default.
+	DiarizationPerWord DiarizationGrouping = "per_word"
+
+	// DiarizationPerTurn groups consecutive words from the same speaker into
+	// one segment per speaker turn, with the segment Speaker set.
+	DiarizationPerTurn DiarizationGrouping = "per_turn"
+)
+
+// DiarizationGroupingFor returns the grouping set with
+// ExtDiarizationGrouping in config, or DiarizationPerWord if it is unset
+// or not a string.
+func DiarizationGroupingFor(config stt.TranscriptionConfig) DiarizationGrouping {
+	if v := extensionString(config, ExtDiarizationGrouping); v != "" {
+		return DiarizationGrouping(v)
+	}
+	return DiarizationPerWord
+}
+
+// speakerTurns groups consecutive words with the same speaker into
+// segments. text returns the display form of word i, such as its
+// punctuated form. Segment confidence is the mean word confidence.
+func speakerTurns(words []stt.Word, text func(i int) string) []stt.Segment {
+	var (
+		turns []stt.Segment
+		parts []string
+		total float64
+	)
+	finish := func() {
+		turn := &turns[len(turns)-1]
+		turn.Text = strings.Join(parts, " ")
+		turn.Confidence = total / float64(len(turn.Words))
+		turn.EndTime = turn.Words[len(turn.Words)-1].EndTime
+	}
+
+	for i, w := range words {
+		if len(turns) == 0 || turns[len(turns)-1].Speaker != w.Speaker {
+			if len(turns) > 0 {
+				finish()
+			}
+			turns = append(turns, stt.Segment{Speaker: w.Speaker, StartTime: w.StartTime})
+			parts, total = nil, 0
+		}
+		turn := &turns[len(turns)-1]
+		turn.Words = append(turn.Words, w)
+		parts = append(parts, text(i))
+		total += w.Confidence
+	}
+	if len(turns) > 0 {
+		finish()
+	}
+	return turns
+}
@@ -0,0 +1,136 @@
+package omnivoice
+
+import (
+	"encoding/json"
+	"testing"
+
+	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// diarizedFixture is a two-speaker conversation with three speaker turns,
+// reported by Deepgram as a single utterance.
+const diarizedFixture = `{
+	"metadata": {"duration": 3.0},
+	"results": {
+		"channels": [{"alternatives": [{
+			"transcript": "hi there hello bob bye",
+			"confidence": 0.9,
+			"words": [
+				{"word": "hi", "punctuated_word": "Hi", "start": 0.0, "end": 0.3, "confidence": 0.9, "speaker": 0},
+				{"word": "there", "punctuated_word": "there.", "start": 0.3, "end": 0.6, "confidence": 0.7, "speaker": 0},
+				{"word": "hello", "punctuated_word": "Hello,", "start": 1.0, "end": 1.3, "confidence": 0.8, "speaker": 1},
+				{"word": "bob", "punctuated_word": "Bob.", "start": 1.3, "end": 1.6, "confidence": 1.0, "speaker": 1},
+				{"word": "bye", "punctuated_word": "Bye.", "start": 2.0, "end": 2.4, "confidence": 0.6, "speaker": 0}
+			]
+		}]}],
+		"utterances": [{
+			"start": 0.0, "end": 2.4, "confidence": 0.9, "transcript": "Hi there. Hello, Bob. Bye.", "speaker": 0,
+			"words": [
+				{"word": "hi", "start": 0.0, "end": 0.3, "confidence": 0.9, "speaker": 0},
+				{"word": "there", "start": 0.3, "end": 0.6, "confidence": 0.7, "speaker": 0},
+				{"word": "hello", "start": 1.0, "end": 1.3, "confidence": 0.8, "speaker": 1},
+				{"word": "bob", "start": 1.3, "end": 1.6, "confidence": 1.0, "speaker": 1},
+				{"word": "bye", "start": 2.0, "end": 2.4, "confidence": 0.6, "speaker": 0}
+			]
+		}]
+	}
+}`
+
+var wantTurns = []struct {
+	speaker, text string
+	words         int
+}{
+	{"speaker_0", "Hi there.", 2},
+	{"speaker_1", "Hello, Bob.", 2},
+	{"speaker_0", "Bye.", 1},
+}
+
+func checkTurns(t *testing.T, turns []stt.Segment) {
+	t.Helper()
+	if len(turns) != len(wantTurns) {
+		t.Fatalf("got %d turns, want %d: %+v", len(turns), len(wantTurns), turns)
+	}
+	for i, want := range wantTurns {
+		got := turns[i]
+		if got.Speaker != want.speaker || got.Text != want.text || len(got.Words) != want.words {
+			t.Errorf("turn %d = %s %q (%d words), want %s %q (%d words)",
+				i, got.Speaker, got.Text, len(got.Words), want.speaker, want.text, want.words)
+		}
+	}
+	if turns[1].StartTime != turns[1].Words[0].StartTime || turns[1].EndTime != turns[1].Words[1].EndTime {
+		t.Errorf("turn 1 timing = %v-%v, want the span of its words", turns[1].StartTime, turns[1].EndTime)
+	}
+	if turns[1].Confidence != 0.9 {
+		t.Errorf("turn 1 confidence = %v, want 0.9", turns[1].Confidence)
+	}
+}
+
+func TestDiarizationGrouping_Batch(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(diarizedFixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+
+	t.Run("per word", func(t *testing.T) {
+		result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{})
+		if len(result.Segments) != 1 {
+			t.Fatalf("got %d segments, want the 1 utterance", len(result.Segments))
+		}
+		words := result.Segments[0].Words
+		if len(words) != 5 || words[0].Speaker != "speaker_0" || words[2].Speaker != "speaker_1" {
+			t.Errorf("words = %+v, want per-word speakers", words)
+		}
+	})
+
+	t.Run("per turn", func(t *testing.T) {
+		result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{DiarizationGrouping: DiarizationPerTurn})
+		checkTurns(t, result.Segments)
+		if result.Text != "hi there hello bob bye" {
+			t.Errorf("Text = %q, want the full transcript", result.Text)
+		}
+	})
+}
+
+func TestDiarizationGrouping_Stream(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(diarizedFixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	msg := &MessageResponse{
+		IsFinal: true,
+		Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "hi there hello bob bye",
+			Words:      restWords(resp.Results.Channels[0].Alternatives[0].Words),
+		}}},
+	}
+
+	t.Run("per word", func(t *testing.T) {
+		event := MessageResponseToEvent(msg, ConvertOptions{})
+		if event.Turns != nil || event.Segment.Speaker != "" {
+			t.Errorf("Turns = %v, Segment.Speaker = %q, want neither", event.Turns, event.Segment.Speaker)
+		}
+		if event.Segment.Words[2].Speaker != "speaker_1" {
+			t.Errorf("word 2 speaker = %q, want speaker_1", event.Segment.Words[2].Speaker)
+		}
+	})
+
+	t.Run("per turn", func(t *testing.T) {
+		event := MessageResponseToEvent(msg, ConvertOptions{DiarizationGrouping: DiarizationPerTurn})
+		checkTurns(t, event.Turns)
+		if event.Segment.Speaker != "" {
+			t.Errorf("Segment.Speaker = %q, want empty for several speakers", event.Segment.Speaker)
+		}
+	})
+
+	t.Run("per turn single speaker", func(t *testing.T) {
+		single := &MessageResponse{Channel: Channel{Alternatives: []Alternative{{
+			Transcript: "hi there",
+			Words:      msg.Channel.Alternatives[0].Words[:2],
+		}}}}
+		event := MessageResponseToEvent(single, ConvertOptions{DiarizationGrouping: DiarizationPerTurn})
+		if len(event.Turns) != 1 || event.Segment.Speaker != "speaker_0" {
+			t.Errorf("Turns = %+v, Segment.Speaker = %q, want one turn by speaker_0", event.Turns, event.Segment.Speaker)
+		}
+	})
+}
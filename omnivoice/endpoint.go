@@ -0,0 +1,81 @@
+package omnivoice
+
+import (
+	"fmt"
+	"net/url"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// Endpoint holds the hosts of a Deepgram deployment in the form the SDK's
+// ClientOptions.Host expects. REST requests and WebSocket streaming need
+// separate hosts, since the SDK only accepts an http or https scheme for
+// the former and a ws or wss scheme for the latter. The zero Endpoint
+// selects Deepgram's hosted API.
+type Endpoint struct {
+	// REST is the host for REST requests, such as "https://dg.internal".
+	REST string
+
+	// WS is the host for WebSocket streaming, such as "wss://dg.internal".
+	WS string
+}
+
+// streamSchemes maps each accepted base URL scheme to the REST and
+// WebSocket schemes of the same security.
+var streamSchemes = map[string][2]string{
+	"http":  {"http", "ws"},
+	"https": {"https", "wss"},
+	"ws":    {"http", "ws"},
+	"wss":   {"https", "wss"},
+}
+
+// ParseBaseURL returns the Endpoint of the Deepgram deployment at rawURL,
+// such as "https://deepgram.internal:8443" for a self-hosted server. The
+// scheme must be http, https, ws, or wss; secure and plain schemes are
+// mapped to their REST and WebSocket counterparts. rawURL must name a host
+// and may not have a path other than "/", a query, or credentials, which
+// the SDK would drop.
+func ParseBaseURL(rawURL string) (Endpoint, error) {
+	if rawURL == "" {
+		return Endpoint{}, fmt.Errorf("base URL must not be empty")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid base URL: %w", err)
+	}
+	schemes, ok := streamSchemes[u.Scheme]
+	switch {
+	case !ok:
+		return Endpoint{}, fmt.Errorf("invalid base URL %q: scheme must be http, https, ws, or wss", rawURL)
+	case u.Host == "":
+		return Endpoint{}, fmt.Errorf("invalid base URL %q: missing host", rawURL)
+	case u.Path != "" && u.Path != "/", u.RawQuery != "", u.Fragment != "", u.User != nil:
+		return Endpoint{}, fmt.Errorf("invalid base URL %q: only a scheme, host, and port are allowed", rawURL)
+	}
+
+	return Endpoint{
+		REST: schemes[0] + "://" + u.Host,
+		WS:   schemes[1] + "://" + u.Host,
+	}, nil
+}
+
+// RESTOptions returns SDK client options for REST requests to e.
+func (e Endpoint) RESTOptions() *interfaces.ClientOptions {
+	return &interfaces.ClientOptions{Host: e.REST}
+}
+
+// WSOptions returns SDK client options for WebSocket streaming to e.
+func (e Endpoint) WSOptions() *interfaces.ClientOptions {
+	return &interfaces.ClientOptions{Host: e.WS}
+}
+
+// StreamHost returns the WebSocket host to use alongside restHost, the
+// host of parsed RESTOptions: e.WS, unless the SDK replaced e.REST with
+// the DEEPGRAM_HOST environment variable, which it reads when parsing.
+func (e Endpoint) StreamHost(restHost string) string {
+	if e.REST != "" && restHost == e.REST {
+		return e.WS
+	}
+	return restHost
+}
@@ -0,0 +1,281 @@
+package omnivoice
+
+import (
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+// Deepgram-specific TranscriptionConfig.Extensions keys.
+const (
+	// ExtNumerals converts spoken numbers to digits, e.g. "nine hundred"
+	// to "900". The value is a bool.
+	ExtNumerals = "deepgram.numerals"
+
+	// ExtMeasurements abbreviates spoken units of measure, e.g.
+	// "five milliliters" to "5 mL". The value is a bool. Measurements
+	// implies ExtNumerals, since abbreviated units are only written after
+	// digits; setting ExtNumerals to false does not disable it. Deepgram
+	// supports measurements only for pre-recorded audio, so streaming
+	// requests enable numerals alone.
+	ExtMeasurements = "deepgram.measurements"
+
+	// ExtFormatLocale is a BCP-47 locale, such as "en-GB", whose date order
+	// is applied to the dates in transcripts; see FormatDates. The value is
+	// a string.
+	ExtFormatLocale = "deepgram.format_locale"
+
+	// ExtDiarizationGrouping selects how diarized words are grouped into
+	// segments; see DiarizationGrouping. The value is a string,
+	// DiarizationPerWord (the default) or DiarizationPerTurn.
+	ExtDiarizationGrouping = "deepgram.diarization_grouping"
+
+	// ExtSmartFormat controls Deepgram's smart formatting, which writes
+	// numbers, dates, currency, and similar entities in their conventional
+	// form. The value is a bool; smart formatting is on unless it is false,
+	// so set it to false for the raw transcript.
+	ExtSmartFormat = "deepgram.smart_format"
+
+	// ExtDictation converts spoken punctuation commands, such as "comma"
+	// and "new paragraph", to the marks they name, for dictated text such
+	// as medical notes. The value is a bool and defaults to false.
+	// Deepgram applies it only with punctuation, so it requires
+	// EnablePunctuation. The SDK's options have no field for it, so it is
+	// sent as a custom query parameter; see ListenContext.
+	ExtDictation = "deepgram.dictation"
+
+	// ExtProfanityFilter masks profanity in transcripts with asterisks,
+	// keeping each word's first letter. The value is a bool and defaults
+	// to false.
+	ExtProfanityFilter = "deepgram.profanity_filter"
+
+	// ExtSearch lists terms to find in batch audio, reported with their
+	// timings in TranscriptionResult.Searches. The value is a []string.
+	ExtSearch = "deepgram.search"
+
+	// ExtReplace lists replacements applied to batch transcripts, each
+	// "term:replacement", such as "acme:ACME". A term without a
+	// replacement is removed. The value is a []string.
+	ExtReplace = "deepgram.replace"
+
+	// ExtSummarize asks Deepgram to summarize batch transcripts, reported
+	// in TranscriptionResult.Summary. The value is true for the current
+	// summarization version, DefaultSummarizeVersion, or a version string
+	// such as "v2".
+	ExtSummarize = "deepgram.summarize"
+
+	// ExtTopics asks Deepgram to detect the topics of batch transcripts,
+	// reported in TranscriptionResult.Topics. The value is a bool.
+	ExtTopics = "deepgram.topics"
+
+	// ExtIntents asks Deepgram to recognize speaker intents in batch
+	// transcripts, reported in TranscriptionResult.Intents. The value is a
+	// bool.
+	ExtIntents = "deepgram.intents"
+
+	// ExtSentiment asks Deepgram to analyze the sentiment of batch
+	// transcripts, reported in TranscriptionResult.Sentiments and
+	// OverallSentiment. The value is a bool.
+	ExtSentiment = "deepgram.sentiment"
+
+	// ExtEntities asks Deepgram to detect named entities in batch
+	// transcripts, reported in TranscriptionResult.Entities. The value is
+	// a bool.
+	ExtEntities = "deepgram.entities"
+
+	// ExtAlternatives is the number of transcripts Deepgram returns for
+	// the same audio, reported best first in the Alternatives of batch
+	// results and stream events. The value is an int; 1, the default,
+	// returns only the best transcript.
+	ExtAlternatives = "deepgram.alternatives"
+
+	// ExtDetectLanguage asks Deepgram to detect the dominant language of
+	// batch audio instead of assuming en-US, reported with its confidence
+	// in the Language and LanguageConfidence of the result. The value is
+	// a bool. It is ignored when Language is set.
+	//
+	// Deepgram does not detect the language of streamed audio. For
+	// multilingual streams, set Language to "multi" with a model that
+	// supports it; each word then reports its language in
+	// WordInfo.Language.
+	ExtDetectLanguage = "deepgram.detect_language"
+
+	// ExtParagraphs asks Deepgram to group batch transcripts into
+	// paragraphs, reported in TranscriptionResult.Paragraphs. The value is
+	// a bool. Smart formatting also returns paragraphs.
+	ExtParagraphs = "deepgram.paragraphs"
+
+	// ExtInterimResults controls whether streams deliver interim results
+	// as well as finals. The value is a bool; interim results are on
+	// unless it is false. Deepgram only detects utterance ends with interim
+	// results on, so turning them off also turns off EventSpeechEnd events
+	// from utterance ends.
+	ExtInterimResults = "deepgram.interim_results"
+
+	// ExtUtteranceEnd is the silence after the last finalized word that
+	// ends an utterance in a stream, emitting an EventSpeechEnd event. The
+	// value is an int number of milliseconds or a time.Duration, rounded
+	// down to whole milliseconds; it defaults to DefaultUtteranceEnd and
+	// requires interim results.
+	ExtUtteranceEnd = "deepgram.utterance_end"
+
+	// ExtRedact redacts sensitive information from transcripts, replacing
+	// it with a placeholder such as "[PCI]". The value is a []string of
+	// categories, such as "pci", "numbers", or "ssn"; see RedactCategories
+	// and Redaction. Batch and streaming requests alike are redacted.
+	ExtRedact = "deepgram.redact"
+)
+
+// DefaultSummarizeVersion is the summarization version requested when
+// ExtSummarize is true.
+const DefaultSummarizeVersion = "v2"
+
+// DefaultUtteranceEnd is the utterance end silence used when
+// ExtUtteranceEnd is not set.
+const DefaultUtteranceEnd = time.Second
+
+// Deepgram-specific SynthesisConfig.Extensions keys.
+const (
+	// ExtOutputSampleRate resamples streamed linear16 audio to this rate
+	// in Hz, for playback devices fixed at a rate the voice was not
+	// produced at. The value is an int. Deepgram still synthesizes at
+	// SampleRate (24000 Hz by default); see Resampler for the quality
+	// implications.
+	ExtOutputSampleRate = "deepgram.output_sample_rate"
+
+	// ExtExtra tags synthesis requests with key-value metadata for usage
+	// reporting, sent to Deepgram as extra=key:value parameters. The value
+	// is a map[string]string; nothing is sent when it is empty.
+	ExtExtra = "deepgram.extra"
+
+	// ExtLanguage is the BCP-47 language, such as "en-GB", of text to be
+	// synthesized. SynthesisConfig has no language field; when neither
+	// Model nor VoiceID is set, the voice is the LanguageVoices default for
+	// this language. The value is a string.
+	//
+	// The language is not sent to Deepgram: the speak API has no language
+	// parameter, and each Aura voice speaks the language in its model name,
+	// such as the "-en" of "aura-2-thalia-en". To speak another language,
+	// select a voice for it.
+	ExtLanguage = "deepgram.language"
+
+	// ExtBitRate sets the bit rate, in bits per second, of lossy output:
+	// 32000 or 48000 for mp3, 4000 to 650000 for opus, and 4000 to 192000
+	// for aac. The value is an int. It is ignored for other encodings,
+	// whose bit rate follows from their sample rate.
+	ExtBitRate = "deepgram.bit_rate"
+)
+
+// extensionBool returns the bool value of the extension key in config, or
+// false if it is unset or not a bool.
+func extensionBool(config stt.TranscriptionConfig, key string) bool {
+	v, _ := config.Extensions[key].(bool)
+	return v
+}
+
+// extensionString returns the string value of the extension key in config,
+// or "" if it is unset or not a string.
+func extensionString(config stt.TranscriptionConfig, key string) string {
+	v, _ := config.Extensions[key].(string)
+	return v
+}
+
+// FormatLocale returns the locale set with ExtFormatLocale in config.
+func FormatLocale(config stt.TranscriptionConfig) string {
+	return extensionString(config, ExtFormatLocale)
+}
+
+// numberFormatting returns the numerals and measurements settings for
+// config, applying the precedence documented on ExtMeasurements.
+func numberFormatting(config stt.TranscriptionConfig) (numerals, measurements bool) {
+	measurements = extensionBool(config, ExtMeasurements)
+	numerals = measurements || extensionBool(config, ExtNumerals)
+	return numerals, measurements
+}
+
+// smartFormat reports whether smart formatting is enabled for config, as
+// documented on ExtSmartFormat.
+func smartFormat(config stt.TranscriptionConfig) bool {
+	v, ok := config.Extensions[ExtSmartFormat].(bool)
+	return v || !ok
+}
+
+// interimResults reports whether interim results are enabled for config,
+// as documented on ExtInterimResults.
+func interimResults(config stt.TranscriptionConfig) bool {
+	v, ok := config.Extensions[ExtInterimResults].(bool)
+	return v || !ok
+}
+
+// utteranceEnd returns the utterance end silence set with ExtUtteranceEnd
+// in config, reporting false if it is unset or of another type.
+func utteranceEnd(config stt.TranscriptionConfig) (time.Duration, bool) {
+	switch v := config.Extensions[ExtUtteranceEnd].(type) {
+	case int:
+		return time.Duration(v) * time.Millisecond, true
+	case time.Duration:
+		return v, true
+	}
+	return 0, false
+}
+
+// extensionStrings returns the []string value of the extension key in
+// config, or nil if it is unset or not a []string.
+func extensionStrings(config stt.TranscriptionConfig, key string) []string {
+	v, _ := config.Extensions[key].([]string)
+	return v
+}
+
+// summarizeVersion returns the summarization version requested with
+// ExtSummarize in config, or "" if none is.
+func summarizeVersion(config stt.TranscriptionConfig) string {
+	switch v := config.Extensions[ExtSummarize].(type) {
+	case bool:
+		if v {
+			return DefaultSummarizeVersion
+		}
+	case string:
+		return v
+	}
+	return ""
+}
+
+// alternatives returns the number of alternatives set with ExtAlternatives
+// in config, or 0 to leave Deepgram's default of one.
+func alternatives(config stt.TranscriptionConfig) int {
+	if n, _ := config.Extensions[ExtAlternatives].(int); n > 1 {
+		return n
+	}
+	return 0
+}
+
+// detectLanguage reports whether config asks for language detection, as
+// documented on ExtDetectLanguage.
+func detectLanguage(config stt.TranscriptionConfig) bool {
+	return config.Language == "" && extensionBool(config, ExtDetectLanguage)
+}
+
+// OutputSampleRate returns the rate set with ExtOutputSampleRate in config,
+// or 0 if it is unset or not an int.
+func OutputSampleRate(config tts.SynthesisConfig) int {
+	v, _ := config.Extensions[ExtOutputSampleRate].(int)
+	return v
+}
+
+// BitRate returns the bit rate set with ExtBitRate in config, or 0 if it is
+// unset, not an int, or the output encoding has no configurable bit rate.
+func BitRate(config tts.SynthesisConfig) int {
+	if _, ok := ttsBitRates[mapTTSEncoding(config.OutputFormat)]; !ok {
+		return 0
+	}
+	v, _ := config.Extensions[ExtBitRate].(int)
+	return v
+}
+
+// SynthesisLanguage returns the language set with ExtLanguage in config,
+// or "" if it is unset or not a string.
+func SynthesisLanguage(config tts.SynthesisConfig) string {
+	v, _ := config.Extensions[ExtLanguage].(string)
+	return v
+}
@@ -0,0 +1,118 @@
+package omnivoice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestNumberFormatting(t *testing.T) {
+	tests := []struct {
+		name             string
+		numerals         any
+		measurements     any
+		wantNumerals     bool
+		wantMeasurements bool
+	}{
+		{"neither", nil, nil, false, false},
+		{"numerals only", true, nil, true, false},
+		{"measurements only", nil, true, true, true},
+		{"both", true, true, true, true},
+		{"measurements overrides numerals off", false, true, true, true},
+		{"numerals on, measurements off", true, false, true, false},
+		{"non-bool ignored", "yes", 1, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := map[string]any{}
+			if tt.numerals != nil {
+				ext[ExtNumerals] = tt.numerals
+			}
+			if tt.measurements != nil {
+				ext[ExtMeasurements] = tt.measurements
+			}
+			config := stt.TranscriptionConfig{Extensions: ext}
+
+			pre := ConfigToPreRecordedOptions(config)
+			if pre.Numerals != tt.wantNumerals || pre.Measurements != tt.wantMeasurements {
+				t.Errorf("prerecorded numerals=%v measurements=%v, want %v/%v",
+					pre.Numerals, pre.Measurements, tt.wantNumerals, tt.wantMeasurements)
+			}
+			if live := ConfigToLiveTranscriptionOptions(config); live.Numerals != tt.wantNumerals {
+				t.Errorf("live numerals=%v, want %v", live.Numerals, tt.wantNumerals)
+			}
+		})
+	}
+}
+
+func TestLiveTurnOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		ext         map[string]any
+		wantInterim bool
+		wantEndMs   string
+	}{
+		{"defaults", nil, true, "1000"},
+		{"interim off", map[string]any{ExtInterimResults: false}, false, ""},
+		{"utterance end in ms", map[string]any{ExtUtteranceEnd: 1500}, true, "1500"},
+		{"utterance end as duration", map[string]any{ExtUtteranceEnd: 2 * time.Second}, true, "2000"},
+		{"non-bool interim ignored", map[string]any{ExtInterimResults: "no"}, true, "1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ConfigToLiveTranscriptionOptions(stt.TranscriptionConfig{Extensions: tt.ext})
+			if opts.InterimResults != tt.wantInterim {
+				t.Errorf("InterimResults = %v, want %v", opts.InterimResults, tt.wantInterim)
+			}
+			if opts.UtteranceEndMs != tt.wantEndMs {
+				t.Errorf("UtteranceEndMs = %q, want %q", opts.UtteranceEndMs, tt.wantEndMs)
+			}
+		})
+	}
+}
+
+func TestProfanityFilter(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		config := stt.TranscriptionConfig{Extensions: map[string]any{ExtProfanityFilter: enabled}}
+		if got := ConfigToPreRecordedOptions(config).ProfanityFilter; got != enabled {
+			t.Errorf("prerecorded ProfanityFilter = %v, want %v", got, enabled)
+		}
+		if got := ConfigToLiveTranscriptionOptions(config).ProfanityFilter; got != enabled {
+			t.Errorf("live ProfanityFilter = %v, want %v", got, enabled)
+		}
+	}
+
+	if ConfigToPreRecordedOptions(stt.TranscriptionConfig{}).ProfanityFilter {
+		t.Error("ProfanityFilter enabled by default")
+	}
+}
+
+func TestSmartFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{"default", nil, true},
+		{"enabled", true, true},
+		{"disabled", false, false},
+		{"non-bool ignored", "no", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := map[string]any{}
+			if tt.value != nil {
+				ext[ExtSmartFormat] = tt.value
+			}
+			config := stt.TranscriptionConfig{Extensions: ext}
+
+			if pre := ConfigToPreRecordedOptions(config); pre.SmartFormat != tt.want {
+				t.Errorf("prerecorded SmartFormat = %v, want %v", pre.SmartFormat, tt.want)
+			}
+			if live := ConfigToLiveTranscriptionOptions(config); live.SmartFormat != tt.want {
+				t.Errorf("live SmartFormat = %v, want %v", live.SmartFormat, tt.want)
+			}
+		})
+	}
+}
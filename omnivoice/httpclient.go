@@ -0,0 +1,20 @@
+package omnivoice
+
+import (
+	"net/http"
+
+	restv1 "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/rest/v1"
+)
+
+// UseHTTPClient makes the Deepgram SDK REST client c send its requests
+// with hc, so that every client given the same hc shares its transport and
+// connection pool. A nil hc leaves c with its default client.
+//
+// hc is safe to share between the STT and TTS providers, as http.Client is
+// safe for concurrent use, but must not be modified once in use.
+func UseHTTPClient(c *restv1.HTTPClient, hc *http.Client) {
+	if c == nil || hc == nil {
+		return
+	}
+	c.Client = *hc
+}
@@ -0,0 +1,103 @@
+package omnivoice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// MaxKeywords is the most keywords sent with one transcription request.
+// Deepgram only accepts keywords as query parameters, so a long list can
+// push the request URL past what Deepgram and proxies accept, and boosting
+// many terms at once weakens the effect of each. Longer lists are truncated
+// to their first MaxKeywords entries, after duplicates are removed; the
+// truncation is logged at klog verbosity 1 and above.
+const MaxKeywords = 100
+
+// LoadKeywords reads keywords from r, one per line, for use as
+// TranscriptionConfig.Keywords. Surrounding whitespace is trimmed, and
+// blank lines and lines starting with # are skipped. A line may carry an
+// intensifier, as in "omnivoice:2".
+func LoadKeywords(r io.Reader) ([]string, error) {
+	var keywords []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keywords = append(keywords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keywords: %w", err)
+	}
+	return keywords, nil
+}
+
+// LoadKeywordsFile reads keywords from the file at path; see LoadKeywords.
+func LoadKeywordsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keywords file: %w", err)
+	}
+	defer f.Close()
+
+	return LoadKeywords(f)
+}
+
+// DedupeKeywords returns keywords with surrounding whitespace trimmed,
+// empty entries dropped, and duplicates removed. Keywords are compared by
+// their term, ignoring case and any intensifier, and the first occurrence
+// is kept, so "Deepgram:2" and "deepgram" count as one. Order is preserved.
+func DedupeKeywords(keywords []string) []string {
+	seen := make(map[string]bool, len(keywords))
+	out := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		term := strings.ToLower(keywordTerm(keyword))
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		out = append(out, keyword)
+	}
+	return out
+}
+
+// keywordTerm returns keyword without a trailing ":<intensifier>".
+func keywordTerm(keyword string) string {
+	i := strings.LastIndexByte(keyword, ':')
+	if i < 0 {
+		return keyword
+	}
+	if _, err := strconv.ParseFloat(keyword[i+1:], 64); err != nil {
+		return keyword
+	}
+	return strings.TrimSpace(keyword[:i])
+}
+
+// prepareKeywords deduplicates keywords and truncates them to MaxKeywords,
+// logging when entries are dropped. It returns nil for an empty
+// list so the parameter is omitted.
+func prepareKeywords(keywords []string) []string {
+	if len(keywords) == 0 {
+		return nil
+	}
+	keywords = DedupeKeywords(keywords)
+	if len(keywords) > MaxKeywords {
+		klog.V(1).Infof("deepgram: %d keywords exceed the limit of %d; sending the first %d", len(keywords), MaxKeywords, MaxKeywords)
+		keywords = keywords[:MaxKeywords]
+	}
+	if len(keywords) == 0 {
+		return nil
+	}
+	return keywords
+}
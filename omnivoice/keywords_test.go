@@ -0,0 +1,99 @@
+package omnivoice
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestLoadKeywords(t *testing.T) {
+	input := `# product names
+OmniVoice
+  Deepgram:2
+
+# people
+Ada Lovelace
+`
+	got, err := LoadKeywords(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadKeywords() error = %v", err)
+	}
+	want := []string{"OmniVoice", "Deepgram:2", "Ada Lovelace"}
+	if !slices.Equal(got, want) {
+		t.Errorf("LoadKeywords() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadKeywordsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keywords.txt")
+	if err := os.WriteFile(path, []byte("alpha\nbeta\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := LoadKeywordsFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeywordsFile() error = %v", err)
+	}
+	if want := []string{"alpha", "beta"}; !slices.Equal(got, want) {
+		t.Errorf("LoadKeywordsFile() = %q, want %q", got, want)
+	}
+
+	if _, err := LoadKeywordsFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadKeywordsFile() of a missing file succeeded")
+	}
+}
+
+func TestDedupeKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords []string
+		want     []string
+	}{
+		{"empty", nil, []string{}},
+		{"no duplicates", []string{"alpha", "beta"}, []string{"alpha", "beta"}},
+		{"exact duplicates", []string{"alpha", "beta", "alpha"}, []string{"alpha", "beta"}},
+		{"case", []string{"Deepgram", "deepgram", "DEEPGRAM"}, []string{"Deepgram"}},
+		{"intensifier ignored", []string{"deepgram", "Deepgram:2", "deepgram:-1.5"}, []string{"deepgram"}},
+		{"first intensifier kept", []string{"deepgram:2", "deepgram"}, []string{"deepgram:2"}},
+		{"colon in term", []string{"re:Invent", "re:invent:2"}, []string{"re:Invent"}},
+		{"whitespace and empties", []string{" alpha ", "", "  ", "alpha"}, []string{"alpha"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DedupeKeywords(tt.keywords); !slices.Equal(got, tt.want) {
+				t.Errorf("DedupeKeywords(%q) = %q, want %q", tt.keywords, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigToOptions_LargeKeywordList(t *testing.T) {
+	// Duplicates do not count toward the limit
+	var keywords []string
+	for i := range MaxKeywords + 50 {
+		keywords = append(keywords, fmt.Sprintf("term%d", i), fmt.Sprintf("TERM%d:2", i))
+	}
+	config := stt.TranscriptionConfig{Keywords: keywords}
+
+	for name, got := range map[string][]string{
+		"pre-recorded": ConfigToPreRecordedOptions(config).Keywords,
+		"live":         ConfigToLiveTranscriptionOptions(config).Keywords,
+	} {
+		if len(got) != MaxKeywords {
+			t.Errorf("%s: sent %d keywords, want %d", name, len(got), MaxKeywords)
+			continue
+		}
+		if got[0] != "term0" || got[MaxKeywords-1] != fmt.Sprintf("term%d", MaxKeywords-1) {
+			t.Errorf("%s: sent %q ... %q, want the first %d unique terms", name, got[0], got[MaxKeywords-1], MaxKeywords)
+		}
+	}
+
+	if got := ConfigToPreRecordedOptions(stt.TranscriptionConfig{}).Keywords; got != nil {
+		t.Errorf("Keywords = %q without keywords, want nil", got)
+	}
+}
@@ -0,0 +1,38 @@
+package omnivoice
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// ModelChain returns the models to try in order: model followed by the
+// fallbacks, skipping empty entries and duplicates.
+func ModelChain(model string, fallbacks []string) []string {
+	chain := make([]string, 0, 1+len(fallbacks))
+	seen := make(map[string]bool, 1+len(fallbacks))
+	for _, m := range append([]string{model}, fallbacks...) {
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		chain = append(chain, m)
+	}
+	if len(chain) == 0 {
+		chain = append(chain, model)
+	}
+	return chain
+}
+
+// IsModelError reports whether err is a Deepgram 400 response rejecting the
+// requested model, such as an unknown or unavailable model name.
+func IsModelError(err error) bool {
+	var se *interfaces.StatusError
+	if !errors.As(err, &se) || se.Resp == nil || se.Resp.StatusCode != http.StatusBadRequest || se.DeepgramError == nil {
+		return false
+	}
+	msg := strings.ToLower(se.DeepgramError.ErrMsg + " " + se.DeepgramError.Description)
+	return strings.Contains(msg, "model")
+}
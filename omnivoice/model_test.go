@@ -0,0 +1,59 @@
+package omnivoice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+func TestModelChain(t *testing.T) {
+	tests := []struct {
+		model     string
+		fallbacks []string
+		want      []string
+	}{
+		{"nova-3", nil, []string{"nova-3"}},
+		{"nova-3", []string{"nova-2"}, []string{"nova-3", "nova-2"}},
+		{"nova-3", []string{"nova-3", "", "nova-2", "nova-2"}, []string{"nova-3", "nova-2"}},
+		{"", []string{"nova-2"}, []string{"nova-2"}},
+		{"", nil, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := ModelChain(tt.model, tt.fallbacks); !slices.Equal(got, tt.want) {
+			t.Errorf("ModelChain(%q, %q) = %q, want %q", tt.model, tt.fallbacks, got, tt.want)
+		}
+	}
+}
+
+func TestIsModelError(t *testing.T) {
+	statusError := func(code int, msg string) error {
+		return &interfaces.StatusError{
+			Resp:          &http.Response{StatusCode: code},
+			DeepgramError: &interfaces.DeepgramError{ErrMsg: msg},
+		}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"model not found", statusError(http.StatusBadRequest, "No such model/language/tier combination found."), true},
+		{"wrapped", fmt.Errorf("failed: %w", statusError(http.StatusBadRequest, "Failed to resolve model")), true},
+		{"other bad request", statusError(http.StatusBadRequest, "corrupt audio"), false},
+		{"unauthorized", statusError(http.StatusUnauthorized, "Invalid credentials for model"), false},
+		{"network", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsModelError(tt.err); got != tt.want {
+				t.Errorf("IsModelError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
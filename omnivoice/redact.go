@@ -0,0 +1,33 @@
+package omnivoice
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	klog "k8s.io/klog/v2"
+)
+
+// RedactCategories lists the redaction categories Deepgram documents for
+// ExtRedact. Categories outside this list are still sent, so newer ones
+// can be used before they are added here.
+var RedactCategories = []string{"pci", "pii", "phi", "numbers", "ssn"}
+
+// Redaction returns the redaction categories set with ExtRedact in config,
+// lower-cased and deduplicated in order, or nil if none are set. Unknown
+// categories are kept and logged.
+func Redaction(config stt.TranscriptionConfig) []string {
+	categories, _ := config.Extensions[ExtRedact].([]string)
+	var out []string
+	for _, c := range categories {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" || slices.Contains(out, c) {
+			continue
+		}
+		if !slices.Contains(RedactCategories, c) {
+			klog.V(1).Infof("deepgram: sending unknown redaction category %q", c)
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package omnivoice
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestRedaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{"unset", nil, nil},
+		{"known", []string{"pci", "ssn"}, []string{"pci", "ssn"}},
+		{"normalized", []string{" PCI", "pci", "Numbers"}, []string{"pci", "numbers"}},
+		{"unknown passed through", []string{"phi", "passport"}, []string{"phi", "passport"}},
+		{"wrong type ignored", "pci", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := map[string]any{}
+			if tt.value != nil {
+				ext[ExtRedact] = tt.value
+			}
+			config := stt.TranscriptionConfig{Extensions: ext}
+
+			if got := ConfigToPreRecordedOptions(config).Redact; !slices.Equal(got, tt.want) {
+				t.Errorf("prerecorded Redact = %q, want %q", got, tt.want)
+			}
+			if got := ConfigToLiveTranscriptionOptions(config).Redact; !slices.Equal(got, tt.want) {
+				t.Errorf("live Redact = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
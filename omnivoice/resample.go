@@ -0,0 +1,42 @@
+package omnivoice
+
+import "github.com/plexusone/omnivoice-core/audio/codec"
+
+// Resampler converts a stream of linear16 chunks from one sample rate to
+// another with codec.ResampleBytes.
+//
+// Resampling uses linear interpolation without an anti-aliasing filter, and
+// each chunk is resampled independently. Downsampling can therefore fold
+// high frequencies back into the audible range, which is most noticeable as
+// a slight harshness on sibilants, and chunk boundaries may introduce faint
+// clicks. For the best quality, request the playback rate from Deepgram with
+// SampleRate where the voice supports it and resample only when it does not.
+type Resampler struct {
+	from, to codec.SampleRate
+
+	// pending holds the odd trailing byte of the previous chunk
+	pending []byte
+}
+
+// NewResampler returns a Resampler from fromRate to toRate in Hz.
+func NewResampler(fromRate, toRate int) *Resampler {
+	return &Resampler{from: codec.SampleRate(fromRate), to: codec.SampleRate(toRate)}
+}
+
+// Resample returns chunk at the target rate. A trailing byte that does not
+// complete a sample is held back and prepended to the next chunk.
+func (r *Resampler) Resample(chunk []byte) []byte {
+	data := chunk
+	if len(r.pending) > 0 {
+		data = append(r.pending, chunk...)
+		r.pending = nil
+	}
+	if len(data)%2 != 0 {
+		r.pending = []byte{data[len(data)-1]}
+		data = data[:len(data)-1]
+	}
+	if r.from == r.to || len(data) == 0 {
+		return data
+	}
+	return codec.ResampleBytes(data, r.from, r.to)
+}
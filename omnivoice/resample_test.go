@@ -0,0 +1,28 @@
+package omnivoice
+
+import "testing"
+
+func TestResampler(t *testing.T) {
+	r := NewResampler(24000, 16000)
+
+	// 100ms at 24 kHz becomes 100ms at 16 kHz
+	if got := len(r.Resample(make([]byte, 4800))); got != 3200 {
+		t.Errorf("Resample(4800 bytes) = %d bytes, want 3200", got)
+	}
+
+	// An odd trailing byte is carried into the next chunk
+	if got := len(r.Resample(make([]byte, 601))); got != 400 {
+		t.Errorf("Resample(601 bytes) = %d bytes, want 400", got)
+	}
+	if got := len(r.Resample(make([]byte, 599))); got != 400 {
+		t.Errorf("Resample(599 bytes) after carry = %d bytes, want 400", got)
+	}
+}
+
+func TestResampler_SameRate(t *testing.T) {
+	r := NewResampler(16000, 16000)
+	chunk := []byte{1, 2, 3, 4}
+	if got := r.Resample(chunk); string(got) != string(chunk) {
+		t.Errorf("Resample() = %v, want %v", got, chunk)
+	}
+}
@@ -0,0 +1,80 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// Callback is where Deepgram delivers the transcript of an asynchronous
+// transcription submitted with SubmitTranscription.
+type Callback struct {
+	// URL receives the transcript once Deepgram has processed the audio.
+	URL string
+
+	// Method is the HTTP method Deepgram uses to deliver the transcript:
+	// "POST" (the default when empty) or "PUT".
+	Method string
+}
+
+// method returns the callback method to send to Deepgram.
+func (cb Callback) method() string {
+	if cb.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(cb.Method)
+}
+
+// validate returns an error wrapping stt.ErrInvalidConfig if cb has no URL
+// or an unsupported method.
+func (cb Callback) validate() error {
+	if cb.URL == "" {
+		return fmt.Errorf("%w: callback URL is required", stt.ErrInvalidConfig)
+	}
+	if m := cb.method(); m != http.MethodPost && m != http.MethodPut {
+		return fmt.Errorf("%w: callback method must be POST or PUT, got %q", stt.ErrInvalidConfig, cb.Method)
+	}
+	return nil
+}
+
+// errNoRequestID is returned by SubmitTranscription when Deepgram accepts
+// a request without returning its ID.
+var errNoRequestID = errors.New("response has no request ID")
+
+// SubmitTranscription submits src for asynchronous transcription and returns
+// the Deepgram request ID without waiting for the transcript, which Deepgram
+// delivers to callback.URL. Use PollTranscription with the returned ID to
+// learn when processing and delivery have finished.
+//
+// src and config are checked as for TranscribeSource. Zero-length audio and
+// empty files are rejected with stt.ErrInvalidAudio, since there would be
+// no request to track.
+func (p *Provider) SubmitTranscription(ctx context.Context, src Source, config stt.TranscriptionConfig, callback Callback) (string, error) {
+	if err := src.validate(); err != nil {
+		return "", err
+	}
+	if err := callback.validate(); err != nil {
+		return "", err
+	}
+	if err := omnivoice.ValidateTranscriptionConfig(config); err != nil {
+		return "", err
+	}
+
+	resp, _, err := p.recognize(ctx, src, config, &callback)
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("%w: no audio to transcribe", stt.ErrInvalidAudio)
+	}
+	if resp.RequestID == "" {
+		return "", errorf(ctx, "deepgram async transcription failed", errNoRequestID)
+	}
+
+	return resp.RequestID, nil
+}
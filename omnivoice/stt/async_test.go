@@ -0,0 +1,78 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+func TestSubmitTranscription_CallbackMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		want   string
+	}{
+		{"default", "", "POST"},
+		{"post", "POST", "POST"},
+		{"put", "PUT", "PUT"},
+		{"lowercase put", "put", "PUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCallback, gotMethod string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotCallback = r.URL.Query().Get("callback")
+				gotMethod = r.URL.Query().Get("callback_method")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"request_id":"req-123"}`))
+			}))
+			defer srv.Close()
+			t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+			p, err := New(WithAPIKey("test-key"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			callback := Callback{URL: "https://example.com/hook", Method: tt.method}
+			id, err := p.SubmitTranscription(context.Background(), Source{URL: "https://example.com/audio.wav"}, stt.TranscriptionConfig{}, callback)
+			if err != nil {
+				t.Fatalf("SubmitTranscription() error = %v", err)
+			}
+			if id != "req-123" {
+				t.Errorf("request ID = %q, want %q", id, "req-123")
+			}
+			if gotCallback != "https://example.com/hook" || gotMethod != tt.want {
+				t.Errorf("callback = %q %q, want %q %q", gotCallback, gotMethod, "https://example.com/hook", tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitTranscription_InvalidCallback(t *testing.T) {
+	unreachableServer(t)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	src := Source{URL: "https://example.com/audio.wav"}
+	for _, callback := range []Callback{
+		{URL: "https://example.com/hook", Method: "GET"},
+		{Method: "POST"},
+	} {
+		if _, err := p.SubmitTranscription(context.Background(), src, stt.TranscriptionConfig{}, callback); !errors.Is(err, stt.ErrInvalidConfig) {
+			t.Errorf("SubmitTranscription(%+v) error = %v, want ErrInvalidConfig", callback, err)
+		}
+	}
+
+	callback := Callback{URL: "https://example.com/hook"}
+	if _, err := p.SubmitTranscription(context.Background(), Source{Audio: []byte{}}, stt.TranscriptionConfig{}, callback); !errors.Is(err, stt.ErrInvalidAudio) {
+		t.Errorf("SubmitTranscription(empty audio) error = %v, want ErrInvalidAudio", err)
+	}
+}
@@ -0,0 +1,62 @@
+package stt
+
+import (
+	"context"
+	"sync"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// FileResult is the outcome of transcribing one file with TranscribeFiles.
+type FileResult struct {
+	// Path is the transcribed file.
+	Path string
+
+	// Result is the transcription, or nil if Err is set.
+	Result *stt.TranscriptionResult
+
+	// Err is the error transcribing this file, if any.
+	Err error
+}
+
+// TranscribeFiles transcribes the files at paths using up to concurrency
+// parallel requests, returning one FileResult per path in input order. A
+// failure only affects its own entry. When ctx is done, files that have not
+// started are not sent and report ctx.Err(). A concurrency below 1 is
+// treated as 1.
+func (p *Provider) TranscribeFiles(ctx context.Context, paths []string, config stt.TranscriptionConfig, concurrency int) []FileResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]FileResult, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Result, results[i].Err = p.TranscribeFile(ctx, paths[i], config)
+			}
+		}()
+	}
+
+	for i, path := range paths {
+		results[i].Path = path
+		if ctx.Err() != nil {
+			results[i].Err = ctx.Err()
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
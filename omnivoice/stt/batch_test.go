@@ -0,0 +1,103 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// transcriptServer echoes the uploaded audio back as the transcript, and
+// rejects audio containing "bad".
+func transcriptServer(t *testing.T) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body [64]byte
+		n, _ := r.Body.Read(body[:])
+		text := string(body[:n])
+		if text == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"err_code":"Bad Request","err_msg":"corrupt audio"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"duration":1.0},"results":{"channels":[{"alternatives":[{"transcript":"` + text + `"}]}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+}
+
+func writeFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(contents))
+	for i, c := range contents {
+		paths[i] = filepath.Join(dir, c+".raw")
+		if err := os.WriteFile(paths[i], []byte(c), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return paths
+}
+
+func TestTranscribeFiles(t *testing.T) {
+	transcriptServer(t)
+
+	paths := writeFiles(t, "one", "bad", "three", "four", "five")
+	paths = append(paths, filepath.Join(t.TempDir(), "missing.raw"))
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	results := p.TranscribeFiles(context.Background(), paths, stt.TranscriptionConfig{}, 3)
+	if len(results) != len(paths) {
+		t.Fatalf("got %d results, want %d", len(results), len(paths))
+	}
+
+	want := []string{"one", "", "three", "four", "five", ""}
+	for i, r := range results {
+		if r.Path != paths[i] {
+			t.Errorf("results[%d].Path = %q, want %q", i, r.Path, paths[i])
+		}
+		if want[i] == "" {
+			if r.Err == nil {
+				t.Errorf("results[%d] expected error", i)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("results[%d] error = %v", i, r.Err)
+			continue
+		}
+		if r.Result.Text != want[i] {
+			t.Errorf("results[%d].Text = %q, want %q", i, r.Result.Text, want[i])
+		}
+	}
+}
+
+func TestTranscribeFiles_Canceled(t *testing.T) {
+	unreachableServer(t)
+
+	paths := writeFiles(t, "one", "two", "three")
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i, r := range p.TranscribeFiles(ctx, paths, stt.TranscriptionConfig{}, 2) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i, r.Err)
+		}
+	}
+}
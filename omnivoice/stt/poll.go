@@ -0,0 +1,161 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	manageapi "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/manage/v1/interfaces"
+	"github.com/deepgram/deepgram-go-sdk/v3/pkg/api/version"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	manage "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/manage"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// Default polling intervals for PollTranscription.
+const (
+	DefaultPollInterval    = 2 * time.Second
+	DefaultMaxPollInterval = 30 * time.Second
+)
+
+// errNoProjectID is returned by PollTranscription when WithProjectID is not set.
+var errNoProjectID = errors.New("project ID is required to poll transcriptions (use WithProjectID)")
+
+// JobStatus describes a completed asynchronous (callback) transcription
+// request as recorded by Deepgram.
+//
+// Deepgram delivers the transcript of an asynchronous request only to its
+// callback URL; the status reported here tells callers when processing and
+// callback delivery have finished, not what the transcript is.
+type JobStatus struct {
+	// RequestID is the Deepgram request ID.
+	RequestID string
+
+	// Code is the HTTP status code of the transcription.
+	Code int
+
+	// Completed is when Deepgram finished processing the audio.
+	Completed time.Time
+
+	// Duration is the length of the processed audio.
+	Duration time.Duration
+
+	// CallbackCode is the HTTP status code returned by the callback URL.
+	CallbackCode int
+
+	// CallbackAttempts is the number of callback delivery attempts.
+	CallbackAttempts int
+
+	// CallbackCompleted is when the callback was delivered.
+	CallbackCompleted time.Time
+}
+
+// WithProjectID sets the Deepgram project ID used to look up requests.
+func WithProjectID(projectID string) Option {
+	return func(o *options) {
+		o.projectID = projectID
+	}
+}
+
+// WithPollInterval sets the initial and maximum delay between status checks
+// in PollTranscription. The delay doubles after each pending check up to
+// maxInterval. An initial delay of zero uses DefaultPollInterval and a
+// maxInterval of zero uses DefaultMaxPollInterval; a maxInterval below the
+// initial delay is raised to it, so the delay stays constant.
+func WithPollInterval(initial, maxInterval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = initial
+		o.maxPollInterval = maxInterval
+	}
+}
+
+// PollTranscription waits for the asynchronous transcription with the given
+// request ID to complete, checking Deepgram's request log with exponential
+// backoff until the request has completed or ctx is done. Requests that
+// Deepgram has not recorded yet are treated as pending.
+//
+// If the transcription completed with a non-2xx status code, the status is
+// returned together with an error.
+func (p *Provider) PollTranscription(ctx context.Context, requestID string) (*JobStatus, error) {
+	if p.projectID == "" {
+		return nil, errNoProjectID
+	}
+
+	c := manage.New(p.apiKey, p.endpoint.RESTOptions())
+	omnivoice.UseHTTPClient(c.HTTPClient, p.httpClient)
+
+	interval, maxInterval := pollIntervals(p.pollInterval, p.maxPollInterval)
+	for {
+		var resp manageapi.UsageRequestResult
+		err := c.APIRequest(ctx, http.MethodGet, version.UsageRequestByIDURI, nil, &resp, p.projectID, requestID)
+		switch {
+		case isNotFound(err):
+			// Not recorded yet
+		case err != nil:
+			return nil, errorf(ctx, "failed to poll transcription "+requestID, err)
+		case resp.Response.Completed != "":
+			status := requestToJobStatus(&resp.Request, requestID)
+			if status.Code >= 300 {
+				return status, fmt.Errorf("deepgram transcription %s failed with status %d", requestID, status.Code)
+			}
+			return status, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errorf(ctx, "transcription "+requestID+" not complete", ctx.Err())
+		case <-timer.C:
+		}
+
+		interval = min(interval*2, maxInterval)
+	}
+}
+
+// pollIntervals returns the initial and maximum polling delays for the
+// configured ones, applying the defaults to those that are unset.
+func pollIntervals(initial, maxInterval time.Duration) (time.Duration, time.Duration) {
+	if initial <= 0 {
+		initial = DefaultPollInterval
+	}
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxPollInterval
+	}
+	return initial, max(maxInterval, initial)
+}
+
+// requestToJobStatus converts a Deepgram request log entry to a JobStatus.
+func requestToJobStatus(req *manageapi.Request, requestID string) *JobStatus {
+	status := &JobStatus{
+		RequestID:         req.RequestID,
+		Code:              req.Response.Code,
+		Completed:         parseTime(req.Response.Completed),
+		Duration:          time.Duration(req.Response.Details.Duration * float64(time.Second)),
+		CallbackCode:      req.Callback.Code,
+		CallbackAttempts:  req.Callback.Attempts,
+		CallbackCompleted: parseTime(req.Callback.Completed),
+	}
+	if status.RequestID == "" {
+		status.RequestID = requestID
+	}
+	return status
+}
+
+// parseTime parses a Deepgram timestamp, returning the zero time if it is
+// empty or malformed.
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// isNotFound reports whether err is a Deepgram 404 response.
+func isNotFound(err error) bool {
+	var se *interfaces.StatusError
+	return errors.As(err, &se) && se.Resp != nil && se.Resp.StatusCode == http.StatusNotFound
+}
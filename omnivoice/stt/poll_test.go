@@ -0,0 +1,113 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollTranscription_PendingThenComplete(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/proj-1/requests/req-1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch calls.Add(1) {
+		case 1:
+			// Not recorded yet
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"err_code":"NOT_FOUND","err_msg":"request not found"}`))
+		case 2:
+			_, _ = w.Write([]byte(`{"request_id":"req-1","response":{}}`))
+		default:
+			_, _ = w.Write([]byte(`{
+				"request_id": "req-1",
+				"response": {"code": 200, "completed": "2024-05-01T12:00:05Z", "details": {"duration": 42.5}},
+				"callback": {"attempts": 1, "code": 200, "completed": "2024-05-01T12:00:06Z"}
+			}`))
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithProjectID("proj-1"), WithPollInterval(time.Millisecond, 2*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	status, err := p.PollTranscription(context.Background(), "req-1")
+	if err != nil {
+		t.Fatalf("PollTranscription() error = %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("status checked %d times, want 3", got)
+	}
+	if status.Code != 200 || status.Duration != 42500*time.Millisecond {
+		t.Errorf("status = %+v", status)
+	}
+	if want := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC); !status.Completed.Equal(want) {
+		t.Errorf("Completed = %v, want %v", status.Completed, want)
+	}
+	if status.CallbackCode != 200 || status.CallbackAttempts != 1 {
+		t.Errorf("callback = %d/%d, want 200/1", status.CallbackCode, status.CallbackAttempts)
+	}
+}
+
+func TestPollIntervals(t *testing.T) {
+	tests := []struct {
+		name                 string
+		initial, maxInterval time.Duration
+		wantInitial, wantMax time.Duration
+	}{
+		{"defaults", 0, 0, DefaultPollInterval, DefaultMaxPollInterval},
+		{"custom max", time.Second, 5 * time.Second, time.Second, 5 * time.Second},
+		{"custom max above default", time.Second, time.Minute, time.Second, time.Minute},
+		{"max below initial", 10 * time.Second, 5 * time.Second, 10 * time.Second, 10 * time.Second},
+		{"initial above default max", time.Minute, 0, time.Minute, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initial, maxInterval := pollIntervals(tt.initial, tt.maxInterval)
+			if initial != tt.wantInitial || maxInterval != tt.wantMax {
+				t.Errorf("pollIntervals(%v, %v) = %v, %v, want %v, %v", tt.initial, tt.maxInterval, initial, maxInterval, tt.wantInitial, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestPollTranscription_ContextDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"request_id":"req-1","response":{}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithProjectID("proj-1"), WithPollInterval(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.PollTranscription(ctx, "req-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PollTranscription() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestPollTranscription_RequiresProjectID(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := p.PollTranscription(context.Background(), "req-1"); err == nil {
+		t.Error("PollTranscription() without project ID should fail")
+	}
+}
@@ -2,36 +2,87 @@
 package stt
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
 	"sync"
+	"time"
 
 	restapi "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest"
+	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
 	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
 	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
 	client "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/listen"
+	"github.com/plexusone/omnivoice-core/audio/codec"
 	"github.com/plexusone/omnivoice-core/stt"
 	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+	klog "k8s.io/klog/v2"
 )
 
 // Verify interface compliance at compile time.
 var _ stt.StreamingProvider = (*Provider)(nil)
 
 // Provider implements stt.StreamingProvider using the Deepgram API.
+//
+// A Provider is safe for concurrent use. Any number of streaming sessions may
+// be open at once; each has its own connection, event channel, and state, and
+// sessions share nothing but the provider's immutable configuration. Batch
+// requests share one REST client and its connections, and run concurrently.
 type Provider struct {
-	apiKey string
-
-	mu sync.Mutex
+	apiKey             string
+	client             *restapi.Client
+	maxAudioDuration   time.Duration
+	encodingAutoDetect bool
+	projectID          string
+	pollInterval       time.Duration
+	maxPollInterval    time.Duration
+	debugWords         bool
+	defaultModel       string
+	modelFallback      []string
+	breaker            *omnivoice.CircuitBreaker
+	httpClient         *http.Client
+	idleTimeout        time.Duration
+	keepAlive          time.Duration
+	drainTimeout       time.Duration
+	observer           func(any)
+	writeRetries       int
+	writeBackoff       time.Duration
+	endpoint           omnivoice.Endpoint
+
+	// dial opens a connected streaming client; replaced in tests
+	dial func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, handler wsinterfaces.LiveMessageCallback) (DeepgramClient, error)
 }
 
 // Option configures the Provider.
 type Option func(*options)
 
 type options struct {
-	apiKey string
+	apiKey             string
+	maxAudioDuration   time.Duration
+	encodingAutoDetect bool
+	projectID          string
+	pollInterval       time.Duration
+	maxPollInterval    time.Duration
+	debugWords         bool
+	defaultModel       string
+	modelFallback      []string
+	failureThreshold   int
+	resetTimeout       time.Duration
+	httpClient         *http.Client
+	idleTimeout        time.Duration
+	keepAlive          time.Duration
+	drainTimeout       time.Duration
+	observer           func(any)
+	writeRetries       int
+	writeBackoff       time.Duration
+	baseURL            string
+	baseURLSet         bool
 }
 
 // WithAPIKey sets the Deepgram API key.
@@ -41,6 +92,180 @@ func WithAPIKey(apiKey string) Option {
 	}
 }
 
+// WithMaxAudioDuration rejects batch audio longer than d with stt.ErrAudioTooLong.
+// WAV input to Transcribe and TranscribeFile is measured from its header and
+// rejected before upload. TranscribeURL can only be checked against the
+// duration Deepgram reports, so an oversized URL is rejected after it has been
+// processed. Zero disables the check.
+func WithMaxAudioDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.maxAudioDuration = d
+	}
+}
+
+// WithEncodingAutoDetect enables detection of the audio format for
+// Transcribe and TranscribeFile from the leading bytes of the audio. WAV,
+// ID3-tagged MP3, Ogg, and FLAC input is sent with the matching
+// Content-Type; when no container is recognized, such as for raw PCM, the
+// Encoding, SampleRate, and Channels from the TranscriptionConfig are sent
+// instead. Leave Encoding empty for untagged MP3, which Deepgram then
+// detects itself.
+func WithEncodingAutoDetect(enabled bool) Option {
+	return func(o *options) {
+		o.encodingAutoDetect = enabled
+	}
+}
+
+// WithDebugWords attaches Deepgram's unmodified word objects, including
+// punctuated_word, confidence, and language, to the DebugWords field of
+// results from TranscribeSource and events from OpenStream. It is off by
+// default to avoid keeping a second copy of every word.
+func WithDebugWords(enabled bool) Option {
+	return func(o *options) {
+		o.debugWords = enabled
+	}
+}
+
+// WithDefaultModel sets the model used when a TranscriptionConfig does not
+// specify one. Defaults to omnivoice.DefaultSTTModel.
+func WithDefaultModel(model string) Option {
+	return func(o *options) {
+		o.defaultModel = model
+	}
+}
+
+// WithModelFallback sets models to try in order when Deepgram rejects the
+// requested model, such as nova-2 after nova-3. Batch transcription retries
+// down the chain only on a model error; authentication, network, and other
+// failures are returned immediately.
+func WithModelFallback(models []string) Option {
+	return func(o *options) {
+		o.modelFallback = models
+	}
+}
+
+// WithCircuitBreaker fails batch transcription requests fast with
+// omnivoice.ErrCircuitOpen after failureThreshold consecutive Deepgram
+// failures, letting a trial request through once resetTimeout has passed.
+// See omnivoice.CircuitBreaker for what counts as a failure. Streaming
+// sessions are not affected.
+func WithCircuitBreaker(failureThreshold int, resetTimeout time.Duration) Option {
+	return func(o *options) {
+		o.failureThreshold = failureThreshold
+		o.resetTimeout = resetTimeout
+	}
+}
+
+// WithHTTPClient sends the provider's REST requests with hc, for custom
+// timeouts, proxies, TLS settings, or a RoundTripper that intercepts
+// requests in tests. The SDK's ClientOptions has no field for a client, so
+// hc replaces the one the SDK builds; see omnivoice.UseHTTPClient.
+// WebSocket streaming connections do not use it. A nil hc keeps the
+// default client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = hc
+	}
+}
+
+// WithSharedClient sends the provider's REST requests with hc. Passing the
+// same client to the STT and TTS providers makes them share one transport
+// and connection pool instead of each building their own. It is
+// WithHTTPClient under a name for that use.
+func WithSharedClient(hc *http.Client) Option {
+	return WithHTTPClient(hc)
+}
+
+// WithIdleTimeout closes a streaming session once no audio has been written
+// and no events have been received for d, so that a session abandoned
+// without Close does not leak its connection and goroutines. Before closing,
+// the session emits an EventError event whose Error wraps ErrIdleTimeout.
+// Zero disables the timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
+
+// DefaultKeepAliveInterval is the keep-alive interval used by WithKeepAlive
+// when none is given. Deepgram closes a streaming connection that has
+// received no audio for about ten seconds.
+const DefaultKeepAliveInterval = 5 * time.Second
+
+// WithKeepAlive sends Deepgram a KeepAlive control message every interval
+// while a streaming session is open but no audio is being written, so that
+// pausing the audio, such as while muted, does not get the connection
+// closed. Any audio written restarts the interval, and the messages stop
+// when the stream is closed. Keep-alives do not count as activity for
+// WithIdleTimeout. A zero or negative interval uses
+// DefaultKeepAliveInterval; without this option no keep-alives are sent.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(o *options) {
+		if interval <= 0 {
+			interval = DefaultKeepAliveInterval
+		}
+		o.keepAlive = interval
+	}
+}
+
+// DefaultDrainTimeout is how long Close waits by default for Deepgram to
+// deliver the final results of a streaming session.
+const DefaultDrainTimeout = 3 * time.Second
+
+// WithDrainTimeout sets how long Close waits for the final results of a
+// streaming session. Close asks Deepgram to finish transcribing the audio
+// already sent and returns once Deepgram has delivered the remaining
+// results and the session metadata, or after d, whichever comes first. A
+// zero d uses DefaultDrainTimeout; a negative d closes the connection at
+// once, dropping any results still in flight. d also bounds how long the
+// EventClose event waits for room on a full event channel.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.drainTimeout = d
+	}
+}
+
+// WithObserver calls fn with every event of every streaming session, as an
+// omnivoice.StreamEvent, once it has been delivered to the session's
+// channel; events dropped because the session has ended are not seen. It
+// is meant for centralized logging and metrics. fn runs inline
+// on the goroutine delivering Deepgram's messages, so it must return
+// quickly and must not call back into the stream; it may be called
+// concurrently for different sessions.
+func WithObserver(fn func(any)) Option {
+	return func(o *options) {
+		o.observer = fn
+	}
+}
+
+// WithWriteRetry retries a streaming audio write that fails up to retries
+// more times, waiting backoff before each attempt, so that a momentary
+// disconnect does not end the session while the Deepgram client reconnects.
+// The chunk is held and sent again unchanged, so Write returns only once it
+// has been sent, the stream is closed, or the retries are exhausted, in
+// which case the error wraps ErrWriteFailed. Zero retries, the default,
+// returns the first error.
+func WithWriteRetry(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.writeRetries = retries
+		o.writeBackoff = backoff
+	}
+}
+
+// WithBaseURL sends the provider's requests to the Deepgram deployment at
+// rawURL instead of Deepgram's hosted API, for self-hosted servers. rawURL
+// is a scheme, host, and optional port, such as "https://dg.internal:8443";
+// see omnivoice.ParseBaseURL. New fails if it is empty or malformed. It
+// applies to REST requests and streaming connections alike. The
+// DEEPGRAM_HOST environment variable, which the SDK reads when building
+// each client, still takes precedence.
+func WithBaseURL(rawURL string) Option {
+	return func(o *options) {
+		o.baseURL = rawURL
+		o.baseURLSet = true
+	}
+}
+
 // New creates a new Deepgram STT provider.
 func New(opts ...Option) (*Provider, error) {
 	cfg := &options{}
@@ -52,12 +277,45 @@ func New(opts ...Option) (*Provider, error) {
 		return nil, fmt.Errorf("API key is required")
 	}
 
+	var endpoint omnivoice.Endpoint
+	if cfg.baseURLSet {
+		var err error
+		if endpoint, err = omnivoice.ParseBaseURL(cfg.baseURL); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the Deepgram client library (shared across STT/TTS)
 	omnivoice.InitSDK()
 
-	return &Provider{
-		apiKey: cfg.apiKey,
-	}, nil
+	// Create the REST client once, so batch requests reuse its connections
+	restClient := client.NewREST(cfg.apiKey, endpoint.RESTOptions())
+	omnivoice.UseHTTPClient(restClient.HTTPClient, cfg.httpClient)
+
+	p := &Provider{
+		apiKey:             cfg.apiKey,
+		client:             restapi.New(restClient),
+		maxAudioDuration:   cfg.maxAudioDuration,
+		encodingAutoDetect: cfg.encodingAutoDetect,
+		projectID:          cfg.projectID,
+		pollInterval:       cfg.pollInterval,
+		maxPollInterval:    cfg.maxPollInterval,
+		debugWords:         cfg.debugWords,
+		defaultModel:       cfg.defaultModel,
+		modelFallback:      cfg.modelFallback,
+		breaker:            omnivoice.NewCircuitBreaker(cfg.failureThreshold, cfg.resetTimeout),
+		httpClient:         cfg.httpClient,
+		idleTimeout:        cfg.idleTimeout,
+		keepAlive:          cfg.keepAlive,
+		drainTimeout:       cfg.drainTimeout,
+		observer:           cfg.observer,
+		writeRetries:       cfg.writeRetries,
+		writeBackoff:       cfg.writeBackoff,
+		endpoint:           endpoint,
+	}
+	p.dial = p.dialDeepgram
+
+	return p, nil
 }
 
 // Name returns the provider name.
@@ -65,138 +323,586 @@ func (p *Provider) Name() string {
 	return omnivoice.ProviderName
 }
 
-// Transcribe converts audio to text (batch mode).
-func (p *Provider) Transcribe(ctx context.Context, audio []byte, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// errConnect is returned when the WebSocket connection cannot be established.
+var errConnect = errors.New("connection failed")
 
-	// Create REST client
-	c := client.NewREST(p.apiKey, &interfaces.ClientOptions{})
-	dg := restapi.New(c)
+// ErrIdleTimeout is carried by the EventError event emitted when a
+// streaming session is closed by WithIdleTimeout.
+var ErrIdleTimeout = errors.New("deepgram: stream closed after idle timeout")
 
-	// Convert config to Deepgram options
-	opts := omnivoice.ConfigToPreRecordedOptions(config)
+// ErrWriteFailed is wrapped by the error Write returns once an audio chunk
+// still cannot be sent after the retries set with WithWriteRetry.
+var ErrWriteFailed = errors.New("deepgram: audio write failed")
 
-	// Transcribe from stream (bytes)
-	resp, err := dg.FromStream(ctx, bytes.NewReader(audio), opts)
-	if err != nil {
-		return nil, fmt.Errorf("deepgram transcription failed: %w", err)
+// correlationTags appends the correlation ID carried by ctx, if any, to the
+// Deepgram request tags.
+func correlationTags(ctx context.Context, tags []string) []string {
+	if id := omnivoice.CorrelationIDFromContext(ctx); id != "" {
+		return append(tags, id)
 	}
+	return tags
+}
 
-	// Convert response to OmniVoice result
-	return omnivoice.PreRecordedResponseToResult(resp), nil
+// errorf wraps err with msg, annotating it with the correlation ID carried
+// by ctx, if any.
+func errorf(ctx context.Context, msg string, err error) error {
+	if id := omnivoice.CorrelationIDFromContext(ctx); id != "" {
+		return fmt.Errorf("%s [correlation_id=%s]: %w", msg, id, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
-// TranscribeFile transcribes audio from a file path.
-func (p *Provider) TranscribeFile(ctx context.Context, filePath string, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// Source identifies the audio for a batch transcription. Set exactly one of
+// Audio, File, or URL.
+type Source struct {
+	// Audio is the audio data to upload.
+	Audio []byte
 
-	// Create REST client
-	c := client.NewREST(p.apiKey, &interfaces.ClientOptions{})
-	dg := restapi.New(c)
+	// File is the path of an audio file to upload.
+	File string
 
-	// Convert config to Deepgram options
-	opts := omnivoice.ConfigToPreRecordedOptions(config)
+	// URL is the address of remote audio for Deepgram to fetch.
+	URL string
+}
 
-	// Transcribe from file
-	resp, err := dg.FromFile(ctx, filePath, opts)
+// validate returns an error wrapping stt.ErrInvalidConfig if more than one
+// of Audio, File, or URL is set.
+func (src Source) validate() error {
+	if (src.Audio != nil && src.File != "") || (src.Audio != nil && src.URL != "") || (src.File != "" && src.URL != "") {
+		return fmt.Errorf("%w: only one of Audio, File, or URL may be set", stt.ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Transcribe converts audio to text (batch mode). A cancelled request
+// returns no transcript, since Deepgram sends it in a single response; use
+// TranscribeStreamed to keep the results received before cancellation.
+func (p *Provider) Transcribe(ctx context.Context, audio []byte, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
+	result, err := p.TranscribeSource(ctx, Source{Audio: audio}, config)
 	if err != nil {
-		return nil, fmt.Errorf("deepgram file transcription failed: %w", err)
+		return nil, err
 	}
+	return &result.TranscriptionResult, nil
+}
 
-	// Convert response to OmniVoice result
-	return omnivoice.PreRecordedResponseToResult(resp), nil
+// TranscribeFile transcribes audio from a file path.
+func (p *Provider) TranscribeFile(ctx context.Context, filePath string, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
+	result, err := p.TranscribeSource(ctx, Source{File: filePath}, config)
+	if err != nil {
+		return nil, err
+	}
+	return &result.TranscriptionResult, nil
 }
 
 // TranscribeURL transcribes audio from a URL.
 func (p *Provider) TranscribeURL(ctx context.Context, url string, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	result, err := p.TranscribeSource(ctx, Source{URL: url}, config)
+	if err != nil {
+		return nil, err
+	}
+	return &result.TranscriptionResult, nil
+}
 
-	// Create REST client
-	c := client.NewREST(p.apiKey, &interfaces.ClientOptions{})
-	dg := restapi.New(c)
+// TranscribeText transcribes audio in batch mode and returns only the
+// text of the top transcript. Audio without speech yields an empty string.
+func (p *Provider) TranscribeText(ctx context.Context, audio []byte, config stt.TranscriptionConfig) (string, error) {
+	return p.transcribeText(ctx, Source{Audio: audio}, config)
+}
 
-	// Convert config to Deepgram options
-	opts := omnivoice.ConfigToPreRecordedOptions(config)
+// TranscribeFileText is like TranscribeText, but reads audio from a file
+// path.
+func (p *Provider) TranscribeFileText(ctx context.Context, filePath string, config stt.TranscriptionConfig) (string, error) {
+	return p.transcribeText(ctx, Source{File: filePath}, config)
+}
+
+// TranscribeURLText is like TranscribeText, but transcribes audio from a
+// URL.
+func (p *Provider) TranscribeURLText(ctx context.Context, url string, config stt.TranscriptionConfig) (string, error) {
+	return p.transcribeText(ctx, Source{URL: url}, config)
+}
+
+// transcribeText transcribes src and returns the text of the result.
+func (p *Provider) transcribeText(ctx context.Context, src Source, config stt.TranscriptionConfig) (string, error) {
+	result, err := p.TranscribeSource(ctx, src, config)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
 
-	// Transcribe from URL
-	resp, err := dg.FromURL(ctx, url, opts)
+// TranscribeSource transcribes audio from src in batch mode, returning the
+// Deepgram result with any provider-specific detail enabled by options.
+// Transcribe, TranscribeFile, and TranscribeURL are shorthands for it.
+//
+// Audio without speech, such as silence or zero-length input, yields a
+// non-nil result with empty Text and nil Segments rather than an error.
+// Zero-length audio and empty files are not uploaded.
+func (p *Provider) TranscribeSource(ctx context.Context, src Source, config stt.TranscriptionConfig) (*omnivoice.TranscriptionResult, error) {
+	if err := src.validate(); err != nil {
+		return nil, err
+	}
+	if err := omnivoice.ValidateTranscriptionConfig(config); err != nil {
+		return nil, err
+	}
+
+	resp, elapsed, err := p.recognize(ctx, src, config, nil)
 	if err != nil {
-		return nil, fmt.Errorf("deepgram URL transcription failed: %w", err)
+		return nil, err
 	}
 
 	// Convert response to OmniVoice result
-	return omnivoice.PreRecordedResponseToResult(resp), nil
+	result := omnivoice.PreRecordedResponseToTranscriptionResult(resp, p.convertOptions(config))
+	result.ProcessingDuration = elapsed
+
+	// Remote audio can only be measured once Deepgram has processed it
+	if src.URL != "" && p.maxAudioDuration > 0 && result.Duration > p.maxAudioDuration {
+		return nil, fmt.Errorf("%w: %s exceeds maximum of %s", stt.ErrAudioTooLong, result.Duration, p.maxAudioDuration)
+	}
+
+	return result, nil
+}
+
+// recognize sends src to Deepgram with the options for config, retrying
+// down the model fallback chain, and returns the response and the time
+// Deepgram took to answer. If callback is set, Deepgram is asked to deliver
+// the transcript there and the response carries only the request ID.
+//
+// A nil response with a nil error means src holds no audio, so nothing was
+// sent.
+func (p *Provider) recognize(ctx context.Context, src Source, config stt.TranscriptionConfig, callback *Callback) (*restinterfaces.PreRecordedResponse, time.Duration, error) {
+	dg := p.client
+
+	// Convert config to Deepgram options
+	opts := omnivoice.ConfigToPreRecordedOptions(p.withDefaults(config))
+	opts.Tag = correlationTags(ctx, opts.Tag)
+	ctx = omnivoice.ListenContext(ctx, config)
+	if callback != nil {
+		opts.Callback = callback.URL
+		opts.CallbackMethod = callback.method()
+	}
+
+	var (
+		send func() (*restinterfaces.PreRecordedResponse, error)
+		msg  string
+	)
+	switch {
+	case src.URL != "":
+		// Transcribe from URL
+		send = func() (*restinterfaces.PreRecordedResponse, error) {
+			return dg.FromURL(ctx, src.URL, opts)
+		}
+		msg = "deepgram URL transcription failed"
+
+	case src.File != "":
+		// Empty files contain no speech, so there is nothing to upload
+		if info, err := os.Stat(src.File); err == nil && info.Size() == 0 {
+			return nil, 0, nil
+		}
+
+		// Reject truncated or oversized WAV audio before uploading it
+		if err := checkFileLength(src.File); err != nil {
+			return nil, 0, err
+		}
+		if err := p.checkFileDuration(src.File); err != nil {
+			return nil, 0, err
+		}
+
+		// Describe the audio format from the file's leading bytes
+		if p.encodingAutoDetect {
+			header, err := readFileHeader(src.File, omnivoice.AudioSniffLen)
+			if err != nil {
+				return nil, 0, err
+			}
+			ctx = withContentType(ctx, omnivoice.ApplyAudioFormat(opts, header, config))
+		}
+
+		// Transcribe from file
+		send = func() (*restinterfaces.PreRecordedResponse, error) {
+			return dg.FromFile(ctx, src.File, opts)
+		}
+		msg = "deepgram file transcription failed"
+
+	default:
+		// Empty audio contains no speech, so there is nothing to upload
+		if len(src.Audio) == 0 {
+			return nil, 0, nil
+		}
+
+		// Reject truncated or oversized WAV audio before uploading it
+		if err := checkAudioLength(bytes.NewReader(src.Audio), int64(len(src.Audio))); err != nil {
+			return nil, 0, err
+		}
+		if err := p.checkAudioDuration(bytes.NewReader(src.Audio), int64(len(src.Audio))); err != nil {
+			return nil, 0, err
+		}
+
+		// Describe the audio format from its leading bytes
+		if p.encodingAutoDetect {
+			ctx = withContentType(ctx, omnivoice.ApplyAudioFormat(opts, src.Audio, config))
+		}
+
+		// Transcribe from stream (bytes)
+		send = func() (*restinterfaces.PreRecordedResponse, error) {
+			return dg.FromStream(ctx, bytes.NewReader(src.Audio), opts)
+		}
+		msg = "deepgram transcription failed"
+	}
+
+	if err := p.breaker.Allow(); err != nil {
+		return nil, 0, errorf(ctx, msg, err)
+	}
+
+	// Retry down the fallback chain while Deepgram rejects the model
+	var (
+		resp    *restinterfaces.PreRecordedResponse
+		err     error
+		elapsed time.Duration
+	)
+	for _, model := range omnivoice.ModelChain(opts.Model, p.modelFallback) {
+		opts.Model = model
+		start := time.Now()
+		resp, err = send()
+		elapsed = time.Since(start)
+		if !omnivoice.IsModelError(err) {
+			break
+		}
+	}
+	p.breaker.Record(err)
+	if err != nil {
+		return nil, 0, errorf(ctx, msg, err)
+	}
+
+	return resp, elapsed, nil
+}
+
+// withDefaults fills fields left empty in config with the provider defaults.
+func (p *Provider) withDefaults(config stt.TranscriptionConfig) stt.TranscriptionConfig {
+	if config.Model == "" {
+		config.Model = p.defaultModel
+	}
+	return config
+}
+
+// convertOptions returns the response conversion options configured on p
+// and in config.
+func (p *Provider) convertOptions(config stt.TranscriptionConfig) omnivoice.ConvertOptions {
+	return omnivoice.ConvertOptions{
+		DebugWords:          p.debugWords,
+		FormatLocale:        omnivoice.FormatLocale(config),
+		DiarizationGrouping: omnivoice.DiarizationGroupingFor(config),
+	}
+}
+
+// withContentType returns a copy of ctx that sends the given Content-Type
+// with the request, preserving any custom headers already on ctx. An empty
+// content type leaves ctx unchanged.
+func withContentType(ctx context.Context, contentType string) context.Context {
+	if contentType == "" {
+		return ctx
+	}
+
+	headers := http.Header{}
+	if existing, ok := ctx.Value(interfaces.HeadersContext{}).(http.Header); ok {
+		headers = existing.Clone()
+	}
+	headers.Set("Content-Type", contentType)
+
+	return interfaces.WithCustomHeaders(ctx, headers)
+}
+
+// readFileHeader reads up to n leading bytes of the file at filePath.
+func readFileHeader(filePath string, n int) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, n)
+	read, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	return header[:read], nil
+}
+
+// checkAudioLength returns stt.ErrInvalidAudio if r, of size bytes, holds
+// WAV audio shorter than its header declares. Deepgram transcribes such
+// input without complaint but returns garbage for the missing audio.
+// Non-WAV audio is not checked.
+func checkAudioLength(r io.Reader, size int64) error {
+	if err := omnivoice.CheckWAVLength(r, size); err != nil {
+		return fmt.Errorf("%w: %w", stt.ErrInvalidAudio, err)
+	}
+	return nil
+}
+
+// checkFileLength applies checkAudioLength to the file at filePath.
+func checkFileLength(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+	return checkAudioLength(bufio.NewReader(f), info.Size())
+}
+
+// checkAudioDuration returns stt.ErrAudioTooLong if r, a stream of size
+// bytes, holds WAV audio longer than the configured maximum. Non-WAV audio
+// is not checked, and WAV with a placeholder data length is measured by
+// the stream's length.
+func (p *Provider) checkAudioDuration(r io.Reader, size int64) error {
+	if p.maxAudioDuration <= 0 {
+		return nil
+	}
+
+	d, err := omnivoice.WAVDuration(r, size)
+	if err != nil {
+		// Only WAV headers carry a duration we can trust before upload
+		return nil
+	}
+
+	if d > p.maxAudioDuration {
+		return fmt.Errorf("%w: %s exceeds maximum of %s", stt.ErrAudioTooLong, d, p.maxAudioDuration)
+	}
+	return nil
+}
+
+// checkFileDuration applies checkAudioDuration to the file at filePath.
+func (p *Provider) checkFileDuration(filePath string) error {
+	if p.maxAudioDuration <= 0 {
+		return nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+	return p.checkAudioDuration(bufio.NewReader(f), info.Size())
 }
 
 // TranscribeStream starts a streaming transcription session.
 // Returns a writer for sending audio and a channel for receiving events.
+// The last event before the channel is closed is an omnivoice.EventClose,
+// whose Error is nil when the stream ended cleanly.
 func (p *Provider) TranscribeStream(ctx context.Context, config stt.TranscriptionConfig) (io.WriteCloser, <-chan stt.StreamEvent, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	// Convert config to Deepgram options
-	dgOptions := omnivoice.ConfigToLiveTranscriptionOptions(config)
+	stream, events, err := p.OpenStream(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stream, coreEvents(ctx, events), nil
+}
 
-	// Create the callback handler
+// coreEvents forwards the core OmniVoice events of a Deepgram session
+// until it is closed.
+func coreEvents(ctx context.Context, events <-chan omnivoice.StreamEvent) <-chan stt.StreamEvent {
 	eventCh := make(chan stt.StreamEvent, 100)
-	handler := &callbackHandler{
-		eventCh: eventCh,
-		ctx:     ctx,
-	}
+	go func() {
+		defer close(eventCh)
+		for event := range events {
+			select {
+			case eventCh <- event.StreamEvent:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return eventCh
+}
 
-	// Create WebSocket client with callback
-	dgClient, err := client.NewWSUsingCallbackWithDefaults(ctx, dgOptions, handler)
+// TranscribeStreamText starts a streaming transcription session that only
+// delivers the text of final transcripts. Interim results, speech events, and
+// empty finals are dropped. The channel is closed when the stream is closed.
+func (p *Provider) TranscribeStreamText(ctx context.Context, config stt.TranscriptionConfig) (io.WriteCloser, <-chan string, error) {
+	stream, events, err := p.OpenStream(ctx, config)
 	if err != nil {
-		close(eventCh)
-		return nil, nil, fmt.Errorf("failed to create Deepgram client: %w", err)
+		return nil, nil, err
 	}
 
-	// Connect to Deepgram
-	if !dgClient.Connect() {
-		close(eventCh)
-		return nil, nil, fmt.Errorf("failed to connect to Deepgram")
+	textCh := make(chan string, 100)
+	go func() {
+		defer close(textCh)
+		for event := range events {
+			if event.Type != stt.EventTranscript || !event.IsFinal || event.Transcript == "" {
+				continue
+			}
+			select {
+			case textCh <- event.Transcript:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return stream, textCh, nil
+}
+
+// OpenStream starts a streaming transcription session like TranscribeStream,
+// but returns the Deepgram Stream and events carrying Deepgram-specific
+// detail such as stable word IDs. The last event is an omnivoice.EventClose
+// describing why the stream ended.
+//
+// Events are not dropped when the reader falls behind: once the channel's
+// buffer is full, Deepgram's further messages wait until the reader catches
+// up, the stream is closed, or ctx is done.
+func (p *Provider) OpenStream(ctx context.Context, config stt.TranscriptionConfig) (*Stream, <-chan omnivoice.StreamEvent, error) {
+	if err := omnivoice.ValidateTranscriptionConfig(config); err != nil {
+		return nil, nil, err
+	}
+
+	// Convert config to Deepgram options
+	dgOptions := omnivoice.ConfigToLiveTranscriptionOptions(p.withDefaults(config))
+	dgOptions.Tag = correlationTags(ctx, dgOptions.Tag)
+
+	// Activity is only tracked when the session can time out, and audio
+	// only when it sends keep-alives
+	var activity, audio chan struct{}
+	if p.idleTimeout > 0 {
+		activity = make(chan struct{}, 1)
+	}
+	if p.keepAlive > 0 {
+		audio = make(chan struct{}, 1)
 	}
 
-	// Create the audio writer
-	writer := &streamWriter{
-		client:  dgClient,
-		eventCh: eventCh,
+	// Create the audio writer and the callback handler delivering to it
+	eventCh := make(chan omnivoice.StreamEvent, 100)
+	writer := &Stream{
+		eventCh:  eventCh,
+		ctx:      ctx,
+		done:     make(chan struct{}),
+		activity: activity,
+		audio:    audio,
+		drained:  make(chan struct{}, 1),
+		observer: p.observer,
+		retries:  p.writeRetries,
+		backoff:  p.writeBackoff,
+	}
+	writer.drainTimeout = p.drainTimeout
+	if writer.drainTimeout == 0 {
+		writer.drainTimeout = DefaultDrainTimeout
+	}
+	handler := &callbackHandler{
+		stream:  writer,
 		ctx:     ctx,
-		done:    make(chan struct{}),
+		convert: p.convertOptions(config),
+	}
+
+	// Connect to Deepgram
+	dgClient, err := p.dial(omnivoice.ListenContext(ctx, config), dgOptions, handler)
+	if err != nil {
+		close(eventCh)
+		return nil, nil, err
 	}
+	writer.client = dgClient
 
 	// Handle context cancellation
 	go func() {
 		select {
 		case <-ctx.Done():
-			_ = writer.Close()
+			writer.closeWith(omnivoice.StreamClose{Reason: omnivoice.CloseContext, Err: ctx.Err()}, false)
 		case <-writer.done:
 		}
 	}()
 
+	if p.idleTimeout > 0 {
+		go writer.closeWhenIdle(p.idleTimeout)
+	}
+	if p.keepAlive > 0 {
+		go writer.keepAlive(p.keepAlive)
+	}
+
 	return writer, eventCh, nil
 }
 
-// streamWriter implements io.WriteCloser for sending audio to Deepgram.
-type streamWriter struct {
+// dialDeepgram creates a WebSocket client for a streaming session and
+// connects it to Deepgram.
+func (p *Provider) dialDeepgram(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, handler wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+	dgClient, err := client.NewWSUsingCallback(ctx, p.apiKey, p.endpoint.WSOptions(), opts, handler)
+	if err != nil {
+		return nil, errorf(ctx, "failed to create Deepgram client", err)
+	}
+
+	if !dgClient.Connect() {
+		return nil, errorf(ctx, "failed to connect to Deepgram", errConnect)
+	}
+
+	return dgClient, nil
+}
+
+// Stream is the audio writer returned by TranscribeStream and OpenStream.
+// It implements io.WriteCloser and can be type-asserted by callers that need
+// the Deepgram-specific session controls.
+type Stream struct {
 	client  DeepgramClient
-	eventCh chan stt.StreamEvent
+	eventCh chan omnivoice.StreamEvent
 	ctx     context.Context
 	done    chan struct{}
 	closed  bool
 	mu      sync.Mutex
+
+	// sending is held for reading while an event is being delivered, so
+	// that closeWith can wait for deliveries before closing eventCh
+	sending sync.RWMutex
+
+	// activity is signaled on every write and event; nil unless the
+	// session has an idle timeout
+	activity chan struct{}
+
+	// audio is signaled on every write; nil unless the session sends
+	// keep-alives
+	audio chan struct{}
+
+	// drained is signaled when Deepgram sends the session metadata, which
+	// follows the last results; drainTimeout bounds how long Close waits
+	// for it, and draining is set once the caller has asked for it, with
+	// Close or at the end of a TranscribeStreamReader source
+	drained      chan struct{}
+	drainTimeout time.Duration
+	draining     bool
+
+	// observer sees every delivered event; may be nil
+	observer func(any)
+
+	// lastErr is the last error Deepgram reported, for the close event
+	lastErr error
+
+	// processed is the audio covered by final results in the current
+	// utterance
+	processed time.Duration
+
+	// offset is where the current utterance starts in the stream, and
+	// result times are reported relative to it; end is the furthest
+	// result end seen. After a Reset, finalizing is set until Deepgram
+	// answers the finalize, whose result still belongs to the previous
+	// utterance starting at prevOffset
+	offset     time.Duration
+	prevOffset time.Duration
+	end        time.Duration
+	finalizing bool
+
+	// retries and backoff control how failed writes are retried
+	retries int
+	backoff time.Duration
 }
 
 // DeepgramClient interface for the Deepgram WebSocket client.
 type DeepgramClient interface {
 	Write(p []byte) (n int, err error)
+	WriteJSON(payload any) error
+	Finalize() error
 	Stop()
 }
 
-func (w *streamWriter) Write(p []byte) (n int, err error) {
+func (w *Stream) Write(p []byte) (n int, err error) {
 	w.mu.Lock()
 	if w.closed {
 		w.mu.Unlock()
@@ -204,32 +910,421 @@ func (w *streamWriter) Write(p []byte) (n int, err error) {
 	}
 	w.mu.Unlock()
 
-	return w.client.Write(p)
+	touch(w.activity)
+	touch(w.audio)
+	n, err = w.client.Write(p)
+	if err == nil || w.retries <= 0 {
+		return n, err
+	}
+	return w.retryWrite(p, err)
+}
+
+// retryWrite resends p after a failed write, up to the stream's retry
+// limit, stopping early if the stream is closed.
+func (w *Stream) retryWrite(p []byte, err error) (int, error) {
+	timer := time.NewTimer(w.backoff)
+	defer timer.Stop()
+
+	for range w.retries {
+		timer.Reset(w.backoff)
+		select {
+		case <-w.done:
+			return 0, io.ErrClosedPipe
+		case <-timer.C:
+		}
+
+		var n int
+		if n, err = w.client.Write(p); err == nil {
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w after %d retries: %w", ErrWriteFailed, w.retries, err)
+}
+
+// touch signals activity on a session's activity channel without blocking.
+// A nil channel is ignored.
+func touch(activity chan struct{}) {
+	select {
+	case activity <- struct{}{}:
+	default:
+	}
+}
+
+// closeWhenIdle closes the stream after d passes without activity, first
+// emitting an EventError event carrying ErrIdleTimeout.
+func (w *Stream) closeWhenIdle(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.activity:
+			timer.Reset(d)
+		case <-w.done:
+			return
+		case <-timer.C:
+			err := errorf(w.ctx, "deepgram stream idle", fmt.Errorf("%w after %s", ErrIdleTimeout, d))
+			w.deliver(omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{
+				Type:  stt.EventError,
+				Error: err,
+			}})
+			w.closeWith(omnivoice.StreamClose{Reason: omnivoice.CloseIdle, Err: err}, false)
+			return
+		}
+	}
+}
+
+// keepAliveMessage asks Deepgram to hold the connection open while no
+// audio is being sent.
+var keepAliveMessage = json.RawMessage(`{"type":"KeepAlive"}`)
+
+// keepAlive sends a keep-alive message whenever d passes without audio
+// being written, until the stream is closed.
+func (w *Stream) keepAlive(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.audio:
+			timer.Reset(d)
+		case <-w.done:
+			return
+		case <-timer.C:
+			if err := w.SendControl(keepAliveMessage); err != nil {
+				if errors.Is(err, io.ErrClosedPipe) {
+					return
+				}
+				klog.V(1).Infof("deepgram: keep-alive failed: %v", err)
+			}
+			timer.Reset(d)
+		}
+	}
+}
+
+// WriteFloat32 converts float32 samples in the range [-1.0, 1.0] to 16-bit
+// little-endian PCM and writes them to the stream. Out-of-range samples are
+// clamped. The stream must have been opened with linear16 encoding. It
+// returns the number of samples written.
+func (w *Stream) WriteFloat32(samples []float32) (int, error) {
+	n, err := w.Write(codec.Int16ToBytes(codec.Float32ToInt16(samples), false))
+	return n / 2, err
 }
 
-func (w *streamWriter) Close() error {
+// ErrInvalidControlMessage is returned by SendControl for a message that is
+// not a JSON object.
+var ErrInvalidControlMessage = errors.New("control message must be a JSON object")
+
+// SendControl sends msg to Deepgram as a raw control message, such as
+// {"type": "KeepAlive"}. It is an escape hatch for control messages this
+// package does not wrap; msg must be a JSON object and is sent unchanged.
+func (w *Stream) SendControl(msg json.RawMessage) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &obj); err != nil || obj == nil {
+		return ErrInvalidControlMessage
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	if w.closed {
-		return nil
+		return io.ErrClosedPipe
 	}
-	w.closed = true
 
-	// Stop the Deepgram client
-	w.client.Stop()
+	if err := w.client.WriteJSON(msg); err != nil {
+		return fmt.Errorf("failed to send control message: %w", err)
+	}
+
+	return nil
+}
+
+// Finalize sends Deepgram's Finalize control message, which flushes the
+// audio received so far and delivers its transcript as a final event
+// (with FromFinalize set) without waiting for endpointing, such as when a
+// push-to-talk button is released. The session stays open for more audio.
+//
+// The writer returned by TranscribeStream is a *Stream, so callers reach
+// Finalize with a type assertion:
+//
+//	if s, ok := writer.(*stt.Stream); ok {
+//		err = s.Finalize()
+//	}
+func (w *Stream) Finalize() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return io.ErrClosedPipe
+	}
+
+	if err := w.client.Finalize(); err != nil {
+		return fmt.Errorf("failed to finalize utterance: %w", err)
+	}
+
+	return nil
+}
+
+// Reset ends the current utterance and prepares the session for the next
+// one on the same connection. It asks Deepgram to finalize any buffered
+// audio, so the pending transcript is delivered as a final event, without
+// tearing down the WebSocket.
+//
+// A full reconnect (Close followed by a new TranscribeStream) is still
+// required to change the model, language, encoding, or sample rate, since
+// those are fixed when the connection is opened, and after the connection
+// has failed or been closed.
+//
+// Reset also clears the per-utterance state: ProcessedDuration and the
+// last reported error start over, and the times and word IDs of later
+// results are relative to the start of the next utterance. The final
+// result Deepgram sends in answer to the finalize still belongs to the
+// previous utterance and is reported as such.
+func (w *Stream) Reset() error {
+	if err := w.Finalize(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.processed = 0
+	w.lastErr = nil
+	w.prevOffset = w.offset
+	w.offset = max(w.offset, w.end)
+	w.finalizing = true
+	return nil
+}
+
+// Close ends the session. It first asks Deepgram to finish transcribing
+// the audio already written and waits, up to the provider's drain timeout,
+// for the remaining results, so that the last final transcript is
+// delivered before the EventClose event. See WithDrainTimeout. If the
+// event channel is full, the EventClose event waits for room for at most
+// the drain timeout and is dropped after it; the channel is closed either
+// way.
+func (w *Stream) Close() error {
+	w.drain()
+	w.closeWith(omnivoice.StreamClose{Reason: omnivoice.CloseClient}, false)
+	return nil
+}
+
+// drain asks Deepgram to deliver the results for the audio sent so far and
+// waits for them, until the stream's drain timeout passes or the stream is
+// closed. It does nothing when draining is disabled or the stream is
+// already closed.
+func (w *Stream) drain() {
+	if w.drainTimeout <= 0 {
+		return
+	}
+
+	if err := w.requestClose(); err != nil {
+		if !errors.Is(err, io.ErrClosedPipe) {
+			klog.V(1).Infof("deepgram: failed to request final results: %v", err)
+		}
+		return
+	}
+
+	w.waitDrained()
+}
+
+// waitDrained waits for the results requested with requestClose, until
+// the stream's drain timeout passes or the stream is closed. It returns at
+// once when draining is disabled.
+func (w *Stream) waitDrained() {
+	if w.drainTimeout <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(w.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.drained:
+	case <-w.done:
+	case <-timer.C:
+		klog.V(1).Infof("deepgram: final results not received within %s", w.drainTimeout)
+	}
+}
+
+// requestClose asks Deepgram to deliver the results for the audio sent so
+// far and then close the session. The close that follows is reported as
+// the caller's. It returns io.ErrClosedPipe if the stream is closed.
+func (w *Stream) requestClose() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return io.ErrClosedPipe
+	}
+
+	if err := w.client.WriteJSON(closeStreamMessage); err != nil {
+		return fmt.Errorf("failed to send control message: %w", err)
+	}
+	w.draining = true
+
+	return nil
+}
+
+// isDraining reports whether the caller has asked Deepgram for the final
+// results and the close of the session.
+func (w *Stream) isDraining() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.draining
+}
+
+// closeWith ends the stream, emitting an EventClose event described by info
+// before closing the event channel, and stops the Deepgram client. When the
+// close is reported by the client itself, it is already shutting down and
+// is stopped in the background, so that its callback can return. When the
+// event channel is full, it waits for the reader to make room for the close
+// event for at most the drain timeout, and drops the event after it or once
+// the stream's context is done. Closing an already closed stream does
+// nothing.
+func (w *Stream) closeWith(info omnivoice.StreamClose, fromClient bool) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	info.ProcessedDuration = w.processed
+	w.mu.Unlock()
 
-	// Close channels
+	// Release deliveries waiting on a full channel and wait for them to
+	// return. Nothing else can deliver once closed is set, so the close
+	// event is the last one on the channel
 	close(w.done)
+	w.sending.Lock()
+
+	event := omnivoice.StreamEvent{
+		StreamEvent: stt.StreamEvent{Type: omnivoice.EventClose, Error: info.Err},
+		Close:       &info,
+	}
+	// The close event always ends the stream, so on a full channel wait
+	// for the reader to make room, up to the drain timeout and unless the
+	// session's context is done, then drop it so closing never blocks. A
+	// channel with room takes it even then.
+	sent := false
+	select {
+	case w.eventCh <- event:
+		sent = true
+	default:
+		if w.drainTimeout > 0 {
+			timer := time.NewTimer(w.drainTimeout)
+			select {
+			case w.eventCh <- event:
+				sent = true
+			case <-w.ctx.Done():
+			case <-timer.C:
+			}
+			timer.Stop()
+		}
+	}
+	if !sent {
+		klog.V(1).Info("deepgram: event channel full, dropping the close event")
+	}
+	if sent && w.observer != nil {
+		w.observer(event)
+	}
 	close(w.eventCh)
+	w.sending.Unlock()
+
+	// Stop the Deepgram client outside the lock, since it waits for the
+	// close handshake while callbacks may still be delivering events
+	if fromClient {
+		go w.client.Stop()
+	} else {
+		w.client.Stop()
+	}
+}
 
-	return nil
+// setError records err as the last error Deepgram reported on the stream.
+func (w *Stream) setError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastErr = err
+}
+
+// ProcessedDuration returns the audio Deepgram has transcribed in the
+// session so far, or since the last Reset, the sum of the durations of its final results. Interim
+// results are not counted, since they cover audio again in later results.
+// It is meant for reconciling billing against the audio sent; the total is
+// also reported on the stream's close event.
+func (w *Stream) ProcessedDuration() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.processed
+}
+
+// place records a result starting at start and lasting dur, and returns
+// the offset of the utterance it belongs to. Final results of the current
+// utterance count towards ProcessedDuration.
+func (w *Stream) place(start, dur time.Duration, final, fromFinalize bool) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.end = max(w.end, start+dur)
+	if w.finalizing && fromFinalize {
+		w.finalizing = false
+		w.offset = max(w.offset, start+dur)
+		return w.prevOffset
+	}
+	if final {
+		w.processed += dur
+	}
+	return w.offset
+}
+
+// lastError returns the last error recorded with setError.
+func (w *Stream) lastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// deliver sends event to the session's channel. When the channel is full,
+// it waits for the reader to catch up, holding back Deepgram's later
+// messages, until the stream is closed or its context is done. Events
+// delivered after the stream has been closed are dropped, and only the
+// events that reach the channel are shown to the observer.
+func (w *Stream) deliver(event omnivoice.StreamEvent) {
+	w.sending.RLock()
+	defer w.sending.RUnlock()
+
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return
+	}
+
+	select {
+	case w.eventCh <- event:
+		if w.observer != nil {
+			w.observer(event)
+		}
+	case <-w.done:
+	case <-w.ctx.Done():
+	}
 }
 
 // callbackHandler implements the Deepgram callback interface.
 type callbackHandler struct {
-	eventCh chan stt.StreamEvent
+	stream  *Stream
 	ctx     context.Context
+	convert omnivoice.ConvertOptions
+}
+
+// emit delivers event to the session, recording it as activity.
+func (h *callbackHandler) emit(event omnivoice.StreamEvent) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	touch(h.stream.activity)
+	h.stream.deliver(event)
+	return nil
 }
 
 // Open is called when the connection is established.
@@ -243,11 +1338,16 @@ func (h *callbackHandler) Message(mr *wsinterfaces.MessageResponse) error {
 		return nil
 	}
 
+	start := time.Duration(mr.Start * float64(time.Second))
+	dur := time.Duration(mr.Duration * float64(time.Second))
+	offset := h.stream.place(start, dur, mr.IsFinal, mr.FromFinalize).Seconds()
+
 	// Convert to our internal type
 	result := &omnivoice.MessageResponse{
-		IsFinal:  mr.IsFinal,
-		Duration: mr.Duration,
-		Start:    mr.Start,
+		IsFinal:      mr.IsFinal,
+		FromFinalize: mr.FromFinalize,
+		Duration:     mr.Duration,
+		Start:        mr.Start - offset,
 	}
 
 	// Copy channel data
@@ -262,10 +1362,12 @@ func (h *callbackHandler) Message(mr *wsinterfaces.MessageResponse) error {
 				result.Channel.Alternatives[i].Words = make([]omnivoice.Word, len(alt.Words))
 				for j, w := range alt.Words {
 					word := omnivoice.Word{
-						Word:       w.Word,
-						Start:      w.Start,
-						End:        w.End,
-						Confidence: w.Confidence,
+						Word:           w.Word,
+						PunctuatedWord: w.PunctuatedWord,
+						Start:          w.Start - offset,
+						End:            w.End - offset,
+						Confidence:     w.Confidence,
+						Language:       w.Language,
 					}
 					if w.Speaker != nil {
 						word.Speaker = w.Speaker
@@ -277,60 +1379,67 @@ func (h *callbackHandler) Message(mr *wsinterfaces.MessageResponse) error {
 	}
 
 	// Convert to OmniVoice event
-	event := omnivoice.MessageResponseToStreamEvent(result)
-
-	select {
-	case h.eventCh <- event:
-	case <-h.ctx.Done():
-		return h.ctx.Err()
-	default:
-		// Channel full, drop event
-	}
-
-	return nil
+	return h.emit(omnivoice.MessageResponseToEvent(result, h.convert))
 }
 
 // Metadata is called when metadata is received.
 func (h *callbackHandler) Metadata(md *wsinterfaces.MetadataResponse) error {
-	return nil
+	if md == nil {
+		return nil
+	}
+
+	// Convert to our internal type
+	details := make(map[string]omnivoice.ModelInfo, len(md.ModelInfo))
+	for uuid, info := range md.ModelInfo {
+		details[uuid] = omnivoice.ModelInfo{Name: info.Name, Version: info.Version, Arch: info.Arch}
+	}
+	result := &omnivoice.MetadataResponse{
+		RequestID: md.RequestID,
+		Duration:  md.Duration,
+		Channels:  md.Channels,
+		Models:    md.Models,
+		ModelInfo: details,
+	}
+
+	err := h.emit(omnivoice.MetadataResponseToEvent(result))
+	touch(h.stream.drained)
+	return err
 }
 
 // SpeechStarted is called when speech is detected.
 func (h *callbackHandler) SpeechStarted(ssr *wsinterfaces.SpeechStartedResponse) error {
-	event := stt.StreamEvent{
+	event := omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{
 		Type:          stt.EventSpeechStart,
 		SpeechStarted: true,
-	}
+	}}
 
-	select {
-	case h.eventCh <- event:
-	case <-h.ctx.Done():
-		return h.ctx.Err()
-	default:
-	}
-
-	return nil
+	return h.emit(event)
 }
 
 // UtteranceEnd is called when an utterance ends.
 func (h *callbackHandler) UtteranceEnd(ur *wsinterfaces.UtteranceEndResponse) error {
-	event := stt.StreamEvent{
+	event := omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{
 		Type:        stt.EventSpeechEnd,
 		SpeechEnded: true,
-	}
-
-	select {
-	case h.eventCh <- event:
-	case <-h.ctx.Done():
-		return h.ctx.Err()
-	default:
-	}
+	}}
 
-	return nil
+	return h.emit(event)
 }
 
 // Close is called when the connection is closed.
+//
+// A close the caller did not ask for ends the stream with a CloseServer
+// event carrying the last error Deepgram reported, if any. When the caller
+// closed the stream, it has already ended and this does nothing; when
+// Deepgram closes it after the caller asked for the final results, while
+// Close is waiting for them or at the end of a TranscribeStreamReader
+// source, the close is reported as the caller's.
 func (h *callbackHandler) Close(cr *wsinterfaces.CloseResponse) error {
+	reason := omnivoice.CloseServer
+	if h.stream.isDraining() {
+		reason = omnivoice.CloseClient
+	}
+	h.stream.closeWith(omnivoice.StreamClose{Reason: reason, Err: h.stream.lastError()}, true)
 	return nil
 }
 
@@ -340,30 +1449,34 @@ func (h *callbackHandler) Error(er *wsinterfaces.ErrorResponse) error {
 		return nil
 	}
 
-	event := stt.StreamEvent{
-		Type:  stt.EventError,
-		Error: fmt.Errorf("deepgram error: %s", er.Description),
-	}
+	err := errorf(h.ctx, "deepgram error", errors.New(er.Description))
+	h.stream.setError(err)
 
-	select {
-	case h.eventCh <- event:
-	case <-h.ctx.Done():
-		return h.ctx.Err()
-	default:
-	}
+	event := omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{
+		Type:  stt.EventError,
+		Error: err,
+	}}
 
-	return nil
+	return h.emit(event)
 }
 
-// UnhandledEvent is called for unhandled events.
+// UnhandledEvent is called for unhandled events. Warnings are emitted as
+// EventWarning events; other events are logged and dropped.
 func (h *callbackHandler) UnhandledEvent(raw []byte) error {
-	// Try to parse as a generic message for debugging
-	var msg map[string]interface{}
-	if err := json.Unmarshal(raw, &msg); err == nil {
-		// Log unhandled event type if present
-		if msgType, ok := msg["type"].(string); ok {
-			_ = msgType // Could log this for debugging
+	warning, ok := omnivoice.ParseWarning(raw)
+	if !ok {
+		var msg struct {
+			Type string `json:"type"`
 		}
+		_ = json.Unmarshal(raw, &msg)
+		klog.V(3).Infof("deepgram: unhandled stream event %q: %s", msg.Type, raw)
+		return nil
 	}
-	return nil
+
+	event := omnivoice.StreamEvent{
+		StreamEvent: stt.StreamEvent{Type: omnivoice.EventWarning},
+		Warning:     warning,
+	}
+
+	return h.emit(event)
 }
@@ -0,0 +1,1908 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+	deepgramtts "github.com/plexusone/omnivoice-deepgram/omnivoice/tts"
+)
+
+// fakeClient is an in-memory DeepgramClient used to observe what the
+// stream sends over the connection.
+type fakeClient struct {
+	mu        sync.Mutex
+	written   [][]byte
+	controls  []string
+	finalizes int
+	stops     int
+
+	// failWrites is the number of upcoming writes that fail
+	failWrites int
+	attempts   int
+}
+
+// errWriteBlip is returned by fakeClient for a failed write.
+var errWriteBlip = errors.New("write: broken pipe")
+
+func (c *fakeClient) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.failWrites > 0 {
+		c.failWrites--
+		return 0, errWriteBlip
+	}
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	c.written = append(c.written, buf)
+	return len(p), nil
+}
+
+func (c *fakeClient) WriteJSON(payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.controls = append(c.controls, string(data))
+	return nil
+}
+
+func (c *fakeClient) Finalize() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finalizes++
+	return nil
+}
+
+func (c *fakeClient) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stops++
+}
+
+// newTestStream creates a Stream backed by the given fake client.
+func newTestStream(client DeepgramClient) *Stream {
+	return &Stream{
+		client:  client,
+		eventCh: make(chan omnivoice.StreamEvent, 100),
+		ctx:     context.Background(),
+		done:    make(chan struct{}),
+	}
+}
+
+func TestStream_ResetReusesConnection(t *testing.T) {
+	fake := &fakeClient{}
+	s := newTestStream(fake)
+
+	if _, err := s.Write([]byte("first")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if _, err := s.Write([]byte("second")); err != nil {
+		t.Fatalf("Write() after Reset() error = %v", err)
+	}
+
+	if fake.finalizes != 1 {
+		t.Errorf("Finalize called %d times, want 1", fake.finalizes)
+	}
+	if fake.stops != 0 {
+		t.Errorf("Stop called %d times, want 0 (connection should be reused)", fake.stops)
+	}
+	if len(fake.written) != 2 {
+		t.Fatalf("client received %d writes, want 2", len(fake.written))
+	}
+	if string(fake.written[1]) != "second" {
+		t.Errorf("second write = %q, want %q", fake.written[1], "second")
+	}
+}
+
+func TestStream_ResetClearsUtteranceState(t *testing.T) {
+	s := newTestStream(&fakeClient{})
+	handler := &callbackHandler{stream: s, ctx: context.Background()}
+
+	message := func(start, duration float64, fromFinalize bool) *wsinterfaces.MessageResponse {
+		return &wsinterfaces.MessageResponse{
+			IsFinal:      true,
+			FromFinalize: fromFinalize,
+			Start:        start,
+			Duration:     duration,
+			Channel: wsinterfaces.Channel{Alternatives: []wsinterfaces.Alternative{{
+				Transcript: "hello",
+				Words:      []wsinterfaces.Word{{Word: "hello", Start: start + 0.5, End: start + 1}},
+			}}},
+		}
+	}
+
+	_ = handler.Message(message(0, 2, false))
+	s.setError(errors.New("boom"))
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	if got := s.ProcessedDuration(); got != 0 {
+		t.Errorf("ProcessedDuration() after Reset() = %v, want 0", got)
+	}
+	if err := s.lastError(); err != nil {
+		t.Errorf("last error after Reset() = %v, want nil", err)
+	}
+
+	// The finalize result still belongs to the first utterance.
+	_ = handler.Message(message(2, 1, true))
+	// The next utterance is reported relative to its own start.
+	_ = handler.Message(message(3, 1, false))
+
+	<-s.eventCh
+	finalized, next := <-s.eventCh, <-s.eventCh
+	if got := finalized.Words[0].StartTime; got != 2500*time.Millisecond {
+		t.Errorf("finalize result word start = %v, want 2.5s", got)
+	}
+	if got := next.Words[0].StartTime; got != 500*time.Millisecond {
+		t.Errorf("next utterance word start = %v, want 500ms", got)
+	}
+	if got, want := next.Words[0].ID, omnivoice.WordID(500*time.Millisecond); got != want {
+		t.Errorf("next utterance word ID = %q, want %q", got, want)
+	}
+	if got := s.ProcessedDuration(); got != time.Second {
+		t.Errorf("ProcessedDuration() = %v, want 1s", got)
+	}
+}
+
+func TestStream_ResetAfterClose(t *testing.T) {
+	s := newTestStream(&fakeClient{})
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := s.Reset(); err != io.ErrClosedPipe {
+		t.Errorf("Reset() after Close() error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+func TestStream_Finalize(t *testing.T) {
+	fake := &fakeClient{}
+	s := newTestStream(fake)
+
+	if _, err := s.Write([]byte("audio")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := s.Finalize(); err != nil {
+		t.Fatalf("Finalize() error = %v", err)
+	}
+
+	if fake.finalizes != 1 {
+		t.Errorf("Finalize control message sent %d times, want 1", fake.finalizes)
+	}
+	if fake.stops != 0 {
+		t.Errorf("Stop called %d times, want 0", fake.stops)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := s.Finalize(); err != io.ErrClosedPipe {
+		t.Errorf("Finalize() after Close() error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+func TestStream_CloseEventOnFullChannel(t *testing.T) {
+	s := newTestStream(&fakeClient{})
+	s.drainTimeout = 500 * time.Millisecond
+	for i := 0; i < cap(s.eventCh); i++ {
+		s.deliver(omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{Type: stt.EventTranscript}})
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	var events []omnivoice.StreamEvent
+	for event := range s.eventCh {
+		events = append(events, event)
+	}
+	if err := <-closed; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(events) != cap(s.eventCh)+1 {
+		t.Fatalf("received %d events, want %d", len(events), cap(s.eventCh)+1)
+	}
+	if last := events[len(events)-1]; last.Type != omnivoice.EventClose {
+		t.Errorf("last event = %v, want %v", last.Type, omnivoice.EventClose)
+	}
+}
+
+func TestStream_CloseWithoutReader(t *testing.T) {
+	s := newTestStream(&fakeClient{})
+	s.drainTimeout = 10 * time.Millisecond
+	for i := 0; i < cap(s.eventCh); i++ {
+		s.deliver(omnivoice.StreamEvent{StreamEvent: stt.StreamEvent{Type: stt.EventTranscript}})
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() blocked on a full event channel")
+	}
+
+	n := 0
+	for event := range s.eventCh {
+		if event.Type == omnivoice.EventClose {
+			t.Error("close event delivered on a full channel")
+		}
+		n++
+	}
+	if n != cap(s.eventCh) {
+		t.Errorf("received %d events, want %d", n, cap(s.eventCh))
+	}
+}
+
+func TestStream_WriteFloat32(t *testing.T) {
+	fake := &fakeClient{}
+	s := newTestStream(fake)
+
+	samples := []float32{0, 0.5, -0.5, 1, -1, 1.5, -2}
+	n, err := s.WriteFloat32(samples)
+	if err != nil {
+		t.Fatalf("WriteFloat32() error = %v", err)
+	}
+	if n != len(samples) {
+		t.Errorf("WriteFloat32() = %d, want %d", n, len(samples))
+	}
+
+	// Manual conversion: clamp to [-1, 1], scale to int16, little-endian
+	want := make([]byte, 0, 2*len(samples))
+	for _, f := range samples {
+		f = max(-1, min(1, f))
+		want = binary.LittleEndian.AppendUint16(want, uint16(int16(f*32767)))
+	}
+
+	if len(fake.written) != 1 {
+		t.Fatalf("client received %d writes, want 1", len(fake.written))
+	}
+	if !bytes.Equal(fake.written[0], want) {
+		t.Errorf("written = %v, want %v", fake.written[0], want)
+	}
+}
+
+func TestOpenStream_ConcurrentSessions(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Each dial gets its own fake connection; remember the handler so the
+	// test can play the server side of every session.
+	var (
+		mu       sync.Mutex
+		sessions = map[string]*fakeClient{}
+		handlers = map[string]wsinterfaces.LiveMessageCallback{}
+	)
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, handler wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		id := opts.Tag[0]
+		fake := &fakeClient{}
+		mu.Lock()
+		sessions[id], handlers[id] = fake, handler
+		mu.Unlock()
+		return fake, nil
+	}
+
+	const n = 8
+	streams := make([]*Stream, n)
+	events := make([]<-chan omnivoice.StreamEvent, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := omnivoice.WithCorrelationID(context.Background(), "session-"+string(rune('a'+i)))
+			s, ev, err := p.OpenStream(ctx, stt.TranscriptionConfig{})
+			if err != nil {
+				t.Errorf("OpenStream() error = %v", err)
+				return
+			}
+			streams[i], events[i] = s, ev
+		}()
+	}
+	wg.Wait()
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := "session-" + string(rune('a'+i))
+
+			if _, err := streams[i].Write([]byte(id)); err != nil {
+				t.Errorf("%s: Write() error = %v", id, err)
+			}
+
+			mu.Lock()
+			handler := handlers[id]
+			mu.Unlock()
+			_ = handler.Message(&wsinterfaces.MessageResponse{
+				IsFinal: true,
+				Channel: wsinterfaces.Channel{Alternatives: []wsinterfaces.Alternative{{Transcript: id}}},
+			})
+
+			if event := <-events[i]; event.Transcript != id {
+				t.Errorf("%s received transcript %q", id, event.Transcript)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id, fake := range sessions {
+		if len(fake.written) != 1 || string(fake.written[0]) != id {
+			t.Errorf("%s connection received %q", id, fake.written)
+		}
+	}
+
+	for _, s := range streams {
+		_ = s.Close()
+	}
+	for id, fake := range sessions {
+		if fake.stops != 1 {
+			t.Errorf("%s connection stopped %d times, want 1", id, fake.stops)
+		}
+	}
+}
+
+func TestTranscribeStreamText_FinalsOnly(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	w, texts, err := p.TranscribeStreamText(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("TranscribeStreamText() error = %v", err)
+	}
+
+	message := func(transcript string, final bool) *wsinterfaces.MessageResponse {
+		return &wsinterfaces.MessageResponse{
+			IsFinal: final,
+			Channel: wsinterfaces.Channel{Alternatives: []wsinterfaces.Alternative{{Transcript: transcript}}},
+		}
+	}
+
+	_ = handler.SpeechStarted(&wsinterfaces.SpeechStartedResponse{})
+	_ = handler.Message(message("hel", false))
+	_ = handler.Message(message("hello there", true))
+	_ = handler.Message(message("", true))
+	_ = handler.UtteranceEnd(&wsinterfaces.UtteranceEndResponse{})
+	_ = handler.Message(message("general", false))
+	_ = handler.Message(message("general kenobi", true))
+	_ = w.Close()
+
+	var got []string
+	for text := range texts {
+		got = append(got, text)
+	}
+
+	want := []string{"hello there", "general kenobi"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("texts = %q, want %q", got, want)
+	}
+}
+
+func TestTranscribe_CorrelationID(t *testing.T) {
+	var gotTags []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTags = r.URL.Query()["tag"]
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"err_code":"Bad Request","err_msg":"corrupt audio"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := omnivoice.WithCorrelationID(context.Background(), "trace-123")
+	_, err = p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{})
+	if err == nil {
+		t.Fatal("Transcribe() expected error from server")
+	}
+
+	if len(gotTags) != 1 || gotTags[0] != "trace-123" {
+		t.Errorf("request tags = %v, want [trace-123]", gotTags)
+	}
+	if !strings.Contains(err.Error(), "correlation_id=trace-123") {
+		t.Errorf("error %q does not contain correlation ID", err)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	base := io.ErrUnexpectedEOF
+
+	err := errorf(context.Background(), "request failed", base)
+	if got, want := err.Error(), "request failed: unexpected EOF"; got != want {
+		t.Errorf("errorf() without ID = %q, want %q", got, want)
+	}
+
+	ctx := omnivoice.WithCorrelationID(context.Background(), "abc")
+	err = errorf(ctx, "request failed", base)
+	if got, want := err.Error(), "request failed [correlation_id=abc]: unexpected EOF"; got != want {
+		t.Errorf("errorf() with ID = %q, want %q", got, want)
+	}
+	if !errors.Is(err, base) {
+		t.Error("errorf() should wrap the original error")
+	}
+}
+
+// pcmWAV returns a 16 kHz mono 16-bit PCM WAV stream of the given duration.
+func pcmWAV(d time.Duration) []byte {
+	const rate = 16000
+	dataSize := int(d.Seconds() * rate * 2)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), uint32(rate), uint32(rate * 2), uint16(2), uint16(16)} {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+// unreachableServer fails the test if any request reaches Deepgram.
+func unreachableServer(t *testing.T) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+}
+
+func TestTranscribe_MaxAudioDuration(t *testing.T) {
+	unreachableServer(t)
+
+	p, err := New(WithAPIKey("test-key"), WithMaxAudioDuration(time.Second))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.Transcribe(context.Background(), pcmWAV(3*time.Second), stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrAudioTooLong) {
+		t.Fatalf("Transcribe() error = %v, want ErrAudioTooLong", err)
+	}
+	if !strings.Contains(err.Error(), "3s exceeds maximum of 1s") {
+		t.Errorf("Transcribe() error = %q, want durations in message", err)
+	}
+}
+
+func TestTranscribe_MaxAudioDurationPlaceholderLength(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithMaxAudioDuration(2*time.Second))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Streaming encoders write a placeholder data length, which would
+	// otherwise read as hours of audio
+	for _, placeholder := range []uint32{0xFFFFFFFF, 0} {
+		short := pcmWAV(time.Second)
+		binary.LittleEndian.PutUint32(short[40:44], placeholder)
+		if _, err := p.Transcribe(context.Background(), short, stt.TranscriptionConfig{}); err != nil {
+			t.Errorf("Transcribe() with data length %#x error = %v, want nil", placeholder, err)
+		}
+
+		long := pcmWAV(3 * time.Second)
+		binary.LittleEndian.PutUint32(long[40:44], placeholder)
+		if _, err := p.Transcribe(context.Background(), long, stt.TranscriptionConfig{}); !errors.Is(err, stt.ErrAudioTooLong) {
+			t.Errorf("Transcribe() of 3s with data length %#x error = %v, want ErrAudioTooLong", placeholder, err)
+		}
+	}
+}
+
+func TestTranscribeFile_MaxAudioDuration(t *testing.T) {
+	unreachableServer(t)
+
+	path := filepath.Join(t.TempDir(), "long.wav")
+	if err := os.WriteFile(path, pcmWAV(3*time.Second), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p, err := New(WithAPIKey("test-key"), WithMaxAudioDuration(time.Second))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.TranscribeFile(context.Background(), path, stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrAudioTooLong) {
+		t.Fatalf("TranscribeFile() error = %v, want ErrAudioTooLong", err)
+	}
+}
+
+func TestTranscribeURL_MaxAudioDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc","duration":90.5},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithMaxAudioDuration(time.Minute))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.TranscribeURL(context.Background(), "https://example.com/long.wav", stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrAudioTooLong) {
+		t.Fatalf("TranscribeURL() error = %v, want ErrAudioTooLong", err)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"duration":1.0},"results":{"channels":[{"alternatives":[{"transcript":"Hello world."}]}]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", "")
+
+	p, err := New(WithAPIKey("test-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.Transcribe(context.Background(), pcmWAV(time.Second), stt.TranscriptionConfig{}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests to base URL = %d, want 1", requests)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if got := p.endpoint.WSOptions().Host; got != "ws://"+host {
+		t.Errorf("streaming Host = %q, want %q", got, "ws://"+host)
+	}
+	req, err := p.BuildRequest(stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+	if !strings.HasPrefix(req.LiveURL, "ws://"+host+"/") {
+		t.Errorf("LiveURL = %q, want it on %s", req.LiveURL, host)
+	}
+
+	for _, bad := range []string{"", "dg.internal", "https://dg.internal/v1"} {
+		if _, err := New(WithAPIKey("test-key"), WithBaseURL(bad)); err == nil {
+			t.Errorf("New(WithBaseURL(%q)) error = nil, want an error", bad)
+		}
+	}
+}
+
+func TestTranscribeText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"duration":1.0},"results":{"channels":[{"alternatives":[
+			{"transcript":"Hello world.","confidence":0.98},
+			{"transcript":"Hollow word.","confidence":0.41}
+		]}]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.wav")
+	if err := os.WriteFile(path, pcmWAV(time.Second), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		transcribe func() (string, error)
+	}{
+		{"TranscribeText", func() (string, error) { return p.TranscribeText(ctx, pcmWAV(time.Second), stt.TranscriptionConfig{}) }},
+		{"TranscribeFileText", func() (string, error) { return p.TranscribeFileText(ctx, path, stt.TranscriptionConfig{}) }},
+		{"TranscribeURLText", func() (string, error) {
+			return p.TranscribeURLText(ctx, "https://example.com/hello.wav", stt.TranscriptionConfig{})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.transcribe()
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if text != "Hello world." {
+				t.Errorf("%s() = %q, want %q", tt.name, text, "Hello world.")
+			}
+		})
+	}
+}
+
+func TestTranscribeText_Error(t *testing.T) {
+	unreachableServer(t)
+
+	p, err := New(WithAPIKey("test-key"), WithMaxAudioDuration(time.Second))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text, err := p.TranscribeText(context.Background(), pcmWAV(3*time.Second), stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrAudioTooLong) {
+		t.Fatalf("TranscribeText() error = %v, want ErrAudioTooLong", err)
+	}
+	if text != "" {
+		t.Errorf("TranscribeText() = %q on error, want empty", text)
+	}
+}
+
+func TestTranscribe_TruncatedWAV(t *testing.T) {
+	unreachableServer(t)
+
+	wav := pcmWAV(time.Second)
+	truncated := wav[:len(wav)/2]
+	path := filepath.Join(t.TempDir(), "truncated.wav")
+	if err := os.WriteFile(path, truncated, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.Transcribe(context.Background(), truncated, stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrInvalidAudio) || !errors.Is(err, omnivoice.ErrTruncatedWAV) {
+		t.Fatalf("Transcribe() error = %v, want ErrInvalidAudio and ErrTruncatedWAV", err)
+	}
+	if !strings.Contains(err.Error(), "declares 32000 bytes but only 15978 are present") {
+		t.Errorf("Transcribe() error = %q, want sizes in message", err)
+	}
+
+	_, err = p.TranscribeFile(context.Background(), path, stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrInvalidAudio) {
+		t.Errorf("TranscribeFile() error = %v, want ErrInvalidAudio", err)
+	}
+}
+
+func TestTranscribe_EncodingAutoDetect(t *testing.T) {
+	var gotContentType, gotEncoding, gotSampleRate string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotEncoding = r.URL.Query().Get("encoding")
+		gotSampleRate = r.URL.Query().Get("sample_rate")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithEncodingAutoDetect(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	config := stt.TranscriptionConfig{Encoding: "pcm", SampleRate: 16000}
+
+	tests := []struct {
+		name            string
+		audio           []byte
+		wantContentType string
+		wantEncoding    string
+		wantSampleRate  string
+	}{
+		{"wav", pcmWAV(time.Second), "audio/wav", "", ""},
+		{"mp3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00frames"), "audio/mpeg", "", ""},
+		{"flac", []byte("fLaC\x00\x00\x00\x22streaminfo"), "audio/flac", "", ""},
+		{"raw pcm", make([]byte, 320), "", "linear16", "16000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.Transcribe(context.Background(), tt.audio, config); err != nil {
+				t.Fatalf("Transcribe() error = %v", err)
+			}
+			if gotContentType != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", gotContentType, tt.wantContentType)
+			}
+			if gotEncoding != tt.wantEncoding || gotSampleRate != tt.wantSampleRate {
+				t.Errorf("encoding = %q/%q, want %q/%q", gotEncoding, gotSampleRate, tt.wantEncoding, tt.wantSampleRate)
+			}
+		})
+	}
+}
+
+func TestTranscribeSource_DebugWords(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"duration":0.5},"results":{"channels":[{"alternatives":[{
+			"transcript":"hi","words":[{"word":"hi","punctuated_word":"Hi.","start":0.1,"end":0.3,"confidence":0.9,"language":"en"}]
+		}]}]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	for _, enabled := range []bool{false, true} {
+		p, err := New(WithAPIKey("test-key"), WithDebugWords(enabled))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		result, err := p.TranscribeSource(context.Background(), Source{Audio: []byte("audio")}, stt.TranscriptionConfig{})
+		if err != nil {
+			t.Fatalf("TranscribeSource() error = %v", err)
+		}
+		if got := len(result.DebugWords) > 0; got != enabled {
+			t.Errorf("WithDebugWords(%v): DebugWords = %v", enabled, result.DebugWords)
+		}
+		if enabled && result.DebugWords[0].PunctuatedWord != "Hi." {
+			t.Errorf("DebugWords[0].PunctuatedWord = %q, want %q", result.DebugWords[0].PunctuatedWord, "Hi.")
+		}
+	}
+}
+
+func TestTranscribeSource_MultipleSources(t *testing.T) {
+	unreachableServer(t)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.TranscribeSource(context.Background(), Source{Audio: []byte("a"), URL: "https://example.com/a.wav"}, stt.TranscriptionConfig{})
+	if !errors.Is(err, stt.ErrInvalidConfig) {
+		t.Errorf("TranscribeSource() error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestBatch_Silence(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"duration":2.0},"results":{"channels":[{"alternatives":[{"transcript":"","words":[]}]}],"utterances":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	path := filepath.Join(t.TempDir(), "silence.wav")
+	if err := os.WriteFile(path, pcmWAV(2*time.Second), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	config := stt.TranscriptionConfig{}
+
+	batch := map[string]func() (*stt.TranscriptionResult, error){
+		"Transcribe":     func() (*stt.TranscriptionResult, error) { return p.Transcribe(ctx, pcmWAV(2*time.Second), config) },
+		"TranscribeFile": func() (*stt.TranscriptionResult, error) { return p.TranscribeFile(ctx, path, config) },
+		"TranscribeURL": func() (*stt.TranscriptionResult, error) {
+			return p.TranscribeURL(ctx, "https://example.com/silence.wav", config)
+		},
+	}
+
+	for name, transcribe := range batch {
+		t.Run(name, func(t *testing.T) {
+			result, err := transcribe()
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if result == nil {
+				t.Fatalf("%s() returned nil result with nil error", name)
+			}
+			if result.Text != "" || result.Segments != nil {
+				t.Errorf("%s() = %+v, want empty Text and nil Segments", name, result)
+			}
+		})
+	}
+}
+
+func TestBatch_ZeroLengthAudio(t *testing.T) {
+	unreachableServer(t)
+
+	path := filepath.Join(t.TempDir(), "empty.wav")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	for name, audio := range map[string][]byte{"nil": nil, "empty": {}} {
+		result, err := p.Transcribe(ctx, audio, stt.TranscriptionConfig{})
+		if err != nil || result == nil || result.Text != "" || result.Segments != nil {
+			t.Errorf("Transcribe(%s) = %+v, %v, want empty result", name, result, err)
+		}
+	}
+
+	result, err := p.TranscribeFile(ctx, path, stt.TranscriptionConfig{})
+	if err != nil || result == nil || result.Text != "" || result.Segments != nil {
+		t.Errorf("TranscribeFile(empty) = %+v, %v, want empty result", result, err)
+	}
+}
+
+func TestWithDefaultModel(t *testing.T) {
+	var gotModels []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotModels = append(gotModels, r.URL.Query().Get("model"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1), WithDefaultModel("nova-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var liveModel string
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		liveModel = opts.Model
+		return &fakeClient{}, nil
+	}
+
+	ctx := context.Background()
+	if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{Model: "nova-2-phonecall"}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	s, _, err := p.OpenStream(ctx, stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	_ = s.Close()
+
+	if want := []string{"nova-3", "nova-2-phonecall"}; strings.Join(gotModels, ",") != strings.Join(want, ",") {
+		t.Errorf("batch models = %v, want %v", gotModels, want)
+	}
+	if liveModel != "nova-3" {
+		t.Errorf("streaming model = %q, want nova-3", liveModel)
+	}
+}
+
+// modelServer rejects every model in reject with Deepgram's 400 model error,
+// answers other models with an empty transcript, and records the models
+// requested.
+func modelServer(t *testing.T, status int, reject ...string) *[]string {
+	t.Helper()
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		model := r.URL.Query().Get("model")
+		models = append(models, model)
+		if slices.Contains(reject, model) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"err_code":"Bad Request","err_msg":"No such model/language/tier combination found."}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+	return &models
+}
+
+func TestTranscribe_ModelFallback(t *testing.T) {
+	models := modelServer(t, http.StatusBadRequest, "nova-3")
+
+	p, err := New(WithAPIKey("test-key"), WithModelFallback([]string{"nova-3", "nova-2"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.Transcribe(context.Background(), []byte("audio"), stt.TranscriptionConfig{Model: "nova-3"}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if want := []string{"nova-3", "nova-2"}; !slices.Equal(*models, want) {
+		t.Errorf("requested models = %q, want %q", *models, want)
+	}
+}
+
+func TestTranscribe_ModelFallbackExhausted(t *testing.T) {
+	models := modelServer(t, http.StatusBadRequest, "nova-3", "nova-2")
+
+	p, err := New(WithAPIKey("test-key"), WithModelFallback([]string{"nova-2"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.Transcribe(context.Background(), []byte("audio"), stt.TranscriptionConfig{Model: "nova-3"})
+	if !omnivoice.IsModelError(err) {
+		t.Fatalf("Transcribe() error = %v, want model error", err)
+	}
+	if len(*models) != 2 {
+		t.Errorf("requested models = %q, want 2 attempts", *models)
+	}
+}
+
+func TestTranscribe_ModelFallbackSkipsAuthError(t *testing.T) {
+	models := modelServer(t, http.StatusUnauthorized, "nova-3")
+
+	p, err := New(WithAPIKey("test-key"), WithModelFallback([]string{"nova-2"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.Transcribe(context.Background(), []byte("audio"), stt.TranscriptionConfig{Model: "nova-3"}); err == nil {
+		t.Fatal("Transcribe() expected error from server")
+	}
+	if want := []string{"nova-3"}; !slices.Equal(*models, want) {
+		t.Errorf("requested models = %q, want %q", *models, want)
+	}
+}
+
+func TestOpenStream_InvalidEncoding(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	p.dial = func(context.Context, *interfaces.LiveTranscriptionOptions, wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		t.Error("dial called for an invalid encoding")
+		return nil, errConnect
+	}
+
+	_, _, err = p.OpenStream(context.Background(), stt.TranscriptionConfig{Encoding: "mulw"})
+	if !errors.Is(err, stt.ErrInvalidConfig) {
+		t.Fatalf("OpenStream() error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestTranscribe_CircuitBreaker(t *testing.T) {
+	var requests int
+	var healthy bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithCircuitBreaker(2, 50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); err == nil || errors.Is(err, omnivoice.ErrCircuitOpen) {
+			t.Fatalf("Transcribe() error = %v, want server error", err)
+		}
+	}
+	if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); !errors.Is(err, omnivoice.ErrCircuitOpen) {
+		t.Fatalf("Transcribe() error = %v, want ErrCircuitOpen", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 while open", requests)
+	}
+
+	healthy = true
+	time.Sleep(60 * time.Millisecond)
+	if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); err != nil {
+		t.Fatalf("Transcribe() after reset error = %v", err)
+	}
+	if _, err := p.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); err != nil {
+		t.Fatalf("Transcribe() after recovery error = %v", err)
+	}
+}
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithSharedClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/speak") {
+			w.Header().Set("char-count", "5")
+			_, _ = w.Write([]byte("audio"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	transport := &countingTransport{}
+	shared := &http.Client{Transport: transport}
+
+	sttProvider, err := New(WithAPIKey("test-key"), WithSharedClient(shared))
+	if err != nil {
+		t.Fatalf("stt New() error = %v", err)
+	}
+	ttsProvider, err := deepgramtts.New(deepgramtts.WithAPIKey("test-key"), deepgramtts.WithSharedClient(shared))
+	if err != nil {
+		t.Fatalf("tts New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := sttProvider.Transcribe(ctx, []byte("audio"), stt.TranscriptionConfig{}); err != nil {
+				t.Errorf("Transcribe() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := ttsProvider.Synthesize(ctx, "Hello", tts.SynthesisConfig{}); err != nil {
+				t.Errorf("Synthesize() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sttProvider.httpClient != shared {
+		t.Error("STT provider does not hold the shared client")
+	}
+	if transport.requests != 8 {
+		t.Errorf("shared transport saw %d requests, want 8 from both providers", transport.requests)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Setenv("DEEPGRAM_HOST", "")
+
+	var paths []string
+	hc := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"metadata":{"request_id":"abc","duration":1.5},"results":{"channels":[{"alternatives":[{"transcript":"hello world"}]}]}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+
+	p, err := New(WithAPIKey("test-key"), WithHTTPClient(hc))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Transcribe(context.Background(), []byte("audio"), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if result.Text != "hello world" {
+		t.Errorf("Text = %q, want the intercepted transcript", result.Text)
+	}
+	if len(paths) != 1 || !strings.HasPrefix(paths[0], "/v1/listen") {
+		t.Errorf("intercepted paths = %q, want one /v1/listen request", paths)
+	}
+}
+
+// TestTranscribe_Concurrent checks that batch calls overlap in time rather
+// than queueing behind one another: the server holds every request until
+// all of them have arrived.
+func TestTranscribe_Concurrent(t *testing.T) {
+	const calls = 8
+	arrived := make(chan struct{}, calls)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Transcribe(context.Background(), []byte("audio"), stt.TranscriptionConfig{}); err != nil {
+				t.Errorf("Transcribe() error = %v", err)
+			}
+		}()
+	}
+
+	// Every request reaches the server before any is answered
+	for i := range calls {
+		select {
+		case <-arrived:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%d of %d requests in flight, want all at once", i, calls)
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+// roundTripFunc is an http.RoundTripper that answers requests itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestOpenStream_Warning(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	_ = handler.UnhandledEvent([]byte(`{"type":"Warning","variant":"DEPRECATED_PARAMETER","description":"The 'tier' parameter is deprecated."}`))
+	_ = handler.UnhandledEvent([]byte(`{"type":"SomethingNew","value":1}`))
+	_ = handler.UnhandledEvent([]byte(`not json`))
+	_ = stream.Close()
+
+	var warnings []*omnivoice.Warning
+	for event := range events {
+		if event.Type == omnivoice.EventClose {
+			continue
+		}
+		if event.Type != omnivoice.EventWarning {
+			t.Errorf("unexpected %q event", event.Type)
+			continue
+		}
+		warnings = append(warnings, event.Warning)
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(warnings))
+	}
+	if w := warnings[0]; w.Code != "DEPRECATED_PARAMETER" || w.Message != "The 'tier' parameter is deprecated." {
+		t.Errorf("warning = %+v", *w)
+	}
+}
+
+func TestOpenStream_Metadata(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	_ = handler.Metadata(&wsinterfaces.MetadataResponse{
+		RequestID: "c2f1a9e0",
+		Duration:  1.5,
+		Models:    []string{"1abfe86b"},
+		ModelInfo: map[string]wsinterfaces.ModelInfo{
+			"1abfe86b": {Name: "general-nova-3", Version: "2024-12-20.0", Arch: "nova-3"},
+		},
+	})
+	_ = stream.Close()
+
+	var metadata []*omnivoice.Metadata
+	for event := range events {
+		if event.Type == omnivoice.EventMetadata {
+			metadata = append(metadata, event.Metadata)
+		}
+	}
+
+	if len(metadata) != 1 {
+		t.Fatalf("got %d metadata events, want 1", len(metadata))
+	}
+	md := metadata[0]
+	if md.RequestID != "c2f1a9e0" || md.Duration != 1500*time.Millisecond {
+		t.Errorf("metadata = %+v", *md)
+	}
+	if len(md.Models) != 1 || md.Models[0].Name != "general-nova-3" {
+		t.Errorf("Models = %+v, want general-nova-3", md.Models)
+	}
+}
+
+func TestStream_SendControl(t *testing.T) {
+	fake := &fakeClient{}
+	stream := newTestStream(fake)
+
+	if err := stream.SendControl(json.RawMessage(`{"type":"KeepAlive"}`)); err != nil {
+		t.Fatalf("SendControl() error = %v", err)
+	}
+	for _, msg := range []string{`["KeepAlive"]`, `"KeepAlive"`, `null`, `{"type":`} {
+		if err := stream.SendControl(json.RawMessage(msg)); !errors.Is(err, ErrInvalidControlMessage) {
+			t.Errorf("SendControl(%s) error = %v, want ErrInvalidControlMessage", msg, err)
+		}
+	}
+
+	if len(fake.controls) != 1 || fake.controls[0] != `{"type":"KeepAlive"}` {
+		t.Errorf("forwarded controls = %q, want the KeepAlive message only", fake.controls)
+	}
+
+	_ = stream.Close()
+	if err := stream.SendControl(json.RawMessage(`{"type":"KeepAlive"}`)); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("SendControl() after Close error = %v, want io.ErrClosedPipe", err)
+	}
+}
+
+func TestTranscribeSource_ProcessingDuration(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"metadata":{"request_id":"abc"},"results":{"channels":[]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	if err := os.WriteFile(path, pcmWAV(time.Second), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for name, src := range map[string]Source{
+		"audio": {Audio: []byte("audio")},
+		"file":  {File: path},
+		"url":   {URL: "https://example.com/audio.wav"},
+	} {
+		result, err := p.TranscribeSource(context.Background(), src, stt.TranscriptionConfig{})
+		if err != nil {
+			t.Fatalf("%s: TranscribeSource() error = %v", name, err)
+		}
+		if result.ProcessingDuration < delay {
+			t.Errorf("%s: ProcessingDuration = %v, want at least %v", name, result.ProcessingDuration, delay)
+		}
+	}
+
+	result, err := p.TranscribeSource(context.Background(), Source{Audio: []byte{}}, stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("empty audio: TranscribeSource() error = %v", err)
+	}
+	if result.ProcessingDuration != 0 {
+		t.Errorf("empty audio: ProcessingDuration = %v, want 0", result.ProcessingDuration)
+	}
+}
+
+func TestOpenStream_IdleTimeout(t *testing.T) {
+	const idle = 100 * time.Millisecond
+
+	p, err := New(WithAPIKey("test-key"), WithIdleTimeout(idle))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return fake, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	opened := time.Now()
+
+	// Alternating writes and events keep the session open past the window
+	for i := range 6 {
+		time.Sleep(idle / 3)
+		if i%2 == 0 {
+			if _, err := stream.Write([]byte{0, 0}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+		} else {
+			_ = handler.SpeechStarted(nil)
+		}
+	}
+	active := time.Since(opened)
+
+	var received []omnivoice.StreamEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	closed := time.Since(opened)
+
+	if closed < active+idle {
+		t.Errorf("stream closed after %v, want at least %v", closed, active+idle)
+	}
+	if len(received) < 2 {
+		t.Fatalf("got %d events, want an error and a close", len(received))
+	}
+	if event := received[len(received)-2]; event.Type != stt.EventError || !errors.Is(event.Error, ErrIdleTimeout) {
+		t.Errorf("second to last event = %+v, want EventError with ErrIdleTimeout", event)
+	}
+	if last := received[len(received)-1]; last.Type != omnivoice.EventClose || last.Close == nil ||
+		last.Close.Reason != omnivoice.CloseIdle || !errors.Is(last.Close.Err, ErrIdleTimeout) {
+		t.Errorf("last event = %+v, want an idle EventClose with ErrIdleTimeout", last)
+	}
+	if _, err := stream.Write([]byte{0, 0}); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Write() after idle close error = %v, want io.ErrClosedPipe", err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.stops != 1 {
+		t.Errorf("client stopped %d times, want 1", fake.stops)
+	}
+}
+
+func TestOpenStream_KeepAlive(t *testing.T) {
+	const interval = 20 * time.Millisecond
+
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1), WithKeepAlive(interval))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return fake, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	controls := func() []string {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return append([]string(nil), fake.controls...)
+	}
+
+	// Audio written more often than the interval holds the keep-alives off
+	for range 6 {
+		if _, err := stream.Write([]byte{0, 0}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		time.Sleep(interval / 4)
+	}
+	if got := controls(); len(got) != 0 {
+		t.Errorf("sent %v while audio was flowing, want no keep-alives", got)
+	}
+
+	time.Sleep(5 * interval)
+	sent := controls()
+	if len(sent) < 2 {
+		t.Fatalf("sent %d control messages while idle, want at least 2", len(sent))
+	}
+	for _, msg := range sent {
+		if msg != `{"type":"KeepAlive"}` {
+			t.Errorf("control message = %s, want a KeepAlive", msg)
+		}
+	}
+
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for range events {
+	}
+	closed := len(controls())
+	time.Sleep(5 * interval)
+	if got := len(controls()); got != closed {
+		t.Errorf("sent %d keep-alives after Close, want none", got-closed)
+	}
+}
+
+func TestWithKeepAlive_Default(t *testing.T) {
+	var o options
+	WithKeepAlive(0)(&o)
+	if o.keepAlive != DefaultKeepAliveInterval {
+		t.Errorf("keepAlive = %v, want %v", o.keepAlive, DefaultKeepAliveInterval)
+	}
+}
+
+func TestOpenStream_Observer(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		observed []stt.StreamEventType
+	)
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1), WithObserver(func(item any) {
+		event, ok := item.(omnivoice.StreamEvent)
+		if !ok {
+			t.Errorf("observed %T, want omnivoice.StreamEvent", item)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		observed = append(observed, event.Type)
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	_ = handler.SpeechStarted(nil)
+	_ = handler.Message(&wsinterfaces.MessageResponse{IsFinal: true})
+	_ = handler.UnhandledEvent([]byte(`{"type":"Warning","warn_code":"W1","warn_msg":"slow down"}`))
+	_ = handler.UtteranceEnd(nil)
+	_ = handler.Error(&wsinterfaces.ErrorResponse{Description: "boom"})
+	_ = stream.Close()
+
+	// Events after the close are dropped, so they are not observed
+	_ = handler.Message(&wsinterfaces.MessageResponse{IsFinal: true})
+
+	var delivered []stt.StreamEventType
+	for event := range events {
+		delivered = append(delivered, event.Type)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []stt.StreamEventType{stt.EventSpeechStart, stt.EventTranscript, omnivoice.EventWarning, stt.EventSpeechEnd, stt.EventError, omnivoice.EventClose}
+	if !slices.Equal(observed, want) {
+		t.Errorf("observed %v, want %v", observed, want)
+	}
+	if !slices.Equal(delivered, observed) {
+		t.Errorf("delivered %v, want the observed %v", delivered, observed)
+	}
+}
+
+func TestOpenStream_SlowReader(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	// Deepgram sends more results than the channel buffers, then fails
+	const finals = 250
+	go func() {
+		for i := range finals {
+			_ = handler.Message(finalMessage(fmt.Sprintf("part %d", i)))
+		}
+		_ = handler.Error(&wsinterfaces.ErrorResponse{Description: "connection reset"})
+		_ = stream.Close()
+	}()
+
+	var transcripts, errs int
+	for event := range events {
+		switch event.Type {
+		case stt.EventTranscript:
+			if want := fmt.Sprintf("part %d", transcripts); event.Transcript != want {
+				t.Errorf("transcript %d = %q, want %q", transcripts, event.Transcript, want)
+			}
+			transcripts++
+		case stt.EventError:
+			errs++
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	if transcripts != finals {
+		t.Errorf("received %d final transcripts, want %d", transcripts, finals)
+	}
+	if errs != 1 {
+		t.Errorf("received %d error events, want 1", errs)
+	}
+}
+
+func TestStream_CloseDrainsFinalResults(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return &closingClient{fakeClient: fake, handler: h, last: "over and out"}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	if _, err := stream.Write([]byte{0, 0}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var received []omnivoice.StreamEvent
+	for event := range events {
+		received = append(received, event)
+	}
+
+	if len(fake.controls) != 1 || fake.controls[0] != string(closeStreamMessage) {
+		t.Errorf("control messages = %v, want a single CloseStream", fake.controls)
+	}
+	if len(received) != 2 {
+		t.Fatalf("got %d events, want the final transcript and a close", len(received))
+	}
+	if event := received[0]; event.Type != stt.EventTranscript || !event.IsFinal || event.Transcript != "over and out" {
+		t.Errorf("first event = %+v, want the final transcript", event)
+	}
+	if last := received[1]; last.Close == nil || last.Close.Reason != omnivoice.CloseClient || last.Close.Err != nil {
+		t.Errorf("last event = %+v, want a clean client EventClose", last)
+	}
+}
+
+func TestStream_CloseDrainTimeout(t *testing.T) {
+	const drain = 50 * time.Millisecond
+
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(drain))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return fake, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < drain || elapsed > 20*drain {
+		t.Errorf("Close() took %v, want about %v", elapsed, drain)
+	}
+
+	var last omnivoice.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Close == nil || last.Close.Reason != omnivoice.CloseClient {
+		t.Errorf("last event = %+v, want a client EventClose", last)
+	}
+	if fake.stops != 1 {
+		t.Errorf("client stopped %d times, want 1", fake.stops)
+	}
+}
+
+func TestOpenStream_CloseEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     func(stream *Stream, handler wsinterfaces.LiveMessageCallback, cancel context.CancelFunc)
+		reason  omnivoice.CloseReason
+		wantErr error
+	}{
+		{
+			name: "client",
+			end: func(stream *Stream, _ wsinterfaces.LiveMessageCallback, _ context.CancelFunc) {
+				_ = stream.Close()
+			},
+			reason: omnivoice.CloseClient,
+		},
+		{
+			name: "context",
+			end: func(_ *Stream, _ wsinterfaces.LiveMessageCallback, cancel context.CancelFunc) {
+				cancel()
+			},
+			reason:  omnivoice.CloseContext,
+			wantErr: context.Canceled,
+		},
+		{
+			name: "server clean",
+			end: func(_ *Stream, handler wsinterfaces.LiveMessageCallback, _ context.CancelFunc) {
+				_ = handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+			},
+			reason: omnivoice.CloseServer,
+		},
+		{
+			name: "server error",
+			end: func(_ *Stream, handler wsinterfaces.LiveMessageCallback, _ context.CancelFunc) {
+				_ = handler.Error(&wsinterfaces.ErrorResponse{Description: "connection reset"})
+				_ = handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+			},
+			reason:  omnivoice.CloseServer,
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			fake := &fakeClient{}
+			var handler wsinterfaces.LiveMessageCallback
+			p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+				handler = h
+				return fake, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, events, err := p.OpenStream(ctx, stt.TranscriptionConfig{})
+			if err != nil {
+				t.Fatalf("OpenStream() error = %v", err)
+			}
+
+			_ = handler.SpeechStarted(nil)
+			tt.end(stream, handler, cancel)
+
+			var received []omnivoice.StreamEvent
+			for event := range events {
+				received = append(received, event)
+			}
+			if len(received) == 0 {
+				t.Fatal("got no events")
+			}
+
+			last := received[len(received)-1]
+			if last.Type != omnivoice.EventClose || last.Close == nil {
+				t.Fatalf("last event = %+v, want EventClose", last)
+			}
+			if last.Close.Reason != tt.reason {
+				t.Errorf("close reason = %q, want %q", last.Close.Reason, tt.reason)
+			}
+			switch {
+			case tt.wantErr == nil && last.Close.Err != nil:
+				t.Errorf("close error = %v, want nil", last.Close.Err)
+			case tt.wantErr == errAny && last.Close.Err == nil:
+				t.Error("close error = nil, want the reported error")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(last.Close.Err, tt.wantErr):
+				t.Errorf("close error = %v, want %v", last.Close.Err, tt.wantErr)
+			}
+			if last.Error != last.Close.Err {
+				t.Errorf("event error = %v, want the close error %v", last.Error, last.Close.Err)
+			}
+			for _, event := range received[:len(received)-1] {
+				if event.Type == omnivoice.EventClose {
+					t.Error("EventClose delivered before the last event")
+				}
+			}
+
+			// Closing again must not emit a second close event or stop twice
+			_ = stream.Close()
+			deadline := time.Now().Add(time.Second)
+			for {
+				fake.mu.Lock()
+				stops := fake.stops
+				fake.mu.Unlock()
+				if stops == 1 {
+					break
+				}
+				if stops > 1 || time.Now().After(deadline) {
+					t.Fatalf("client stopped %d times, want 1", stops)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestStream_ProcessedDuration(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var handler wsinterfaces.LiveMessageCallback
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handler = h
+		return &fakeClient{}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	// Interim results cover audio that a later final covers again
+	fixtures := []*wsinterfaces.MessageResponse{
+		{Start: 0, Duration: 0.5},
+		{Start: 0, Duration: 1.5, IsFinal: true},
+		{Start: 1.5, Duration: 1},
+		{Start: 1.5, Duration: 2.25, IsFinal: true},
+		{Start: 3.75, Duration: 0.75, IsFinal: true},
+	}
+	for _, fixture := range fixtures {
+		_ = handler.Message(fixture)
+	}
+
+	want := 4500 * time.Millisecond
+	if got := stream.ProcessedDuration(); got != want {
+		t.Errorf("ProcessedDuration() = %v, want %v", got, want)
+	}
+
+	_ = stream.Close()
+	var last omnivoice.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Close == nil || last.Close.ProcessedDuration != want {
+		t.Errorf("close event = %+v, want ProcessedDuration %v", last.Close, want)
+	}
+}
+
+// errAny marks a test case that expects some non-nil error.
+var errAny = errors.New("any error")
+
+func TestStream_WriteRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		failWrites   int
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "no retry", retries: 0, failWrites: 1, wantErr: errWriteBlip, wantAttempts: 1},
+		{name: "recovers from blip", retries: 2, failWrites: 1, wantAttempts: 2},
+		{name: "retries exhausted", retries: 2, failWrites: 5, wantErr: ErrWriteFailed, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1), WithWriteRetry(tt.retries, time.Millisecond))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			fake := &fakeClient{failWrites: tt.failWrites}
+			p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+				return fake, nil
+			}
+
+			stream, _, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+			if err != nil {
+				t.Fatalf("OpenStream() error = %v", err)
+			}
+			defer stream.Close()
+
+			chunk := []byte{1, 2, 3, 4}
+			n, err := stream.Write(chunk)
+			if tt.wantErr == nil {
+				if err != nil || n != len(chunk) {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(chunk))
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Write() error = %v, want %v", err, tt.wantErr)
+			}
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if fake.attempts != tt.wantAttempts {
+				t.Errorf("write attempts = %d, want %d", fake.attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == nil && (len(fake.written) != 1 || !bytes.Equal(fake.written[0], chunk)) {
+				t.Errorf("written = %v, want the chunk once", fake.written)
+			}
+		})
+	}
+}
+
+func TestStream_WriteRetryStopsOnClose(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(-1), WithWriteRetry(3, time.Hour))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return &fakeClient{failWrites: 1}, nil
+	}
+
+	stream, _, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := stream.Write([]byte{0, 0})
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = stream.Close()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("Write() error = %v, want io.ErrClosedPipe", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write() still retrying after Close")
+	}
+}
@@ -0,0 +1,63 @@
+package stt
+
+import (
+	"context"
+
+	"github.com/deepgram/deepgram-go-sdk/v3/pkg/api/version"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// Request describes the Deepgram requests the provider would send for a
+// TranscriptionConfig, after all defaults and mappings are applied.
+type Request struct {
+	// PreRecordedURL is the endpoint used by the batch methods.
+	PreRecordedURL string
+
+	// PreRecorded holds the options sent by the batch methods.
+	PreRecorded *interfaces.PreRecordedTranscriptionOptions
+
+	// LiveURL is the WebSocket endpoint used by streaming sessions.
+	LiveURL string
+
+	// Live holds the options sent when opening a streaming session.
+	Live *interfaces.LiveTranscriptionOptions
+}
+
+// BuildRequest returns the Deepgram requests that would be sent for config
+// without contacting Deepgram, for debugging and cost estimation.
+// Content-dependent settings, such as those from WithEncodingAutoDetect, are
+// not reflected.
+func (p *Provider) BuildRequest(config stt.TranscriptionConfig) (*Request, error) {
+	if err := omnivoice.ValidateTranscriptionConfig(config); err != nil {
+		return nil, err
+	}
+
+	ctx := omnivoice.ListenContext(context.Background(), config)
+	config = p.withDefaults(config)
+
+	clientOptions := p.endpoint.RESTOptions()
+	clientOptions.APIKey = p.apiKey
+	if err := clientOptions.Parse(); err != nil {
+		return nil, err
+	}
+	liveHost := p.endpoint.StreamHost(clientOptions.Host)
+
+	req := &Request{
+		PreRecorded: omnivoice.ConfigToPreRecordedOptions(config),
+		Live:        omnivoice.ConfigToLiveTranscriptionOptions(config),
+	}
+
+	var err error
+	req.PreRecordedURL, err = version.GetPrerecordedAPI(ctx, clientOptions.Host, clientOptions.APIVersion, clientOptions.Path, req.PreRecorded)
+	if err != nil {
+		return nil, err
+	}
+	req.LiveURL, err = version.GetLiveAPI(ctx, liveHost, clientOptions.APIVersion, clientOptions.Path, req.Live)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
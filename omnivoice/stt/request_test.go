@@ -0,0 +1,78 @@
+package stt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+func TestBuildRequest(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDefaultModel("nova-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, err := p.BuildRequest(stt.TranscriptionConfig{
+		Language:                 "es",
+		Encoding:                 "g711u",
+		SampleRate:               8000,
+		EnablePunctuation:        true,
+		EnableSpeakerDiarization: true,
+		MaxSpeakers:              2,
+		Keywords:                 []string{"Deepgram:2"},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	wantPreRecorded := "https://api.deepgram.com/v1/listen?diarize=true&keywords=Deepgram%3A2&language=es&model=nova-3&punctuate=true&smart_format=true&utterances=true"
+	if req.PreRecordedURL != wantPreRecorded {
+		t.Errorf("PreRecordedURL = %s\nwant %s", req.PreRecordedURL, wantPreRecorded)
+	}
+
+	wantLive := "wss://api.deepgram.com/v1/listen?channels=1&diarize=true&diarize_version=latest&encoding=mulaw&interim_results=true&keywords=Deepgram%3A2&language=es&model=nova-3&punctuate=true&sample_rate=8000&smart_format=true&utterance_end_ms=1000"
+	if req.LiveURL != wantLive {
+		t.Errorf("LiveURL = %s\nwant %s", req.LiveURL, wantLive)
+	}
+
+	if req.Live.Encoding != "mulaw" || req.PreRecorded.Model != "nova-3" {
+		t.Errorf("options = %+v / %+v", req.Live, req.PreRecorded)
+	}
+}
+
+func TestBuildRequest_Formatting(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, err := p.BuildRequest(stt.TranscriptionConfig{
+		EnablePunctuation: true,
+		Extensions: map[string]any{
+			omnivoice.ExtNumerals:     true,
+			omnivoice.ExtMeasurements: true,
+			omnivoice.ExtDictation:    true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	if !req.PreRecorded.Numerals || !req.PreRecorded.Measurements || !req.Live.Numerals {
+		t.Errorf("options = %+v / %+v, want numerals and measurements", req.PreRecorded, req.Live)
+	}
+	for _, url := range []string{req.PreRecordedURL, req.LiveURL} {
+		if !strings.Contains(url, "dictation=true") || !strings.Contains(url, "numerals=true") {
+			t.Errorf("URL = %s, want dictation and numerals", url)
+		}
+	}
+	if !strings.Contains(req.PreRecordedURL, "measurements=true") {
+		t.Errorf("PreRecordedURL = %s, want measurements", req.PreRecordedURL)
+	}
+
+	if _, err := p.BuildRequest(stt.TranscriptionConfig{Extensions: map[string]any{omnivoice.ExtDictation: true}}); err == nil {
+		t.Error("BuildRequest() with dictation but no punctuation error = nil")
+	}
+}
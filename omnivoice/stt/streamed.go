@@ -0,0 +1,150 @@
+package stt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// streamChunkSize is the amount of audio TranscribeStreamed sends per write.
+const streamChunkSize = 8192
+
+// closeStreamMessage asks Deepgram to deliver the results for the audio
+// sent so far and then close the session.
+var closeStreamMessage = json.RawMessage(`{"type":"CloseStream"}`)
+
+// TranscribeStreamed transcribes the audio read from r over a streaming
+// session instead of a REST request, and returns the final transcripts as
+// one result once r is exhausted and Deepgram has closed the session. The
+// config is that of TranscribeStream, and the audio is sent as by
+// TranscribeStreamReader.
+//
+// Unlike Transcribe, it keeps what it has received: when ctx is cancelled,
+// the session fails, or reading r fails, it returns the transcript so far
+// together with the error. The result is nil only when the session could
+// not be opened. Batch REST requests cannot do this, since Deepgram returns
+// their transcript in a single response that a cancelled request never
+// receives.
+//
+// r is not closed. A Read still blocked when ctx is cancelled is left to
+// return on its own.
+func (p *Provider) TranscribeStreamed(ctx context.Context, r io.Reader, config stt.TranscriptionConfig) (*stt.TranscriptionResult, error) {
+	stream, events, err := p.OpenStream(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	go streamAudio(stream, r, omnivoice.ConfigToLiveTranscriptionOptions(config))
+
+	result := &stt.TranscriptionResult{}
+	var texts []string
+	var closeErr error
+	for event := range events {
+		switch {
+		case event.Type == stt.EventTranscript && event.IsFinal && event.Transcript != "":
+			texts = append(texts, event.Transcript)
+			segment := stt.Segment{Text: event.Transcript}
+			if event.Segment != nil {
+				segment = *event.Segment
+			}
+			result.Segments = append(result.Segments, segment)
+			result.Duration = max(result.Duration, segment.EndTime)
+		case event.Type == omnivoice.EventClose && event.Close != nil:
+			closeErr = event.Close.Err
+		}
+	}
+	result.Text = strings.Join(texts, " ")
+
+	return result, closeErr
+}
+
+// TranscribeStreamReader starts a streaming transcription session fed with
+// the audio read from r, as SynthesizeFromReader is fed with text. The
+// audio is sent in chunks no faster than real time at the configured
+// sample rate, so a file is streamed as a live source would be; audio in a
+// container or compressed encoding, whose duration cannot be derived from
+// its length, is sent as fast as r provides it. At EOF, Deepgram is asked
+// to deliver the remaining results, which are awaited for at most the
+// drain timeout (see WithDrainTimeout) before the session is closed, so
+// the channel ends with an omnivoice.EventClose whose Error is nil. A read
+// or write failure closes the session early, and the close event carries
+// the error.
+//
+// r is not closed. A Read still blocked when ctx is cancelled is left to
+// return on its own.
+func (p *Provider) TranscribeStreamReader(ctx context.Context, r io.Reader, config stt.TranscriptionConfig) (<-chan stt.StreamEvent, error) {
+	stream, events, err := p.OpenStream(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	go streamAudio(stream, r, omnivoice.ConfigToLiveTranscriptionOptions(config))
+	return coreEvents(ctx, events), nil
+}
+
+// streamAudio sends the audio read from r to stream, closing the stream
+// with the error if that fails.
+func streamAudio(stream *Stream, r io.Reader, opts *interfaces.LiveTranscriptionOptions) {
+	if err := sendAudio(stream, r, opts); err != nil {
+		stream.closeWith(omnivoice.StreamClose{
+			Reason: omnivoice.CloseClient,
+			Err:    fmt.Errorf("failed to send audio: %w", err),
+		}, false)
+	}
+}
+
+// sendAudio writes the audio read from r to stream in chunks, paced to
+// real time for the encoding and sample rate in opts when its duration can
+// be derived from its length. At EOF it asks Deepgram for the remaining
+// results, waits for them up to the drain timeout, and closes the stream.
+func sendAudio(stream *Stream, r io.Reader, opts *interfaces.LiveTranscriptionOptions) error {
+	buf := make([]byte, streamChunkSize)
+	start := time.Now()
+	sent := 0
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if d, ok := omnivoice.PCMDuration(opts.Encoding, opts.SampleRate*opts.Channels, sent); ok {
+				stream.waitUntil(start.Add(d))
+			}
+			if _, werr := stream.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			sent += n
+		}
+		if err == io.EOF {
+			if err := stream.requestClose(); err != nil {
+				return err
+			}
+			stream.waitDrained()
+			stream.closeWith(omnivoice.StreamClose{Reason: omnivoice.CloseClient}, false)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitUntil waits until t or until the stream is closed.
+func (w *Stream) waitUntil(t time.Time) {
+	d := time.Until(t)
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.done:
+	}
+}
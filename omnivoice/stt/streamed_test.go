@@ -0,0 +1,337 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// finalMessage returns a final transcript message for transcript.
+func finalMessage(transcript string) *wsinterfaces.MessageResponse {
+	return &wsinterfaces.MessageResponse{
+		IsFinal: true,
+		Channel: wsinterfaces.Channel{Alternatives: []wsinterfaces.Alternative{{Transcript: transcript}}},
+	}
+}
+
+// closingClient is a fakeClient that answers CloseStream like Deepgram,
+// delivering a last transcript and closing the session.
+type closingClient struct {
+	*fakeClient
+	handler wsinterfaces.LiveMessageCallback
+	last    string
+}
+
+func (c *closingClient) WriteJSON(payload any) error {
+	if err := c.fakeClient.WriteJSON(payload); err != nil {
+		return err
+	}
+	go func() {
+		_ = c.handler.Message(finalMessage(c.last))
+		_ = c.handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+	}()
+	return nil
+}
+
+func TestTranscribeStreamed(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		_ = h.Message(finalMessage("hello there"))
+		return &closingClient{fakeClient: fake, handler: h, last: "general kenobi"}, nil
+	}
+
+	audio := bytes.Repeat([]byte{1}, streamChunkSize+100)
+	result, err := p.TranscribeStreamed(context.Background(), bytes.NewReader(audio), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("TranscribeStreamed() error = %v", err)
+	}
+	if result.Text != "hello there general kenobi" {
+		t.Errorf("Text = %q, want %q", result.Text, "hello there general kenobi")
+	}
+	if len(result.Segments) != 2 {
+		t.Errorf("got %d segments, want 2", len(result.Segments))
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if got := len(bytes.Join(fake.written, nil)); got != len(audio) {
+		t.Errorf("sent %d bytes, want %d", got, len(audio))
+	}
+	if len(fake.controls) != 1 || !strings.Contains(fake.controls[0], "CloseStream") {
+		t.Errorf("controls = %q, want one CloseStream", fake.controls)
+	}
+}
+
+func TestTranscribeStreamed_Cancel(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handlers := make(chan wsinterfaces.LiveMessageCallback, 1)
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handlers <- h
+		return &fakeClient{}, nil
+	}
+
+	// The audio never ends, so only cancellation stops the session
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type outcome struct {
+		result *stt.TranscriptionResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := p.TranscribeStreamed(ctx, pr, stt.TranscriptionConfig{})
+		done <- outcome{result, err}
+	}()
+
+	// Once audio is read the session is open
+	handler := <-handlers
+	_, _ = pw.Write([]byte{1, 2})
+	_ = handler.Message(finalMessage("hello there"))
+	_ = handler.Message(&wsinterfaces.MessageResponse{
+		Channel: wsinterfaces.Channel{Alternatives: []wsinterfaces.Alternative{{Transcript: "gen"}}},
+	})
+	_ = handler.Message(finalMessage("general kenobi"))
+	cancel()
+
+	got := <-done
+	if !errors.Is(got.err, context.Canceled) {
+		t.Errorf("error = %v, want %v", got.err, context.Canceled)
+	}
+	if got.result == nil {
+		t.Fatal("result = nil, want the partial transcript")
+	}
+	if got.result.Text != "hello there general kenobi" {
+		t.Errorf("Text = %q, want %q", got.result.Text, "hello there general kenobi")
+	}
+}
+
+func TestTranscribeStreamed_ServerError(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handlers := make(chan wsinterfaces.LiveMessageCallback, 1)
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		handlers <- h
+		return &fakeClient{}, nil
+	}
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	done := make(chan error, 1)
+	var result *stt.TranscriptionResult
+	go func() {
+		var err error
+		result, err = p.TranscribeStreamed(context.Background(), pr, stt.TranscriptionConfig{})
+		done <- err
+	}()
+
+	// Once audio is read the session is open
+	handler := <-handlers
+	_, _ = pw.Write([]byte{1, 2})
+	_ = handler.Message(finalMessage("hello there"))
+	_ = handler.Error(&wsinterfaces.ErrorResponse{Description: "connection reset"})
+	_ = handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+
+	if err := <-done; err == nil {
+		t.Error("error = nil, want the session error")
+	}
+	if result == nil || result.Text != "hello there" {
+		t.Errorf("result = %+v, want the partial transcript", result)
+	}
+}
+
+func TestTranscribeStreamReader(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return &closingClient{fakeClient: fake, handler: h, last: "hello there"}, nil
+	}
+
+	audio := bytes.Repeat([]byte{1}, 2*streamChunkSize+10)
+	events, err := p.TranscribeStreamReader(context.Background(), bytes.NewReader(audio), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("TranscribeStreamReader() error = %v", err)
+	}
+
+	var received []stt.StreamEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	if len(received) != 2 {
+		t.Fatalf("got %d events, want a transcript and the close", len(received))
+	}
+	if received[0].Transcript != "hello there" || !received[0].IsFinal {
+		t.Errorf("first event = %+v, want the final transcript", received[0])
+	}
+	if received[1].Type != omnivoice.EventClose || received[1].Error != nil {
+		t.Errorf("last event = %+v, want a clean close", received[1])
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.written) != 3 {
+		t.Errorf("sent %d chunks, want 3", len(fake.written))
+	}
+	if got := len(bytes.Join(fake.written, nil)); got != len(audio) {
+		t.Errorf("sent %d bytes, want %d", got, len(audio))
+	}
+}
+
+func TestStreamAudio_EOFClosesAsClient(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return &closingClient{fakeClient: &fakeClient{}, handler: h, last: "hello there"}, nil
+	}
+
+	stream, events, err := p.OpenStream(context.Background(), stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	go streamAudio(stream, bytes.NewReader([]byte{1, 2, 3}), omnivoice.ConfigToLiveTranscriptionOptions(stt.TranscriptionConfig{}))
+
+	var last omnivoice.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Type != omnivoice.EventClose || last.Close == nil {
+		t.Fatalf("last event = %+v, want EventClose", last)
+	}
+	if last.Close.Reason != omnivoice.CloseClient {
+		t.Errorf("close reason = %v, want %v after the reader's EOF", last.Close.Reason, omnivoice.CloseClient)
+	}
+	if last.Close.Err != nil {
+		t.Errorf("close error = %v, want nil", last.Close.Err)
+	}
+}
+
+func TestTranscribeStreamed_EOFWaitBounded(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDrainTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The client never answers CloseStream.
+	fake := &fakeClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		_ = h.Message(finalMessage("hello there"))
+		return fake, nil
+	}
+
+	done := make(chan struct{})
+	var result *stt.TranscriptionResult
+	go func() {
+		defer close(done)
+		result, err = p.TranscribeStreamed(context.Background(), bytes.NewReader([]byte{1, 2, 3}), stt.TranscriptionConfig{})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("TranscribeStreamed() did not return after the drain timeout")
+	}
+	if err != nil {
+		t.Fatalf("TranscribeStreamed() error = %v", err)
+	}
+	if result.Text != "hello there" {
+		t.Errorf("Text = %q, want %q", result.Text, "hello there")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.controls) != 1 || !strings.Contains(fake.controls[0], "CloseStream") {
+		t.Errorf("controls = %q, want one CloseStream", fake.controls)
+	}
+}
+
+func TestSendAudio_Paced(t *testing.T) {
+	fake := &fakeClient{}
+	s := newTestStream(fake)
+
+	// Two chunks of 80 kHz mulaw: the second is due a chunk's duration later.
+	opts := &interfaces.LiveTranscriptionOptions{Encoding: "mulaw", SampleRate: 80000, Channels: 1}
+	audio := bytes.Repeat([]byte{1}, 2*streamChunkSize)
+
+	start := time.Now()
+	if err := sendAudio(s, bytes.NewReader(audio), opts); err != nil {
+		t.Fatalf("sendAudio() error = %v", err)
+	}
+	want, _ := omnivoice.PCMDuration("mulaw", 80000, streamChunkSize)
+	if elapsed := time.Since(start); elapsed < want {
+		t.Errorf("sent %d bytes in %v, want at least %v", len(audio), elapsed, want)
+	}
+	if len(fake.written) != 2 {
+		t.Errorf("sent %d chunks, want 2", len(fake.written))
+	}
+}
+
+// failingReader returns data, then err.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestTranscribeStreamReader_ReadError(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	p.dial = func(ctx context.Context, opts *interfaces.LiveTranscriptionOptions, h wsinterfaces.LiveMessageCallback) (DeepgramClient, error) {
+		return &fakeClient{}, nil
+	}
+
+	errRead := errors.New("device unplugged")
+	events, err := p.TranscribeStreamReader(context.Background(), &failingReader{data: []byte{1, 2}, err: errRead}, stt.TranscriptionConfig{})
+	if err != nil {
+		t.Fatalf("TranscribeStreamReader() error = %v", err)
+	}
+
+	var last stt.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Type != omnivoice.EventClose || !errors.Is(last.Error, errRead) {
+		t.Errorf("last event = %+v, want a close carrying %v", last, errRead)
+	}
+}
@@ -0,0 +1,105 @@
+package omnivoice
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+)
+
+// TranscriptVersion is the version of the schema written by
+// MarshalTranscript. It changes only when a field is removed or changes
+// meaning; new optional fields keep the version.
+const TranscriptVersion = 1
+
+// Transcript is the provider-neutral JSON export of a transcription
+// result written by MarshalTranscript. Times are in seconds from the start
+// of the audio, rounded to the millisecond, and confidences range from 0
+// to 1. Fields are always present except those marked omitempty.
+type Transcript struct {
+	// Version is TranscriptVersion.
+	Version int `json:"version"`
+
+	// Text is the full transcript.
+	Text string `json:"text"`
+
+	// Language is the spoken language, if known.
+	Language string `json:"language,omitempty"`
+
+	// Duration is the length of the audio.
+	Duration float64 `json:"duration"`
+
+	// Segments are the transcript's segments in chronological order.
+	Segments []TranscriptSegment `json:"segments"`
+}
+
+// TranscriptSegment is a segment of a Transcript, such as an utterance
+// or a speaker turn.
+type TranscriptSegment struct {
+	Text       string           `json:"text"`
+	Start      float64          `json:"start"`
+	End        float64          `json:"end"`
+	Speaker    string           `json:"speaker,omitempty"`
+	Confidence float64          `json:"confidence"`
+	Words      []TranscriptWord `json:"words"`
+}
+
+// TranscriptWord is a word of a TranscriptSegment.
+type TranscriptWord struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Speaker    string  `json:"speaker,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+// MarshalTranscript exports result as indented JSON in the Transcript
+// schema, which is independent of Deepgram's response format, so that
+// transcripts can be stored and exchanged without depending on the
+// provider. Empty lists are written as [] rather than null.
+func MarshalTranscript(result *TranscriptionResult) ([]byte, error) {
+	if result == nil {
+		return nil, errors.New("cannot marshal a nil transcription result")
+	}
+
+	transcript := Transcript{
+		Version:  TranscriptVersion,
+		Text:     result.Text,
+		Language: result.Language,
+		Duration: seconds(result.Duration),
+		Segments: make([]TranscriptSegment, 0, len(result.Segments)),
+	}
+	for _, seg := range result.Segments {
+		transcript.Segments = append(transcript.Segments, TranscriptSegment{
+			Text:       seg.Text,
+			Start:      seconds(seg.StartTime),
+			End:        seconds(seg.EndTime),
+			Speaker:    seg.Speaker,
+			Confidence: seg.Confidence,
+			Words:      transcriptWords(seg.Words),
+		})
+	}
+
+	return json.MarshalIndent(transcript, "", "  ")
+}
+
+// transcriptWords converts words to the Transcript schema.
+func transcriptWords(words []stt.Word) []TranscriptWord {
+	out := make([]TranscriptWord, 0, len(words))
+	for _, w := range words {
+		out = append(out, TranscriptWord{
+			Text:       w.Text,
+			Start:      seconds(w.StartTime),
+			End:        seconds(w.EndTime),
+			Speaker:    w.Speaker,
+			Confidence: w.Confidence,
+		})
+	}
+	return out
+}
+
+// seconds returns d in seconds, rounded to the millisecond.
+func seconds(d time.Duration) float64 {
+	return float64(d.Round(time.Millisecond).Milliseconds()) / 1000
+}
@@ -0,0 +1,67 @@
+package omnivoice
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	restinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest/interfaces"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestMarshalTranscript_Golden(t *testing.T) {
+	var resp restinterfaces.PreRecordedResponse
+	if err := json.Unmarshal([]byte(diarizedFixture), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	result := PreRecordedResponseToTranscriptionResult(&resp, ConvertOptions{DiarizationGrouping: DiarizationPerTurn})
+	result.Language = "en"
+
+	got, err := MarshalTranscript(result)
+	if err != nil {
+		t.Fatalf("MarshalTranscript() error = %v", err)
+	}
+
+	golden := filepath.Join("testdata", "transcript.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalTranscript() =\n%s\nwant golden file %s:\n%s", got, golden, want)
+	}
+
+	// The export round-trips through the documented types
+	var transcript Transcript
+	if err := json.Unmarshal(got, &transcript); err != nil {
+		t.Fatalf("unmarshal export: %v", err)
+	}
+	if transcript.Version != TranscriptVersion || len(transcript.Segments) != len(result.Segments) {
+		t.Errorf("round trip = version %d with %d segments, want %d with %d",
+			transcript.Version, len(transcript.Segments), TranscriptVersion, len(result.Segments))
+	}
+}
+
+func TestMarshalTranscript_Empty(t *testing.T) {
+	got, err := MarshalTranscript(PreRecordedResponseToTranscriptionResult(nil, ConvertOptions{}))
+	if err != nil {
+		t.Fatalf("MarshalTranscript() error = %v", err)
+	}
+	want := "{\n  \"version\": 1,\n  \"text\": \"\",\n  \"duration\": 0,\n  \"segments\": []\n}"
+	if string(got) != want {
+		t.Errorf("MarshalTranscript() = %s, want %s", got, want)
+	}
+
+	if _, err := MarshalTranscript(nil); err == nil {
+		t.Error("MarshalTranscript(nil) succeeded")
+	}
+}
@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// DialogueLine is one line of a dialogue passed to SynthesizeDialogue.
+type DialogueLine struct {
+	// Text is the text to speak.
+	Text string
+
+	// VoiceID is the voice for this line. If empty, the voice from the
+	// SynthesisConfig is used.
+	VoiceID string
+}
+
+// DialogueResult is the result of SynthesizeDialogue.
+type DialogueResult struct {
+	tts.SynthesisResult
+
+	// LineCharacters holds the number of characters billed for each line,
+	// in the order of the input lines. CharacterCount is their sum.
+	LineCharacters []int
+}
+
+// dialogueEncodings are the encodings whose headerless output can be
+// concatenated into a single stream.
+var dialogueEncodings = map[string]bool{
+	"linear16": true,
+	"mulaw":    true,
+	"alaw":     true,
+}
+
+// SynthesizeDialogue synthesizes each line with its own voice and joins the
+// audio into a single result, so that several speakers can share one output.
+//
+// Lines are requested without a container and concatenated, which requires
+// a raw PCM output format: linear16 (the default), mulaw, or alaw. Other
+// formats return tts.ErrInvalidConfig.
+func (p *Provider) SynthesizeDialogue(ctx context.Context, lines []DialogueLine, config tts.SynthesisConfig) (*DialogueResult, error) {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return nil, err
+	}
+
+	base := omnivoice.ConfigToSpeakOptions(config)
+	if !dialogueEncodings[base.Encoding] {
+		return nil, fmt.Errorf("%w: dialogue requires linear16, mulaw, or alaw output, got %q", tts.ErrInvalidConfig, config.OutputFormat)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := &DialogueResult{
+		SynthesisResult: tts.SynthesisResult{
+			Format:     base.Encoding,
+			SampleRate: omnivoice.EffectiveSampleRate(base),
+		},
+		LineCharacters: make([]int, len(lines)),
+	}
+
+	for i, line := range lines {
+		lineConfig := config
+		if line.VoiceID != "" {
+			lineConfig.VoiceID = line.VoiceID
+			lineConfig.Model = ""
+		}
+		opts := omnivoice.ConfigToSpeakOptions(lineConfig)
+		opts.Container = "none"
+
+		audio, chars, err := p.synthesize(omnivoice.SpeakContext(ctx, lineConfig), line.Text, opts)
+		if err != nil {
+			return nil, errorf(ctx, fmt.Sprintf("deepgram TTS failed for dialogue line %d", i), err)
+		}
+
+		result.Audio = append(result.Audio, audio...)
+		result.LineCharacters[i] = chars
+		result.CharacterCount += chars
+	}
+
+	return result, nil
+}
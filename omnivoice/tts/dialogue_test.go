@@ -0,0 +1,74 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+func TestSynthesizeDialogue(t *testing.T) {
+	var models, containers []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		model := r.URL.Query().Get("model")
+		models = append(models, model)
+		containers = append(containers, r.URL.Query().Get("container"))
+		w.Header().Set("char-count", strconv.Itoa(len(model)))
+		_, _ = w.Write([]byte("[" + model + "]"))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	lines := []DialogueLine{
+		{Text: "Hi there.", VoiceID: "aura-asteria-en"},
+		{Text: "Hello!", VoiceID: "aura-orion-en"},
+		{Text: "How are you?"},
+	}
+	result, err := p.SynthesizeDialogue(context.Background(), lines, tts.SynthesisConfig{VoiceID: "aura-luna-en", SampleRate: 16000})
+	if err != nil {
+		t.Fatalf("SynthesizeDialogue() error = %v", err)
+	}
+
+	if want := []string{"aura-asteria-en", "aura-orion-en", "aura-luna-en"}; !slices.Equal(models, want) {
+		t.Errorf("requested models = %q, want %q", models, want)
+	}
+	for i, c := range containers {
+		if c != "none" {
+			t.Errorf("line %d container = %q, want none", i, c)
+		}
+	}
+	if want := "[aura-asteria-en][aura-orion-en][aura-luna-en]"; string(result.Audio) != want {
+		t.Errorf("Audio = %q, want %q", result.Audio, want)
+	}
+	if want := []int{15, 13, 12}; !slices.Equal(result.LineCharacters, want) {
+		t.Errorf("LineCharacters = %v, want %v", result.LineCharacters, want)
+	}
+	if result.CharacterCount != 40 {
+		t.Errorf("CharacterCount = %d, want 40", result.CharacterCount)
+	}
+	if result.Format != "linear16" || result.SampleRate != 16000 {
+		t.Errorf("Format/SampleRate = %q/%d, want linear16/16000", result.Format, result.SampleRate)
+	}
+}
+
+func TestSynthesizeDialogue_UnsupportedFormat(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.SynthesizeDialogue(context.Background(), []DialogueLine{{Text: "Hi"}}, tts.SynthesisConfig{OutputFormat: "mp3"})
+	if !errors.Is(err, tts.ErrInvalidConfig) {
+		t.Fatalf("SynthesizeDialogue() error = %v, want ErrInvalidConfig", err)
+	}
+}
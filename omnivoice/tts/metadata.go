@@ -0,0 +1,83 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dvonthenen/websocket"
+
+	speakws "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket"
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
+	common "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/common/v1"
+	commoninterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/common/v1/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	speak "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/speak"
+	speakclient "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/speak/v1/websocket"
+)
+
+// speakMetadata is Deepgram's TTS Metadata message, including the audio
+// format fields that the SDK's MetadataResponse does not keep. Encoding
+// and SampleRate are empty unless Deepgram reports them.
+type speakMetadata struct {
+	Type       string `json:"type"`
+	RequestID  string `json:"request_id"`
+	Encoding   string `json:"encoding"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+// metadataReader is implemented by callback handlers that read the raw
+// Metadata message, before the SDK parses it.
+type metadataReader interface {
+	rawMetadata(msg []byte)
+}
+
+// metadataTap is the message handler of a Deepgram TTS connection. It
+// shows each text message to reader before passing it to the SDK, so that
+// the fields the SDK drops from Metadata are not lost.
+type metadataTap struct {
+	*speakclient.WSCallback
+	reader metadataReader
+}
+
+// ProcessMessage shows text messages to the tap's reader, then routes the
+// message as the SDK does.
+func (t *metadataTap) ProcessMessage(wsType int, byMsg []byte) error {
+	if wsType == websocket.TextMessage {
+		t.reader.rawMetadata(byMsg)
+	}
+	return t.WSCallback.ProcessMessage(wsType, byMsg)
+}
+
+// newSpeakClient creates a Deepgram TTS WebSocket client whose messages
+// reach handler as with speak.NewWSUsingCallback, and whose raw Metadata
+// messages also reach handler when it is a metadataReader.
+func newSpeakClient(ctx context.Context, apiKey string, cOptions *interfaces.ClientOptions, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (*speakclient.WSCallback, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	wsClient, err := speak.NewWSUsingCallbackWithCancel(ctx, cancel, apiKey, cOptions, opts, handler)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	reader, ok := handler.(metadataReader)
+	if !ok {
+		return wsClient, nil
+	}
+
+	// Rebuild the connection around the tap; the SDK keeps its own message
+	// handler unexported
+	var tap commoninterfaces.WebSocketHandler = &metadataTap{WSCallback: wsClient, reader: reader}
+	var router commoninterfaces.Router = speakws.NewCallbackRouter(handler)
+	wsClient.WSClient = common.NewWS(ctx, cancel, apiKey, cOptions, &tap, &router)
+	return wsClient, nil
+}
+
+// parseSpeakMetadata returns the Metadata message in msg, or false if msg
+// is another message.
+func parseSpeakMetadata(msg []byte) (speakMetadata, bool) {
+	var md speakMetadata
+	if err := json.Unmarshal(msg, &md); err != nil || md.Type != string(wsinterfaces.TypeMetadataResponse) {
+		return speakMetadata{}, false
+	}
+	return md, true
+}
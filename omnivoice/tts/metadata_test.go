@@ -0,0 +1,58 @@
+package tts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dvonthenen/websocket"
+
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// metadataFixture is a Metadata message reporting a format other than the
+// linear16 at 24 kHz requested in the tests.
+const metadataFixture = `{"type":"Metadata","request_id":"req-1","model_name":"aura-asteria-en","model_version":"2024-01-01.0","encoding":"mulaw","sample_rate":8000}`
+
+func TestMetadataTap(t *testing.T) {
+	handler := &ttsCallbackHandler{}
+	opts := &interfaces.WSSpeakOptions{Model: "aura-asteria-en", Encoding: "linear16"}
+	client, err := newSpeakClient(context.Background(), "test-key", &interfaces.ClientOptions{}, opts, handler)
+	if err != nil {
+		t.Fatalf("newSpeakClient() error = %v", err)
+	}
+	tap := &metadataTap{WSCallback: client, reader: handler}
+
+	if err := tap.ProcessMessage(websocket.TextMessage, []byte(metadataFixture)); err != nil {
+		t.Fatalf("ProcessMessage() error = %v", err)
+	}
+	if encoding, rate := handler.deliveredFormat("linear16", 24000); encoding != "mulaw" || rate != 8000 {
+		t.Errorf("format = %s/%d, want mulaw/8000", encoding, rate)
+	}
+}
+
+func TestMetadataTap_FormatNotReported(t *testing.T) {
+	handler := &ttsCallbackHandler{}
+	handler.rawMetadata([]byte(`{"type":"Metadata","request_id":"req-1","model_name":"aura-asteria-en"}`))
+
+	if encoding, rate := handler.deliveredFormat("linear16", 24000); encoding != "linear16" || rate != 24000 {
+		t.Errorf("format = %s/%d, want the requested linear16/24000", encoding, rate)
+	}
+}
+
+func TestHandlerRouter_ReplaysMetadata(t *testing.T) {
+	router := &handlerRouter{}
+	first := &ttsCallbackHandler{}
+	router.attach(first)
+	router.rawMetadata([]byte(metadataFixture))
+
+	// A later session on the pooled connection never sees the message
+	// itself, which Deepgram sends once per connection
+	second := &ttsCallbackHandler{}
+	router.attach(second)
+
+	for name, h := range map[string]*ttsCallbackHandler{"first": first, "second": second} {
+		if encoding, rate := h.deliveredFormat("linear16", 24000); encoding != "mulaw" || rate != 8000 {
+			t.Errorf("%s session format = %s/%d, want mulaw/8000", name, encoding, rate)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// poolClearTimeout bounds how long a connection returned to the pool
+// mid-synthesis may take to confirm it has discarded its pending audio.
+const poolClearTimeout = 2 * time.Second
+
+// WithConnectionPool keeps up to size idle streaming connections open for
+// reuse by SynthesizeStream, saving the connection setup on each call.
+// Connections are shared only between calls whose voice, encoding, sample
+// rate, and deepgram.extra metadata match, since Deepgram fixes those when
+// a connection opens. Idle connections older than maxIdle, or closed by
+// Deepgram, are discarded rather than reused; a maxIdle of 0 keeps them
+// until Deepgram closes them. A size below 1 disables pooling, the default.
+//
+// A pooled connection outlives the context of the call that opened it, and
+// is returned to the pool once its audio has been delivered, or after
+// Deepgram confirms it has cleared the rest when the call's context ends
+// early. Call Close to close the idle connections.
+func WithConnectionPool(size int, maxIdle time.Duration) Option {
+	return func(o *options) {
+		o.poolSize = size
+		o.poolMaxIdle = maxIdle
+	}
+}
+
+// connPool holds idle streaming connections for reuse.
+type connPool struct {
+	size    int
+	maxIdle time.Duration
+
+	// now returns the current time; replaced in tests
+	now func() time.Time
+
+	mu   sync.Mutex
+	idle []*pooledConn
+}
+
+// newConnPool returns a pool of up to size idle connections, or nil if
+// size is below 1.
+func newConnPool(size int, maxIdle time.Duration) *connPool {
+	if size < 1 {
+		return nil
+	}
+	return &connPool{size: size, maxIdle: maxIdle, now: time.Now}
+}
+
+// pooledConn is a streaming connection that can serve several sessions
+// in turn.
+type pooledConn struct {
+	client SpeakClient
+	router *handlerRouter
+
+	// key identifies the options the connection was opened with
+	key string
+
+	// idleSince is when the connection was returned to the pool
+	idleSince time.Time
+}
+
+// poolKey returns the key of the connections that can serve config with opts.
+func poolKey(config tts.SynthesisConfig, opts *interfaces.WSSpeakOptions) string {
+	return fmt.Sprintf("%+v|%v", *opts, config.Extensions[omnivoice.ExtExtra])
+}
+
+// get removes and returns an idle connection for key, or nil if there is
+// none. Stale and closed connections found on the way are closed.
+func (cp *connPool) get(key string) *pooledConn {
+	cp.mu.Lock()
+	stale := cp.evictLocked()
+	var found *pooledConn
+	for i := len(cp.idle) - 1; i >= 0; i-- {
+		if conn := cp.idle[i]; conn.key == key {
+			found = conn
+			cp.idle = slices.Delete(cp.idle, i, i+1)
+			break
+		}
+	}
+	cp.mu.Unlock()
+
+	stopAll(stale)
+	return found
+}
+
+// put returns conn to the pool, closing it instead if Deepgram has closed
+// it or the pool is full.
+func (cp *connPool) put(conn *pooledConn) {
+	cp.mu.Lock()
+	stale := cp.evictLocked()
+	if conn.router.isClosed() || len(cp.idle) >= cp.size {
+		stale = append(stale, conn)
+	} else {
+		conn.idleSince = cp.now()
+		cp.idle = append(cp.idle, conn)
+	}
+	cp.mu.Unlock()
+
+	stopAll(stale)
+}
+
+// close closes all idle connections, and those returned later.
+func (cp *connPool) close() {
+	cp.mu.Lock()
+	idle := cp.idle
+	cp.idle = nil
+	cp.size = 0
+	cp.mu.Unlock()
+
+	stopAll(idle)
+}
+
+// idleCount returns the number of idle connections.
+func (cp *connPool) idleCount() int {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return len(cp.idle)
+}
+
+// evictLocked removes and returns the idle connections that are closed or
+// have been idle longer than maxIdle. cp.mu must be held.
+func (cp *connPool) evictLocked() []*pooledConn {
+	now := cp.now()
+	var stale []*pooledConn
+	cp.idle = slices.DeleteFunc(cp.idle, func(conn *pooledConn) bool {
+		if conn.router.isClosed() || (cp.maxIdle > 0 && now.Sub(conn.idleSince) > cp.maxIdle) {
+			stale = append(stale, conn)
+			return true
+		}
+		return false
+	})
+	return stale
+}
+
+// stopAll closes conns. It is called without holding the pool lock, since
+// closing a connection waits for the close handshake.
+func stopAll(conns []*pooledConn) {
+	for _, conn := range conns {
+		conn.client.Stop()
+	}
+}
+
+// Close closes the idle connections of the pool set with
+// WithConnectionPool. Sessions in progress are not affected, and their
+// connections are closed when they end instead of being pooled again.
+// Without a pool, Close does nothing.
+func (p *Provider) Close() error {
+	if p.pool == nil {
+		return nil
+	}
+	p.pool.close()
+	return nil
+}
+
+// streamConn returns a connection for a SynthesizeStream session routed to
+// handler, taking it from the pool when possible. Pooled connections are
+// opened with a context that outlives ctx.
+func (p *Provider) streamConn(ctx context.Context, config tts.SynthesisConfig, opts *interfaces.WSSpeakOptions, handler *ttsCallbackHandler) (*pooledConn, error) {
+	key := poolKey(config, opts)
+	if conn := p.pool.get(key); conn != nil {
+		conn.router.attach(handler)
+		return conn, nil
+	}
+
+	router := &handlerRouter{}
+	router.attach(handler)
+	client, err := p.dial(context.WithoutCancel(omnivoice.SpeakContext(ctx, config)), opts, router)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledConn{client: client, router: router, key: key}, nil
+}
+
+// release detaches conn from its session and returns it to the pool. A
+// session that ended before its audio was delivered has the rest cleared
+// first; if Deepgram does not confirm that, the connection is closed.
+func (p *Provider) release(conn *pooledConn, complete bool) {
+	conn.router.attach(nil)
+	if complete {
+		p.pool.put(conn)
+		return
+	}
+
+	go func() {
+		cleared := conn.router.expectClear()
+		if err := conn.client.Reset(); err != nil {
+			conn.client.Stop()
+			return
+		}
+		select {
+		case <-cleared:
+			p.pool.put(conn)
+		case <-time.After(poolClearTimeout):
+			conn.client.Stop()
+		}
+	}()
+}
+
+// handlerRouter is the callback handler of a pooled connection, or of a
+// reader session's connection. It forwards Deepgram's callbacks to the
+// session using the connection, and drops them between sessions or once
+// the session has moved to another connection.
+type handlerRouter struct {
+	mu      sync.Mutex
+	target  *ttsCallbackHandler
+	closed  bool
+	cleared chan struct{}
+
+	// metadata is the connection's last Metadata message, shown to each
+	// session that attaches later, since Deepgram sends it only once
+	metadata []byte
+}
+
+// attach routes callbacks to handler; nil drops them.
+func (r *handlerRouter) attach(handler *ttsCallbackHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.target = handler
+	if handler != nil && r.metadata != nil {
+		handler.rawMetadata(r.metadata)
+	}
+}
+
+// rawMetadata records a Metadata message for the connection and shows it
+// to the current session.
+func (r *handlerRouter) rawMetadata(msg []byte) {
+	if _, ok := parseSpeakMetadata(msg); !ok {
+		return
+	}
+
+	r.mu.Lock()
+	r.metadata = slices.Clone(msg)
+	h := r.target
+	r.mu.Unlock()
+
+	if h != nil {
+		h.rawMetadata(msg)
+	}
+}
+
+// expectClear returns a channel closed by the next Clear callback.
+func (r *handlerRouter) expectClear() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cleared = make(chan struct{})
+	return r.cleared
+}
+
+// isClosed reports whether Deepgram has closed the connection or reported
+// an error on it.
+func (r *handlerRouter) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// current returns the handler of the session using the connection, or nil.
+func (r *handlerRouter) current() *ttsCallbackHandler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.target
+}
+
+// Open is called when the connection is established.
+func (r *handlerRouter) Open(or *wsinterfaces.OpenResponse) error {
+	return nil
+}
+
+// Metadata is called when metadata is received.
+func (r *handlerRouter) Metadata(md *wsinterfaces.MetadataResponse) error {
+	if h := r.current(); h != nil {
+		return h.Metadata(md)
+	}
+	return nil
+}
+
+// Flush is called when a flush response is received.
+func (r *handlerRouter) Flush(fr *wsinterfaces.FlushedResponse) error {
+	if h := r.current(); h != nil {
+		return h.Flush(fr)
+	}
+	return nil
+}
+
+// Clear is called when a clear response is received.
+func (r *handlerRouter) Clear(cr *wsinterfaces.ClearedResponse) error {
+	r.mu.Lock()
+	if r.cleared != nil {
+		close(r.cleared)
+		r.cleared = nil
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// Close is called when the connection is closed.
+func (r *handlerRouter) Close(cr *wsinterfaces.CloseResponse) error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	if h := r.current(); h != nil {
+		return h.Close(cr)
+	}
+	return nil
+}
+
+// Warning is called when a warning is received.
+func (r *handlerRouter) Warning(wr *wsinterfaces.WarningResponse) error {
+	if h := r.current(); h != nil {
+		return h.Warning(wr)
+	}
+	return nil
+}
+
+// Error is called when an error occurs. The connection is not reused.
+func (r *handlerRouter) Error(er *wsinterfaces.ErrorResponse) error {
+	r.mu.Lock()
+	r.closed = true
+	h := r.target
+	r.mu.Unlock()
+
+	if h != nil {
+		return h.Error(er)
+	}
+	return nil
+}
+
+// UnhandledEvent is called for unhandled events.
+func (r *handlerRouter) UnhandledEvent(raw []byte) error {
+	return nil
+}
+
+// Binary is called when audio data is received.
+func (r *handlerRouter) Binary(data []byte) error {
+	if h := r.current(); h != nil {
+		return h.Binary(data)
+	}
+	return nil
+}
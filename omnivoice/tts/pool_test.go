@@ -0,0 +1,211 @@
+package tts
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+// pooledProvider returns a provider with a connection pool whose dialer
+// records every connection it opens.
+func pooledProvider(t *testing.T, size int, maxIdle time.Duration) (*Provider, func() []*fakeSpeakClient) {
+	t.Helper()
+	p, err := New(WithAPIKey("test-key"), WithConnectionPool(size, maxIdle))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var dialed []*fakeSpeakClient
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fake := &fakeSpeakClient{handler: handler}
+		dialed = append(dialed, fake)
+		return fake, nil
+	}
+	return p, func() []*fakeSpeakClient {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(dialed)
+	}
+}
+
+// synthesizeOnce runs a SynthesizeStream session to its final chunk and
+// returns the audio received.
+func synthesizeOnce(t *testing.T, p *Provider, text string, config tts.SynthesisConfig) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunks, err := p.SynthesizeStream(ctx, text, config)
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+
+	var audio string
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		audio += string(chunk.Audio)
+		if chunk.IsFinal {
+			break
+		}
+	}
+
+	// The connection is back in the pool once the channel closes
+	cancel()
+	for range chunks {
+	}
+	return audio
+}
+
+func TestSynthesizeStream_PoolReuse(t *testing.T) {
+	p, dialed := pooledProvider(t, 2, 0)
+
+	first := synthesizeOnce(t, p, "Hello there.", tts.SynthesisConfig{})
+	second := synthesizeOnce(t, p, "General Kenobi.", tts.SynthesisConfig{})
+	if first != "Hello there." || second != "General Kenobi." {
+		t.Errorf("audio = %q, %q; want each session's own text", first, second)
+	}
+
+	conns := dialed()
+	if len(conns) != 1 {
+		t.Fatalf("dialed %d connections, want 1 reused", len(conns))
+	}
+	if got := conns[0].texts; !slices.Equal(got, []string{"Hello there.", "General Kenobi."}) {
+		t.Errorf("texts = %q, want both sessions on one connection", got)
+	}
+
+	// A different voice needs its own connection
+	synthesizeOnce(t, p, "Hi.", tts.SynthesisConfig{VoiceID: "aura-2-thalia-en"})
+	if got := len(dialed()); got != 2 {
+		t.Errorf("dialed %d connections, want 2 after a voice change", got)
+	}
+	if got := p.pool.idleCount(); got != 2 {
+		t.Errorf("idle connections = %d, want 2", got)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for i, conn := range dialed() {
+		if conn.stops != 1 {
+			t.Errorf("connection %d stopped %d times by Close, want 1", i, conn.stops)
+		}
+	}
+}
+
+func TestSynthesizeStream_PoolSkipsClosed(t *testing.T) {
+	p, dialed := pooledProvider(t, 2, 0)
+
+	synthesizeOnce(t, p, "Hello there.", tts.SynthesisConfig{})
+	_ = dialed()[0].handler.Close(&wsinterfaces.CloseResponse{})
+
+	synthesizeOnce(t, p, "General Kenobi.", tts.SynthesisConfig{})
+	conns := dialed()
+	if len(conns) != 2 {
+		t.Fatalf("dialed %d connections, want a new one after a close", len(conns))
+	}
+	if conns[0].stops != 1 {
+		t.Errorf("closed connection stopped %d times, want 1", conns[0].stops)
+	}
+}
+
+// pooledFake returns a connection for a connPool test.
+func pooledFake(key string) (*pooledConn, *fakeSpeakClient) {
+	router := &handlerRouter{}
+	fake := &fakeSpeakClient{handler: router}
+	return &pooledConn{client: fake, router: router, key: key}, fake
+}
+
+func TestConnPool_Bounds(t *testing.T) {
+	pool := newConnPool(1, 0)
+
+	a, fakeA := pooledFake("k")
+	b, fakeB := pooledFake("k")
+	pool.put(a)
+	pool.put(b)
+
+	if got := pool.idleCount(); got != 1 {
+		t.Errorf("idle connections = %d, want 1", got)
+	}
+	if fakeA.stops != 0 || fakeB.stops != 1 {
+		t.Errorf("stops = %d, %d; want only the connection over the bound stopped", fakeA.stops, fakeB.stops)
+	}
+	if pool.get("other") != nil {
+		t.Error("get() returned a connection for another key")
+	}
+	if pool.get("k") != a {
+		t.Error("get() did not return the pooled connection")
+	}
+	if newConnPool(0, 0) != nil {
+		t.Error("newConnPool(0) != nil, want pooling disabled")
+	}
+}
+
+func TestConnPool_EvictsStale(t *testing.T) {
+	pool := newConnPool(2, time.Minute)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	conn, fake := pooledFake("k")
+	pool.put(conn)
+
+	now = now.Add(2 * time.Minute)
+	if pool.get("k") != nil {
+		t.Error("get() returned a stale connection")
+	}
+	if fake.stops != 1 {
+		t.Errorf("stale connection stopped %d times, want 1", fake.stops)
+	}
+}
+
+func TestConnPool_Close(t *testing.T) {
+	pool := newConnPool(2, 0)
+
+	a, fakeA := pooledFake("k")
+	b, fakeB := pooledFake("k")
+	pool.put(a)
+	pool.close()
+	pool.put(b)
+
+	if got := pool.idleCount(); got != 0 {
+		t.Errorf("idle connections = %d, want 0 after close", got)
+	}
+	if fakeA.stops != 1 || fakeB.stops != 1 {
+		t.Errorf("stops = %d, %d; want the idle and the returned connection stopped once", fakeA.stops, fakeB.stops)
+	}
+}
+
+func TestRelease_ClearsIncompleteSession(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithConnectionPool(1, 0))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	conn, fake := pooledFake("k")
+	conn.router.attach(&ttsCallbackHandler{})
+	p.release(conn, false)
+
+	deadline := time.Now().Add(time.Second)
+	for p.pool.idleCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if p.pool.idleCount() != 1 {
+		t.Fatal("connection was not returned to the pool after clearing")
+	}
+	if fake.resets != 1 {
+		t.Errorf("resets = %d, want 1", fake.resets)
+	}
+	if conn.router.current() != nil {
+		t.Error("released connection still routes to the session")
+	}
+}
@@ -2,13 +2,17 @@
 package tts
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	speakapi "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/rest"
 	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
@@ -26,17 +30,54 @@ var (
 
 // Provider implements tts.Provider using the Deepgram API.
 type Provider struct {
-	apiKey string
-	client *speakapi.Client
+	apiKey        string
+	client        *speakapi.Client
+	modelFallback []string
+	breaker       *omnivoice.CircuitBreaker
+	httpClient    *http.Client
+	observer      func(any)
+	rawChunking   bool
+	minChunk      int
+	idleFlush     time.Duration
+	defaultFormat string
+	endpoint      omnivoice.Endpoint
+
+	// pool holds idle streaming connections; nil unless pooling is enabled
+	pool *connPool
+
+	// dial opens a connected streaming client; replaced in tests
+	dial func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error)
 
 	mu sync.Mutex
 }
 
+// SpeakClient is the subset of the Deepgram TTS WebSocket client used by
+// the streaming methods.
+type SpeakClient interface {
+	SpeakWithText(text string) error
+	Flush() error
+	Reset() error
+	Stop()
+}
+
 // Option configures the Provider.
 type Option func(*options)
 
 type options struct {
-	apiKey string
+	apiKey           string
+	modelFallback    []string
+	failureThreshold int
+	resetTimeout     time.Duration
+	httpClient       *http.Client
+	observer         func(any)
+	rawChunking      bool
+	minChunk         int
+	idleFlush        time.Duration
+	defaultFormat    string
+	poolSize         int
+	poolMaxIdle      time.Duration
+	baseURL          string
+	baseURLSet       bool
 }
 
 // WithAPIKey sets the Deepgram API key.
@@ -46,6 +87,122 @@ func WithAPIKey(apiKey string) Option {
 	}
 }
 
+// WithModelFallback sets voice models to try in order when Deepgram rejects
+// the requested model in Synthesize. Only model errors fall back;
+// authentication, network, and other failures are returned immediately.
+func WithModelFallback(models []string) Option {
+	return func(o *options) {
+		o.modelFallback = models
+	}
+}
+
+// WithCircuitBreaker fails Synthesize and SynthesizeDialogue requests fast with
+// omnivoice.ErrCircuitOpen after failureThreshold consecutive Deepgram
+// failures, letting a trial request through once resetTimeout has passed.
+// See omnivoice.CircuitBreaker for what counts as a failure. Streaming
+// sessions are not affected.
+func WithCircuitBreaker(failureThreshold int, resetTimeout time.Duration) Option {
+	return func(o *options) {
+		o.failureThreshold = failureThreshold
+		o.resetTimeout = resetTimeout
+	}
+}
+
+// WithHTTPClient sends the provider's REST requests with hc, for custom
+// timeouts, proxies, TLS settings, or a RoundTripper that intercepts
+// requests in tests. The SDK's ClientOptions has no field for a client, so
+// hc replaces the one the SDK builds; see omnivoice.UseHTTPClient.
+// WebSocket streaming connections do not use it. A nil hc keeps the
+// default client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = hc
+	}
+}
+
+// WithSharedClient sends the provider's REST requests with hc. Passing the
+// same client to the STT and TTS providers makes them share one transport
+// and connection pool instead of each building their own. It is
+// WithHTTPClient under a name for that use.
+func WithSharedClient(hc *http.Client) Option {
+	return WithHTTPClient(hc)
+}
+
+// WithObserver calls fn with every chunk of every streaming synthesis, as a
+// tts.StreamChunk, once it has been delivered to the stream's channel;
+// chunks dropped because the stream has ended are not seen. It is meant
+// for centralized logging and metrics. fn runs inline on the
+// goroutine delivering Deepgram's audio, so it must return quickly; it may
+// be called concurrently for different streams.
+func WithObserver(fn func(any)) Option {
+	return func(o *options) {
+		o.observer = fn
+	}
+}
+
+// WithRawChunking makes SynthesizeFromReader and
+// SynthesizeFromReaderWithFlush treat the text of each Read as a complete
+// unit: it is trimmed, sent, and flushed on its own instead of being
+// buffered and split into sentences. This suits callers that already
+// segment their text, since it avoids waiting for sentence ends and never
+// merges or splits their units. Reads of only whitespace are skipped.
+// Sentence splitting is the default.
+func WithRawChunking(enabled bool) Option {
+	return func(o *options) {
+		o.rawChunking = enabled
+	}
+}
+
+// WithMinChunkLength makes SynthesizeFromReader and
+// SynthesizeFromReaderWithFlush hold complete sentences back until at
+// least n characters of them are buffered, and then send them as one unit,
+// so that runs of short sentences are not synthesized as separate, choppy
+// pieces. Held text is still sent by an explicit or idle flush and at EOF.
+// Zero, the default, sends each sentence as soon as it ends. It has no
+// effect with WithRawChunking.
+func WithMinChunkLength(n int) Option {
+	return func(o *options) {
+		o.minChunk = n
+	}
+}
+
+// WithIdleFlush makes SynthesizeFromReader and
+// SynthesizeFromReaderWithFlush flush buffered text, including an
+// incomplete sentence, once the reader has provided no new text for d, so
+// that a source pausing mid-sentence, such as an LLM waiting on a tool
+// call, is still heard. Zero, the default, waits for the sentence to end.
+func WithIdleFlush(d time.Duration) Option {
+	return func(o *options) {
+		o.idleFlush = d
+	}
+}
+
+// WithDefaultOutputFormat sets the output format used when a
+// SynthesisConfig does not specify one, such as "mulaw" for an application
+// that only handles telephony audio. When it applies and the config sets no
+// SampleRate, Deepgram's default rate for the format is used, so mulaw
+// defaults to 8000 Hz. A format set on the config always wins. Defaults to
+// linear16.
+func WithDefaultOutputFormat(format string) Option {
+	return func(o *options) {
+		o.defaultFormat = format
+	}
+}
+
+// WithBaseURL sends the provider's requests to the Deepgram deployment at
+// rawURL instead of Deepgram's hosted API, for self-hosted servers. rawURL
+// is a scheme, host, and optional port, such as "https://dg.internal:8443";
+// see omnivoice.ParseBaseURL. New fails if it is empty or malformed. It
+// applies to REST requests and streaming connections alike. The
+// DEEPGRAM_HOST environment variable, which the SDK reads when building
+// each client, still takes precedence.
+func WithBaseURL(rawURL string) Option {
+	return func(o *options) {
+		o.baseURL = rawURL
+		o.baseURLSet = true
+	}
+}
+
 // New creates a new Deepgram TTS provider.
 func New(opts ...Option) (*Provider, error) {
 	cfg := &options{}
@@ -57,17 +214,73 @@ func New(opts ...Option) (*Provider, error) {
 		return nil, fmt.Errorf("API key is required")
 	}
 
+	var endpoint omnivoice.Endpoint
+	if cfg.baseURLSet {
+		var err error
+		if endpoint, err = omnivoice.ParseBaseURL(cfg.baseURL); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the Deepgram client library (shared across STT/TTS)
 	omnivoice.InitSDK()
 
 	// Create REST client with empty options (not nil)
-	restClient := speak.NewREST(cfg.apiKey, &interfaces.ClientOptions{})
+	restClient := speak.NewREST(cfg.apiKey, endpoint.RESTOptions())
+	omnivoice.UseHTTPClient(restClient.HTTPClient, cfg.httpClient)
 	client := speakapi.New(restClient)
 
-	return &Provider{
-		apiKey: cfg.apiKey,
-		client: client,
-	}, nil
+	p := &Provider{
+		apiKey:        cfg.apiKey,
+		client:        client,
+		modelFallback: cfg.modelFallback,
+		breaker:       omnivoice.NewCircuitBreaker(cfg.failureThreshold, cfg.resetTimeout),
+		httpClient:    cfg.httpClient,
+		observer:      cfg.observer,
+		rawChunking:   cfg.rawChunking,
+		minChunk:      cfg.minChunk,
+		idleFlush:     cfg.idleFlush,
+		defaultFormat: cfg.defaultFormat,
+		endpoint:      endpoint,
+		pool:          newConnPool(cfg.poolSize, cfg.poolMaxIdle),
+	}
+	p.dial = p.dialDeepgram
+
+	return p, nil
+}
+
+// errConnect is returned when the WebSocket connection cannot be established.
+var errConnect = errors.New("connection failed")
+
+// errorf wraps err with msg, annotating it with the correlation ID carried
+// by ctx, if any.
+func errorf(ctx context.Context, msg string, err error) error {
+	if id := omnivoice.CorrelationIDFromContext(ctx); id != "" {
+		return fmt.Errorf("%s [correlation_id=%s]: %w", msg, id, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// dialDeepgram creates a Deepgram TTS WebSocket client and connects it.
+func (p *Provider) dialDeepgram(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+	wsClient, err := newSpeakClient(ctx, p.apiKey, p.endpoint.WSOptions(), opts, handler)
+	if err != nil {
+		return nil, errorf(ctx, "failed to create Deepgram TTS client", err)
+	}
+
+	if !wsClient.Connect() {
+		return nil, errorf(ctx, "failed to connect to Deepgram TTS", errConnect)
+	}
+
+	return wsClient, nil
+}
+
+// withDefaults fills fields left empty in config with the provider defaults.
+func (p *Provider) withDefaults(config tts.SynthesisConfig) tts.SynthesisConfig {
+	if config.OutputFormat == "" {
+		config.OutputFormat = p.defaultFormat
+	}
+	return config
 }
 
 // Name returns the provider name.
@@ -76,42 +289,122 @@ func (p *Provider) Name() string {
 }
 
 // Synthesize converts text to speech and returns audio data.
+//
+// The result's Format is the Deepgram encoding the request was sent with,
+// such as "mulaw" for an OutputFormat of "ulaw", or "ogg_opus" for Opus,
+// and its SampleRate is the rate of the returned audio, accounting for
+// Deepgram's defaults and fixed-rate encodings.
+//
+// An OutputFormat of "wav" returns mono 16-bit linear PCM behind a 44-byte
+// RIFF/WAVE header, with a Format of "wav". Streaming output is always
+// headerless; see StreamToWAV.
+//
+// text is sent to Deepgram exactly as given. Deepgram's speak API has no
+// markup mode and does not interpret SSML, so tags are not a way to shape
+// the speech: pauses follow punctuation, such as commas, ellipses, and
+// sentence ends, and pronunciation follows spelling, so words can be
+// respelled phonetically. The same holds for the streaming methods.
 func (p *Provider) Synthesize(ctx context.Context, text string, config tts.SynthesisConfig) (*tts.SynthesisResult, error) {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return nil, err
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	// Convert config to Deepgram options
 	opts := omnivoice.ConfigToSpeakOptions(config)
 
-	// Get audio into buffer
-	var buffer interfaces.RawResponse
-	resp, err := p.client.ToStream(ctx, text, opts, &buffer)
-	if err != nil {
-		return nil, fmt.Errorf("deepgram TTS failed: %w", err)
+	// WAV output is requested as bare linear16 and given its header here,
+	// so that the header always describes the audio returned
+	wav := config.OutputFormat == "wav"
+	if wav {
+		opts.Container = "none"
 	}
 
-	// Determine output format
-	outputFormat := config.OutputFormat
-	if outputFormat == "" {
-		outputFormat = "linear16"
+	// Get audio into buffer
+	audio, chars, err := p.synthesize(omnivoice.SpeakContext(ctx, config), text, opts)
+	if err != nil {
+		return nil, errorf(ctx, "deepgram TTS failed", err)
 	}
 
-	// Determine sample rate
-	sampleRate := config.SampleRate
-	if sampleRate == 0 {
-		sampleRate = 24000 // Deepgram default
+	// Report the encoding actually requested, not the config's alias for it
+	outputFormat := opts.Encoding
+	switch {
+	case opts.Container == "ogg":
+		outputFormat = "ogg_opus"
+	case wav:
+		info, _ := omnivoice.NewWAVInfo(opts.Encoding, omnivoice.EffectiveSampleRate(opts))
+		info.DataSize = int64(len(audio))
+		audio = append(info.Header(), audio...)
+		outputFormat = "wav"
 	}
 
 	return &tts.SynthesisResult{
-		Audio:          buffer.Bytes(),
+		Audio:          audio,
 		Format:         outputFormat,
-		SampleRate:     sampleRate,
-		CharacterCount: resp.Characters,
+		SampleRate:     omnivoice.EffectiveSampleRate(opts),
+		CharacterCount: chars,
 	}, nil
 }
 
-// SynthesizeStream converts text to speech with streaming output.
+// synthesize converts text to speech with opts, retrying down the fallback
+// chain while Deepgram rejects the model, and returns the audio and the
+// number of characters billed.
+func (p *Provider) synthesize(ctx context.Context, text string, opts *interfaces.SpeakOptions) ([]byte, int, error) {
+	if err := p.breaker.Allow(); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		buffer interfaces.RawResponse
+		chars  int
+		err    error
+	)
+	for _, model := range omnivoice.ModelChain(opts.Model, p.modelFallback) {
+		opts.Model = model
+		buffer.Reset()
+		chars, err = p.speak(ctx, text, opts, &buffer)
+		if !omnivoice.IsModelError(err) {
+			break
+		}
+	}
+	p.breaker.Record(err)
+	if err != nil {
+		return nil, 0, err
+	}
+	return buffer.Bytes(), chars, nil
+}
+
+// SynthesizeStream converts text to speech with streaming output. The
+// channel is closed once all of the audio has been delivered, after the
+// chunk with IsFinal set, or when Deepgram closes the connection or ctx is
+// done, whichever comes first.
+//
+// To deliver linear16 audio at a different rate than Deepgram produces, set
+// omnivoice.ExtOutputSampleRate in config.Extensions; each chunk is then
+// resampled before it is sent. See omnivoice.Resampler for the quality
+// trade-offs.
+//
+// With WithConnectionPool, the session reuses an idle connection opened
+// with the same options when there is one.
 func (p *Provider) SynthesizeStream(ctx context.Context, text string, config tts.SynthesisConfig) (<-chan tts.StreamChunk, error) {
+	handler, err := p.synthesizeStream(ctx, text, config)
+	if err != nil {
+		return nil, err
+	}
+	return handler.chunkCh, nil
+}
+
+// synthesizeStream starts a SynthesizeStream session and returns its
+// handler, whose channel carries the session's chunks.
+func (p *Provider) synthesizeStream(ctx context.Context, text string, config tts.SynthesisConfig) (*ttsCallbackHandler, error) {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return nil, err
+	}
+
 	// Convert config to Deepgram WebSocket options
 	opts := omnivoice.ConfigToWSSpeakOptions(config)
 
@@ -119,52 +412,172 @@ func (p *Provider) SynthesizeStream(ctx context.Context, text string, config tts
 
 	// Create callback handler
 	handler := &ttsCallbackHandler{
-		chunkCh: chunkCh,
-		ctx:     ctx,
+		chunkCh:   chunkCh,
+		ctx:       ctx,
+		resampler: outputResampler(config, opts),
+		observer:  p.observer,
+		flushed:   make(chan struct{}, 1),
+		closedBy:  make(chan struct{}, 1),
+		done:      make(chan struct{}),
 	}
 
-	// Create WebSocket client with callback and API key
-	wsClient, err := speak.NewWSUsingCallback(ctx, p.apiKey, &interfaces.ClientOptions{}, opts, handler)
-	if err != nil {
-		close(chunkCh)
-		return nil, fmt.Errorf("failed to create Deepgram TTS client: %w", err)
+	if p.pool != nil {
+		if err := p.synthesizePooled(ctx, text, config, opts, handler); err != nil {
+			return nil, err
+		}
+		return handler, nil
 	}
 
 	// Connect to Deepgram
-	if !wsClient.Connect() {
+	wsClient, err := p.dial(omnivoice.SpeakContext(ctx, config), opts, handler)
+	if err != nil {
 		close(chunkCh)
-		return nil, fmt.Errorf("failed to connect to Deepgram TTS")
+		return nil, err
 	}
 
 	// Send text and manage connection in goroutine
 	go func() {
 		defer func() {
-			wsClient.Finish()
-			handler.mu.Lock()
-			if !handler.closed {
-				handler.closed = true
-				close(chunkCh)
-			}
-			handler.mu.Unlock()
+			handler.close()
+			wsClient.Stop()
 		}()
 
 		// Send text
 		if err := wsClient.SpeakWithText(text); err != nil {
-			handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to send text: %w", err)})
+			handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to send text", err)})
 			return
 		}
 
 		// Flush to signal end of input
 		if err := wsClient.Flush(); err != nil {
-			handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to flush: %w", err)})
+			handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to flush", err)})
+			return
+		}
+
+		// Wait for the audio to be delivered, unless the session ends first
+		select {
+		case <-handler.flushed:
+		case <-handler.closedBy:
+		case <-ctx.Done():
+		}
+	}()
+
+	return handler, nil
+}
+
+// synthesizePooled runs a SynthesizeStream session on a pooled connection,
+// returning the connection to the pool once the audio has been delivered
+// or ctx is done. Like an unpooled session, the channel is closed then.
+func (p *Provider) synthesizePooled(ctx context.Context, text string, config tts.SynthesisConfig, opts *interfaces.WSSpeakOptions, handler *ttsCallbackHandler) error {
+	conn, err := p.streamConn(ctx, config, opts, handler)
+	if err != nil {
+		close(handler.chunkCh)
+		return err
+	}
+
+	go func() {
+		defer func() {
+			handler.close()
+		}()
+
+		// A connection that fails to send is not reused
+		if err := conn.client.SpeakWithText(text); err != nil {
+			conn.router.attach(nil)
+			conn.client.Stop()
+			handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to send text", err)})
+			return
+		}
+		if err := conn.client.Flush(); err != nil {
+			conn.router.attach(nil)
+			conn.client.Stop()
+			handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to flush", err)})
 			return
 		}
 
-		// Wait for context cancellation or flush completion
-		<-ctx.Done()
+		// A flush that arrived as ctx ended still leaves nothing to clear
+		complete := false
+		select {
+		case <-handler.flushed:
+			complete = true
+		case <-handler.closedBy:
+		case <-ctx.Done():
+			select {
+			case <-handler.flushed:
+				complete = true
+			default:
+			}
+		}
+		p.release(conn, complete)
 	}()
 
-	return chunkCh, nil
+	return nil
+}
+
+// streamSampleRate returns the sample rate Deepgram streams audio at for opts.
+func streamSampleRate(opts *interfaces.WSSpeakOptions) int {
+	return omnivoice.EffectiveSampleRate(&interfaces.SpeakOptions{
+		Encoding:   opts.Encoding,
+		SampleRate: opts.SampleRate,
+	})
+}
+
+// outputResampler returns a resampler from Deepgram's streaming rate to the
+// rate set with omnivoice.ExtOutputSampleRate, or nil if none is set.
+func outputResampler(config tts.SynthesisConfig, opts *interfaces.WSSpeakOptions) *omnivoice.Resampler {
+	rate := omnivoice.OutputSampleRate(config)
+	if rate <= 0 {
+		return nil
+	}
+	return omnivoice.NewResampler(streamSampleRate(opts), rate)
+}
+
+// SynthesizeStreamWithTiming is like SynthesizeStream, but annotates each
+// chunk with its playback offset and duration so that consumers can
+// schedule audio, for example to synchronize captions, and with the
+// encoding and sample rate of the audio as delivered. Timing is only
+// available for linear16, mulaw, and alaw output; see omnivoice.StreamChunk.
+func (p *Provider) SynthesizeStreamWithTiming(ctx context.Context, text string, config tts.SynthesisConfig) (<-chan omnivoice.StreamChunk, error) {
+	config = p.withDefaults(config)
+
+	handler, err := p.synthesizeStream(ctx, text, config)
+	if err != nil {
+		return nil, err
+	}
+	chunks := handler.chunkCh
+
+	opts := omnivoice.ConfigToWSSpeakOptions(config)
+	outputRate := omnivoice.OutputSampleRate(config)
+
+	timedCh := make(chan omnivoice.StreamChunk, cap(chunks))
+	go func() {
+		defer close(timedCh)
+
+		var received int
+		for chunk := range chunks {
+			encoding, sampleRate := handler.deliveredFormat(opts.Encoding, streamSampleRate(opts))
+			if outputRate > 0 {
+				sampleRate = outputRate
+			}
+			timed := omnivoice.StreamChunk{
+				StreamChunk: chunk,
+				Encoding:    encoding,
+				SampleRate:  sampleRate,
+			}
+			if start, ok := omnivoice.PCMDuration(encoding, sampleRate, received); ok {
+				received += len(chunk.Audio)
+				end, _ := omnivoice.PCMDuration(encoding, sampleRate, received)
+				timed.Offset = start
+				timed.Duration = end - start
+			}
+
+			select {
+			case timedCh <- timed:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return timedCh, nil
 }
 
 // ListVoices returns available voices from this provider.
@@ -187,10 +600,192 @@ func (p *Provider) GetVoice(ctx context.Context, voiceID string) (*tts.Voice, er
 	return nil, tts.ErrVoiceNotFound
 }
 
+// SearchVoices returns the voices whose name or ID contains query,
+// ignoring case. Results are ordered by relevance: exact matches first,
+// then prefix matches, then other substring matches, keeping the catalog
+// order within each tier. An empty query matches every voice.
+func (p *Provider) SearchVoices(ctx context.Context, query string) ([]tts.Voice, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var tiers [3][]tts.Voice
+	for _, v := range omnivoice.DeepgramVoices {
+		rank, ok := matchVoice(v, query)
+		if !ok {
+			continue
+		}
+		tiers[rank] = append(tiers[rank], omnivoice.VoiceToOmniVoice(v))
+	}
+
+	voices := make([]tts.Voice, 0, len(tiers[0])+len(tiers[1])+len(tiers[2]))
+	for _, tier := range tiers {
+		voices = append(voices, tier...)
+	}
+	return voices, nil
+}
+
+// VoiceFilter selects voices by language and gender for
+// ListVoicesFiltered. Empty fields match every voice, and matching ignores
+// case.
+type VoiceFilter struct {
+	// Language is a BCP-47 language tag or a prefix of one ending at a
+	// subtag, so "en" matches "en-US" and "en-GB" but "e" matches neither.
+	Language string
+
+	// Gender is "male", "female", or "neutral".
+	Gender string
+}
+
+// ListVoicesFiltered returns the voices ListVoices would, keeping only
+// those that match filter, in catalog order.
+func (p *Provider) ListVoicesFiltered(ctx context.Context, filter VoiceFilter) ([]tts.Voice, error) {
+	voices := []tts.Voice{}
+	for _, v := range omnivoice.DeepgramVoices {
+		if filter.matches(v) {
+			voices = append(voices, omnivoice.VoiceToOmniVoice(v))
+		}
+	}
+	return voices, nil
+}
+
+// matches reports whether v passes the filter.
+func (f VoiceFilter) matches(v omnivoice.Voice) bool {
+	if f.Gender != "" && !strings.EqualFold(v.Gender, f.Gender) {
+		return false
+	}
+	if f.Language == "" {
+		return true
+	}
+	language, want := strings.ToLower(v.Language), strings.ToLower(f.Language)
+	return language == want || strings.HasPrefix(language, want+"-")
+}
+
+// matchVoice ranks how well a voice matches a lowercase query:
+// 0 for an exact name or ID match, 1 for a prefix match, 2 for a substring.
+func matchVoice(v omnivoice.Voice, query string) (int, bool) {
+	name, id := strings.ToLower(v.Name), strings.ToLower(v.ID)
+	switch {
+	case name == query || id == query:
+		return 0, true
+	case strings.HasPrefix(name, query) || strings.HasPrefix(id, query):
+		return 1, true
+	case strings.Contains(name, query) || strings.Contains(id, query):
+		return 2, true
+	default:
+		return 0, false
+	}
+}
+
+// deadlineFlushMargin is how long before the context deadline
+// SynthesizeFromReader stops reading and flushes buffered text, leaving
+// Deepgram time to return its audio.
+const deadlineFlushMargin = 500 * time.Millisecond
+
+// readResult is one read from the text source of SynthesizeFromReader.
+type readResult struct {
+	text string
+	err  error
+}
+
+// voiceSwitchTimeout bounds how long SetVoice waits for Deepgram to
+// finish the audio of the previous voice before reconnecting.
+const voiceSwitchTimeout = 10 * time.Second
+
+// ReaderStream is a handle to a SynthesizeFromReaderWithFlush session.
+type ReaderStream struct {
+	flushReq chan chan error
+	voiceReq chan voiceRequest
+	done     chan struct{}
+}
+
+// voiceRequest asks a reader session to switch to voice.
+type voiceRequest struct {
+	voice string
+	reply chan error
+}
+
+// Flush sends the text read so far to Deepgram, even if it does not end a
+// sentence, and asks Deepgram to synthesize it. Use it at logical
+// boundaries known before EOF, such as the end of an LLM response. Audio
+// for the flushed text is followed by a chunk with IsFinal set.
+//
+// Text counts as read once the reader has returned it; text still inside
+// the reader is not flushed. Flush returns io.ErrClosedPipe once the
+// session has ended.
+func (s *ReaderStream) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case s.flushReq <- reply:
+	case <-s.done:
+		return io.ErrClosedPipe
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-s.done:
+		return io.ErrClosedPipe
+	}
+}
+
+// SetVoice switches the session to voiceID, a Deepgram voice model such as
+// "aura-2-thalia-en", for all text read from then on, without ending the
+// session or its chunk channel.
+//
+// Deepgram fixes the voice of a streaming connection when it is opened, so
+// SetVoice reconnects: it flushes text already read, as Flush does, waits
+// for its audio in the old voice, then opens a new connection with the new
+// voice and closes the old one. If the new connection cannot be opened,
+// SetVoice returns the error and the session continues with the old voice.
+// SetVoice returns io.ErrClosedPipe once the session has ended.
+func (s *ReaderStream) SetVoice(voiceID string) error {
+	if voiceID == "" {
+		return fmt.Errorf("%w: voice ID must not be empty", tts.ErrInvalidConfig)
+	}
+
+	reply := make(chan error, 1)
+	select {
+	case s.voiceReq <- voiceRequest{voice: voiceID, reply: reply}:
+	case <-s.done:
+		return io.ErrClosedPipe
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-s.done:
+		return io.ErrClosedPipe
+	}
+}
+
 // SynthesizeFromReader reads text from a reader and streams audio output.
 // This is useful for streaming LLM output directly to TTS.
-// Text is buffered and split into sentences for natural speech synthesis.
+// Text is buffered and split into sentences for natural speech synthesis,
+// unless the provider was created with WithRawChunking.
+//
+// If ctx has a deadline, reading stops shortly before it and any buffered
+// text is flushed, so that a slow reader does not cause text to be lost to a
+// cancellation mid-write.
+//
+// The channel is closed once the audio for all of the text has been
+// delivered after EOF or the deadline flush, or when Deepgram closes the
+// connection or ctx is done.
+//
+// To flush buffered text explicitly before EOF, use
+// SynthesizeFromReaderWithFlush.
 func (p *Provider) SynthesizeFromReader(ctx context.Context, reader io.Reader, config tts.SynthesisConfig) (<-chan tts.StreamChunk, error) {
+	_, chunks, err := p.SynthesizeFromReaderWithFlush(ctx, reader, config)
+	return chunks, err
+}
+
+// SynthesizeFromReaderWithFlush is like SynthesizeFromReader, but also
+// returns a ReaderStream whose Flush method forces buffered text to be
+// synthesized without waiting for the end of a sentence, and whose SetVoice
+// method changes the voice mid-session. Sentences are still sent
+// automatically as they complete.
+func (p *Provider) SynthesizeFromReaderWithFlush(ctx context.Context, reader io.Reader, config tts.SynthesisConfig) (*ReaderStream, <-chan tts.StreamChunk, error) {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return nil, nil, err
+	}
+
 	// Convert config to Deepgram WebSocket options
 	opts := omnivoice.ConfigToWSSpeakOptions(config)
 
@@ -198,75 +793,215 @@ func (p *Provider) SynthesizeFromReader(ctx context.Context, reader io.Reader, c
 
 	// Create callback handler
 	handler := &ttsCallbackHandler{
-		chunkCh: chunkCh,
-		ctx:     ctx,
+		chunkCh:   chunkCh,
+		ctx:       ctx,
+		resampler: outputResampler(config, opts),
+		observer:  p.observer,
+		flushed:   make(chan struct{}, 1),
+		closedBy:  make(chan struct{}, 1),
+		done:      make(chan struct{}),
 	}
 
-	// Create WebSocket client with callback and API key
-	wsClient, err := speak.NewWSUsingCallback(ctx, p.apiKey, &interfaces.ClientOptions{}, opts, handler)
+	// Connect to Deepgram. Each connection has its own router, so that the
+	// callbacks of a connection replaced by SetVoice are dropped.
+	router := &handlerRouter{}
+	router.attach(handler)
+	wsClient, err := p.dial(omnivoice.SpeakContext(ctx, config), opts, router)
 	if err != nil {
 		close(chunkCh)
-		return nil, fmt.Errorf("failed to create Deepgram TTS client: %w", err)
+		return nil, nil, err
 	}
 
-	// Connect to Deepgram
-	if !wsClient.Connect() {
-		close(chunkCh)
-		return nil, fmt.Errorf("failed to connect to Deepgram TTS")
+	// Read text in its own goroutine so that a blocked reader cannot delay
+	// the deadline flush. Text is taken as soon as it arrives rather than a
+	// line at a time, so a partial line is not held back by the reader.
+	stream := &ReaderStream{
+		flushReq: make(chan chan error),
+		voiceReq: make(chan voiceRequest),
+		done:     make(chan struct{}),
 	}
+	done := stream.done
+	reads := make(chan readResult)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := reader.Read(buf)
+			select {
+			case reads <- readResult{text: string(buf[:n]), err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
 
 	// Process text from reader in goroutine
 	go func() {
 		defer func() {
-			wsClient.Finish()
-			handler.mu.Lock()
-			if !handler.closed {
-				handler.closed = true
-				close(chunkCh)
-			}
-			handler.mu.Unlock()
+			close(done)
+			handler.close()
+			wsClient.Stop()
 		}()
 
-		// Create a buffered reader for efficient reading
-		bufReader := bufio.NewReader(reader)
+		var flushBy <-chan time.Time
+		if deadline, ok := ctx.Deadline(); ok {
+			timer := time.NewTimer(time.Until(deadline) - deadlineFlushMargin)
+			defer timer.Stop()
+			flushBy = timer.C
+		}
+
 		var textBuffer strings.Builder
 
+		// idle fires once the reader has been quiet for the idle flush delay
+		idleTimer := time.NewTimer(p.idleFlush)
+		idleTimer.Stop()
+		defer idleTimer.Stop()
+
+		// flushes counts the flushes sent on the current connection
+		var flushes int64
+
+		// flush speaks any buffered text and asks Deepgram to synthesize it
+		flush := func() error {
+			remaining := strings.TrimSpace(textBuffer.String())
+			textBuffer.Reset()
+			if remaining != "" {
+				if err := wsClient.SpeakWithText(remaining); err != nil {
+					err = errorf(ctx, "failed to send text", err)
+					handler.sendChunk(tts.StreamChunk{Error: err})
+					return err
+				}
+			}
+			if err := wsClient.Flush(); err != nil {
+				err = errorf(ctx, "failed to flush", err)
+				handler.sendChunk(tts.StreamChunk{Error: err})
+				return err
+			}
+			flushes++
+			return nil
+		}
+
+		// await waits until Deepgram has delivered the audio for every flush
+		// sent on the current connection, unless the session ends first
+		await := func() {
+			for handler.flushes.Load() < flushes {
+				select {
+				case <-handler.flushed:
+				case <-handler.closedBy:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		// switchVoice moves the session to a new connection speaking voice,
+		// once the audio requested from the current one has arrived
+		switchVoice := func(voice string) error {
+			if err := flush(); err != nil {
+				return err
+			}
+
+			wait := time.NewTimer(voiceSwitchTimeout)
+			defer wait.Stop()
+		drain:
+			for handler.flushes.Load() < flushes {
+				select {
+				case <-handler.flushed:
+				case <-wait.C:
+					break drain
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			next := *opts
+			next.Model = voice
+			nextRouter := &handlerRouter{}
+			nextRouter.attach(handler)
+			client, err := p.dial(omnivoice.SpeakContext(ctx, config), &next, nextRouter)
+			if err != nil {
+				return fmt.Errorf("failed to switch voice: %w", err)
+			}
+
+			// Closing the previous connection does not end the session, so
+			// its callbacks, including a late close or error, are dropped
+			router.attach(nil)
+			wsClient.Stop()
+			wsClient, router, opts = client, nextRouter, &next
+			flushes = 0
+			handler.flushes.Store(0)
+			return nil
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
 				// Flush any remaining text before exit
-				if textBuffer.Len() > 0 {
-					text := strings.TrimSpace(textBuffer.String())
-					if text != "" {
-						if err := wsClient.SpeakWithText(text); err != nil {
-							handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to send text: %w", err)})
-						}
-					}
+				_ = flush()
+				return
+
+			case <-flushBy:
+				// The deadline is near; flush buffered text and wait for its audio
+				_ = flush()
+				await()
+				return
+
+			case reply := <-stream.flushReq:
+				err := flush()
+				reply <- err
+				if err != nil {
+					return
 				}
-				if err := wsClient.Flush(); err != nil {
-					handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to flush: %w", err)})
+
+			case req := <-stream.voiceReq:
+				req.reply <- switchVoice(req.voice)
+
+			case <-idleTimer.C:
+				// The reader has paused; speak what it has provided so far
+				if strings.TrimSpace(textBuffer.String()) != "" {
+					if err := flush(); err != nil {
+						return
+					}
 				}
-				return
-			default:
-				// Read a chunk of text
-				chunk, err := bufReader.ReadString('\n')
-				if err != nil && err != io.EOF {
-					handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to read text: %w", err)})
+
+			case r := <-reads:
+				if r.err != nil && r.err != io.EOF {
+					handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to read text", r.err)})
 					return
 				}
 
-				if len(chunk) > 0 {
-					textBuffer.WriteString(chunk)
+				if p.rawChunking {
+					// Each read is a complete unit; speak and flush it on its own
+					if strings.TrimSpace(r.text) != "" {
+						textBuffer.WriteString(r.text)
+						if err := flush(); err != nil {
+							return
+						}
+					}
+				} else if len(r.text) > 0 {
+					textBuffer.WriteString(r.text)
 
-					// Check if we have complete sentences to send
+					// Check if we have complete sentences to send, all but the
+					// last (potentially incomplete) one
 					sentences := splitIntoSentences(textBuffer.String())
-					if len(sentences) > 1 {
-						// Send all complete sentences except the last (potentially incomplete) one
-						for _, sentence := range sentences[:len(sentences)-1] {
+					complete := sentences[:len(sentences)-1]
+					if p.minChunk > 0 && len(complete) > 0 {
+						// Short sentences wait to be sent together
+						unit := strings.TrimSpace(strings.Join(complete, ""))
+						if utf8.RuneCountInString(unit) < p.minChunk {
+							complete = nil
+						} else {
+							complete = []string{unit}
+						}
+					}
+					if len(complete) > 0 {
+						for _, sentence := range complete {
 							sentence = strings.TrimSpace(sentence)
 							if sentence != "" {
 								if err := wsClient.SpeakWithText(sentence); err != nil {
-									handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to send text: %w", err)})
+									handler.sendChunk(tts.StreamChunk{Error: errorf(ctx, "failed to send text", err)})
 									return
 								}
 							}
@@ -275,101 +1010,246 @@ func (p *Provider) SynthesizeFromReader(ctx context.Context, reader io.Reader, c
 						textBuffer.Reset()
 						textBuffer.WriteString(sentences[len(sentences)-1])
 					}
-				}
 
-				if err == io.EOF {
-					// End of input - flush remaining text
-					remaining := strings.TrimSpace(textBuffer.String())
-					if remaining != "" {
-						if err := wsClient.SpeakWithText(remaining); err != nil {
-							handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to send text: %w", err)})
-							return
-						}
+					if p.idleFlush > 0 {
+						idleTimer.Reset(p.idleFlush)
 					}
-					if err := wsClient.Flush(); err != nil {
-						handler.sendChunk(tts.StreamChunk{Error: fmt.Errorf("failed to flush: %w", err)})
-					}
-					// Wait for flush callback to signal completion
-					<-ctx.Done()
+				}
+
+				if r.err == io.EOF {
+					// End of input - flush remaining text and wait for its audio
+					_ = flush()
+					await()
 					return
 				}
 			}
 		}
 	}()
 
-	return chunkCh, nil
+	return stream, chunkCh, nil
 }
 
 // splitIntoSentences splits text into sentences based on common delimiters.
 // Returns a slice where the last element may be an incomplete sentence.
+//
+// A sentence ends at a run of terminal punctuation, such as ".", "?!", or
+// an ellipsis, together with any closing quotes or brackets after it, when
+// whitespace or the end of the text follows. A period ending a known
+// abbreviation or an initialism such as "U.S." does not end a sentence,
+// and neither does an ellipsis followed by a lowercase word.
 func splitIntoSentences(text string) []string {
 	var sentences []string
-	var current strings.Builder
 
 	runes := []rune(text)
+	start := 0
 	for i := 0; i < len(runes); i++ {
-		r := runes[i]
-		current.WriteRune(r)
-
-		// Check for sentence-ending punctuation
-		if r == '.' || r == '!' || r == '?' {
-			// Look ahead to see if this is really the end of a sentence
-			// (not an abbreviation like "Dr." or decimal like "3.14")
-			isEndOfSentence := true
-
-			if r == '.' && i > 0 {
-				// Check if it's likely an abbreviation (single uppercase letter before dot)
-				prevRune := runes[i-1]
-				if unicode.IsUpper(prevRune) && (i < 2 || !unicode.IsLetter(runes[i-2])) {
-					isEndOfSentence = false
-				}
-				// Check if it's a number (decimal point)
-				if unicode.IsDigit(prevRune) && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
-					isEndOfSentence = false
-				}
-			}
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
 
-			// Check if followed by space or end of text
-			if isEndOfSentence && (i+1 >= len(runes) || unicode.IsSpace(runes[i+1])) {
-				sentences = append(sentences, current.String())
-				current.Reset()
-			}
+		// Take the whole run of terminators and the closing marks after it
+		stop := i + 1
+		for stop < len(runes) && isSentenceTerminator(runes[stop]) {
+			stop++
+		}
+		end := stop
+		for end < len(runes) && strings.ContainsRune(closingPunctuation, runes[end]) {
+			end++
+		}
+
+		if endsSentence(runes, i, stop, end) {
+			sentences = append(sentences, string(runes[start:end]))
+			start = end
 		}
+		i = end - 1
 	}
 
 	// Add any remaining text as the last element
-	if current.Len() > 0 {
-		sentences = append(sentences, current.String())
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
 	}
 
 	return sentences
 }
 
+// closingPunctuation holds the marks kept with the sentence they follow.
+const closingPunctuation = "\"'\u201d\u2019)]}\u00bb"
+
+// abbreviations holds lowercase abbreviations, without their final period,
+// whose period does not end a sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+	"sr": true, "jr": true, "st": true, "mt": true, "vs": true,
+	"etc": true, "e.g": true, "i.e": true, "approx": true,
+}
+
+// isSentenceTerminator reports whether r is terminal punctuation.
+func isSentenceTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '\u2026'
+}
+
+// endsSentence reports whether the terminators runes[i:stop], followed by
+// closing marks up to end, end a sentence.
+func endsSentence(runes []rune, i, stop, end int) bool {
+	if end < len(runes) && !unicode.IsSpace(runes[end]) {
+		return false
+	}
+
+	run := string(runes[i:stop])
+	switch {
+	case run == ".":
+		return !isAbbreviation(runes[:i])
+	case strings.Trim(run, ".\u2026") == "":
+		// An ellipsis may trail off mid-sentence; wait for the next word
+		// to tell
+		if end == len(runes) {
+			return true
+		}
+		next := end
+		for next < len(runes) && unicode.IsSpace(runes[next]) {
+			next++
+		}
+		return next < len(runes) && !unicode.IsLower(runes[next])
+	default:
+		return true
+	}
+}
+
+// isAbbreviation reports whether the word at the end of before, whose
+// period follows it, is a known abbreviation, an initialism such as "U.S",
+// or a single capital letter such as a middle initial.
+func isAbbreviation(before []rune) bool {
+	start := len(before)
+	for start > 0 && (unicode.IsLetter(before[start-1]) || before[start-1] == '.') {
+		start--
+	}
+	word := string(before[start:])
+	if word == "" {
+		return false
+	}
+	if abbreviations[strings.ToLower(word)] {
+		return true
+	}
+
+	letters := strings.Split(word, ".")
+	for _, letter := range letters {
+		if len([]rune(letter)) != 1 {
+			return false
+		}
+	}
+	return len(letters) > 1 || unicode.IsUpper([]rune(word)[0])
+}
+
 // ttsCallbackHandler implements the Deepgram TTS callback interface.
 type ttsCallbackHandler struct {
 	chunkCh chan tts.StreamChunk
 	ctx     context.Context
 	closed  bool
 	mu      sync.Mutex
+
+	// done is closed when the session ends, releasing chunks waiting on a
+	// full channel; sending is held for reading while a chunk is sent, so
+	// that the channel is closed only once no send is in flight
+	done    chan struct{}
+	sending sync.RWMutex
+
+	// resampler converts audio to the requested output rate, if set
+	resampler *omnivoice.Resampler
+
+	// observer sees every delivered chunk; may be nil
+	observer func(any)
+
+	// flushes counts the flush responses received, and flushed is
+	// signaled after each; used by SetVoice to await outstanding audio
+	flushes atomic.Int64
+	flushed chan struct{}
+
+	// closedBy is signaled when Deepgram closes the connection; may be nil
+	closedBy chan struct{}
+
+	// encoding and sampleRate are the audio format Deepgram reported in
+	// its Metadata message, if any; guarded by mu
+	encoding   string
+	sampleRate int
 }
 
-// sendChunk safely sends a chunk to the channel.
-func (h *ttsCallbackHandler) sendChunk(chunk tts.StreamChunk) {
+// rawMetadata records the audio format reported in a Metadata message.
+// Other messages, and Metadata without a format, are ignored.
+func (h *ttsCallbackHandler) rawMetadata(msg []byte) {
+	md, ok := parseSpeakMetadata(msg)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if md.Encoding != "" {
+		h.encoding = md.Encoding
+	}
+	if md.SampleRate > 0 {
+		h.sampleRate = md.SampleRate
+	}
+}
+
+// deliveredFormat returns the encoding and sample rate Deepgram reported
+// for the session, falling back to encoding and sampleRate for what it has
+// not reported.
+func (h *ttsCallbackHandler) deliveredFormat(encoding string, sampleRate int) (string, int) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if h.encoding != "" {
+		encoding = h.encoding
+	}
+	if h.sampleRate > 0 {
+		sampleRate = h.sampleRate
+	}
+	return encoding, sampleRate
+}
 
-	if h.closed {
+// sendChunk sends a chunk to the channel. When the channel is full, it
+// waits for the reader to catch up rather than dropping audio, until the
+// session ends or its context is done. Chunks sent after the session has
+// ended are dropped, and only the chunks that reach the channel are shown
+// to the observer.
+func (h *ttsCallbackHandler) sendChunk(chunk tts.StreamChunk) {
+	h.sending.RLock()
+	defer h.sending.RUnlock()
+
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
 		return
 	}
 
 	select {
 	case h.chunkCh <- chunk:
+		if h.observer != nil {
+			h.observer(chunk)
+		}
+	case <-h.done:
 	case <-h.ctx.Done():
-	default:
-		// Channel full, drop chunk
 	}
 }
 
+// close ends the session, releasing any chunk waiting on a full channel,
+// and closes the channel once no chunk is being sent. Closing an already
+// closed session does nothing.
+func (h *ttsCallbackHandler) close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.done)
+	h.sending.Lock()
+	close(h.chunkCh)
+	h.sending.Unlock()
+}
+
 // Open is called when the connection is established.
 func (h *ttsCallbackHandler) Open(or *wsinterfaces.OpenResponse) error {
 	return nil
@@ -384,6 +1264,12 @@ func (h *ttsCallbackHandler) Metadata(md *wsinterfaces.MetadataResponse) error {
 func (h *ttsCallbackHandler) Flush(fr *wsinterfaces.FlushedResponse) error {
 	// Mark final chunk after flush
 	h.sendChunk(tts.StreamChunk{IsFinal: true})
+
+	h.flushes.Add(1)
+	select {
+	case h.flushed <- struct{}{}:
+	default:
+	}
 	return nil
 }
 
@@ -394,6 +1280,10 @@ func (h *ttsCallbackHandler) Clear(cr *wsinterfaces.ClearedResponse) error {
 
 // Close is called when the connection is closed.
 func (h *ttsCallbackHandler) Close(cr *wsinterfaces.CloseResponse) error {
+	select {
+	case h.closedBy <- struct{}{}:
+	default:
+	}
 	return nil
 }
 
@@ -409,7 +1299,7 @@ func (h *ttsCallbackHandler) Error(er *wsinterfaces.ErrorResponse) error {
 	}
 
 	h.sendChunk(tts.StreamChunk{
-		Error: fmt.Errorf("deepgram TTS error: %s", er.Description),
+		Error: errorf(h.ctx, "deepgram TTS error", errors.New(er.Description)),
 	})
 	return nil
 }
@@ -425,6 +1315,13 @@ func (h *ttsCallbackHandler) Binary(data []byte) error {
 	audio := make([]byte, len(data))
 	copy(audio, data)
 
+	if h.resampler != nil {
+		audio = h.resampler.Resample(audio)
+		if len(audio) == 0 {
+			return nil
+		}
+	}
+
 	h.sendChunk(tts.StreamChunk{Audio: audio})
 	return nil
 }
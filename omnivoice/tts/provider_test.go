@@ -1,9 +1,22 @@
 package tts
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
 	"github.com/plexusone/omnivoice-core/tts"
 	"github.com/plexusone/omnivoice-deepgram/omnivoice"
 )
@@ -134,6 +147,127 @@ func TestProvider_GetVoice(t *testing.T) {
 	}
 }
 
+func TestProvider_SearchVoices(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "exact name match",
+			query: "ZEUS",
+			want:  []string{"aura-zeus-en"},
+		},
+		{
+			name:  "exact ID ranks before substring",
+			query: "aura-luna-en",
+			want:  []string{"aura-luna-en"},
+		},
+		{
+			name:  "prefix before contains",
+			query: "he",
+			want: []string{
+				"aura-hera-en", "aura-helios-en", "aura-2-helena-en", // prefix
+				"aura-athena-en", "aura-orpheus-en", // contains
+			},
+		},
+		{
+			name:  "exact before prefix",
+			query: "orion",
+			want:  []string{"aura-orion-en"},
+		},
+		{
+			name:  "no match",
+			query: "nonexistent",
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voices, err := p.SearchVoices(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("SearchVoices() error = %v", err)
+			}
+			got := make([]string, len(voices))
+			for i, v := range voices {
+				got[i] = v.ID
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SearchVoices(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_ListVoicesFiltered(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// count returns the number of catalog voices with the given language
+	// prefix and gender
+	count := func(prefix, gender string) int {
+		var n int
+		for _, v := range omnivoice.DeepgramVoices {
+			if strings.HasPrefix(v.Language, prefix) && (gender == "" || v.Gender == gender) {
+				n++
+			}
+		}
+		return n
+	}
+
+	tests := []struct {
+		name      string
+		filter    VoiceFilter
+		wantIDs   []string
+		wantCount int
+	}{
+		{name: "no filter", filter: VoiceFilter{}, wantCount: len(omnivoice.DeepgramVoices)},
+		{name: "language prefix and gender", filter: VoiceFilter{Language: "en", Gender: "female"}, wantCount: count("en-", "female")},
+		{name: "exact language ignoring case", filter: VoiceFilter{Language: "EN-gb"}, wantIDs: []string{"aura-helios-en"}},
+		{name: "gender ignoring case", filter: VoiceFilter{Language: "en-IE", Gender: "MALE"}, wantIDs: []string{"aura-angus-en"}},
+		{name: "language and gender without a match", filter: VoiceFilter{Language: "en-IE", Gender: "female"}, wantIDs: []string{}},
+		{name: "prefix within a subtag", filter: VoiceFilter{Language: "e"}, wantIDs: []string{}},
+		{name: "other language", filter: VoiceFilter{Language: "fr"}, wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voices, err := p.ListVoicesFiltered(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("ListVoicesFiltered() error = %v", err)
+			}
+			if tt.wantIDs != nil {
+				got := make([]string, len(voices))
+				for i, v := range voices {
+					got[i] = v.ID
+				}
+				if !slices.Equal(got, tt.wantIDs) {
+					t.Errorf("ListVoicesFiltered(%+v) = %v, want %v", tt.filter, got, tt.wantIDs)
+				}
+				return
+			}
+			if len(voices) != tt.wantCount || len(voices) == 0 {
+				t.Errorf("ListVoicesFiltered(%+v) returned %d voices, want %d", tt.filter, len(voices), tt.wantCount)
+			}
+			for _, v := range voices {
+				if tt.filter.Gender != "" && v.Gender != tt.filter.Gender {
+					t.Errorf("voice %s has gender %q, want %q", v.ID, v.Gender, tt.filter.Gender)
+				}
+			}
+		})
+	}
+}
+
 func TestProvider_ImplementsInterface(t *testing.T) {
 	p, err := New(WithAPIKey("test-key"))
 	if err != nil {
@@ -176,7 +310,62 @@ func TestSplitIntoSentences(t *testing.T) {
 		{
 			name:     "sentence with abbreviation",
 			input:    "Dr. Smith is here.",
-			expected: []string{"Dr.", " Smith is here."}, // Abbreviations may split; acceptable for TTS
+			expected: []string{"Dr. Smith is here."},
+		},
+		{
+			name:     "several abbreviations",
+			input:    "Mr. and Mrs. Jones met at St. Paul's vs. the rest. They left.",
+			expected: []string{"Mr. and Mrs. Jones met at St. Paul's vs. the rest.", " They left."},
+		},
+		{
+			name:     "dotted abbreviations",
+			input:    "Bring fruit, e.g. apples, i.e. food. Thanks.",
+			expected: []string{"Bring fruit, e.g. apples, i.e. food.", " Thanks."},
+		},
+		{
+			name:     "initialism",
+			input:    "She moved to the U.S. in May. Then she left.",
+			expected: []string{"She moved to the U.S. in May.", " Then she left."},
+		},
+		{
+			name:     "middle initial",
+			input:    "John F. Kennedy spoke. We listened.",
+			expected: []string{"John F. Kennedy spoke.", " We listened."},
+		},
+		{
+			name:     "ellipsis ending a sentence",
+			input:    "Wait... Who is there?",
+			expected: []string{"Wait...", " Who is there?"},
+		},
+		{
+			name:     "ellipsis mid-sentence",
+			input:    "Well... maybe not. Okay.",
+			expected: []string{"Well... maybe not.", " Okay."},
+		},
+		{
+			name:     "unicode ellipsis mid-sentence",
+			input:    "Hmm\u2026 fine.",
+			expected: []string{"Hmm\u2026 fine."},
+		},
+		{
+			name:     "ellipsis awaiting the next word",
+			input:    "I wonder... ",
+			expected: []string{"I wonder... "},
+		},
+		{
+			name:     "closing quote kept with sentence",
+			input:    `He said "hi." Then left.`,
+			expected: []string{`He said "hi."`, " Then left."},
+		},
+		{
+			name:     "closing bracket kept with sentence",
+			input:    "It works (mostly.) Try it.",
+			expected: []string{"It works (mostly.)", " Try it."},
+		},
+		{
+			name:     "repeated terminators",
+			input:    "Really?! Yes.",
+			expected: []string{"Really?!", " Yes."},
 		},
 		{
 			name:     "sentence with decimal number",
@@ -222,3 +411,1502 @@ func TestSplitIntoSentences(t *testing.T) {
 		})
 	}
 }
+
+func TestWithBaseURL(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", "")
+
+	p, err := New(WithAPIKey("test-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests to base URL = %d, want 1", requests)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if got := p.endpoint.WSOptions().Host; got != "ws://"+host {
+		t.Errorf("streaming Host = %q, want %q", got, "ws://"+host)
+	}
+	req, err := p.BuildRequest(tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+	if !strings.HasPrefix(req.StreamURL, "ws://"+host+"/") {
+		t.Errorf("StreamURL = %q, want it on %s", req.StreamURL, host)
+	}
+
+	for _, bad := range []string{"", "dg.internal", "https://dg.internal/v1"} {
+		if _, err := New(WithAPIKey("test-key"), WithBaseURL(bad)); err == nil {
+			t.Errorf("New(WithBaseURL(%q)) error = nil, want an error", bad)
+		}
+	}
+}
+
+// roundTripFunc is an http.RoundTripper that answers requests itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Setenv("DEEPGRAM_HOST", "")
+
+	var requests []*http.Request
+	hc := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Char-Count": {"5"}},
+			Body:       io.NopCloser(strings.NewReader("audio")),
+			Request:    req,
+		}, nil
+	})}
+
+	p, err := New(WithAPIKey("test-key"), WithHTTPClient(hc))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if string(result.Audio) != "audio" {
+		t.Errorf("Audio = %q, want the intercepted response", result.Audio)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("intercepted %d requests, want 1", len(requests))
+	}
+	if got := requests[0].URL.Path; !strings.HasPrefix(got, "/v1/speak") {
+		t.Errorf("request path = %q, want /v1/speak", got)
+	}
+
+	// A nil client keeps the default
+	if _, err := New(WithAPIKey("test-key"), WithHTTPClient(nil)); err != nil {
+		t.Errorf("New(WithHTTPClient(nil)) error = %v", err)
+	}
+}
+
+func TestSynthesize_OggOpus(t *testing.T) {
+	tests := []struct {
+		name           string
+		sampleRate     int
+		wantSampleRate string
+	}{
+		{name: "default rate", wantSampleRate: ""},
+		{name: "unsupported rate omitted", sampleRate: 16000, wantSampleRate: ""},
+		{name: "fixed rate", sampleRate: 48000, wantSampleRate: "48000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query map[string][]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Header().Set("char-count", "5")
+				_, _ = w.Write([]byte("OggS audio"))
+			}))
+			defer srv.Close()
+			t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+			p, err := New(WithAPIKey("test-key"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			result, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{OutputFormat: "opus", SampleRate: tt.sampleRate})
+			if err != nil {
+				t.Fatalf("Synthesize() error = %v", err)
+			}
+
+			if got := query["encoding"]; !slices.Equal(got, []string{"opus"}) {
+				t.Errorf("encoding = %q, want opus", got)
+			}
+			if got := query["container"]; !slices.Equal(got, []string{"ogg"}) {
+				t.Errorf("container = %q, want ogg", got)
+			}
+			if got := strings.Join(query["sample_rate"], ","); got != tt.wantSampleRate {
+				t.Errorf("sample_rate = %q, want %q", got, tt.wantSampleRate)
+			}
+			if result.Format != "ogg_opus" {
+				t.Errorf("Format = %q, want %q", result.Format, "ogg_opus")
+			}
+			if result.SampleRate != 48000 {
+				t.Errorf("SampleRate = %d, want 48000", result.SampleRate)
+			}
+		})
+	}
+}
+
+func TestSynthesize_CorrelationID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"err_code":"Bad Request","err_msg":"bad text"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := omnivoice.WithCorrelationID(context.Background(), "trace-123")
+	_, err = p.Synthesize(ctx, "Hello", tts.SynthesisConfig{})
+	if err == nil {
+		t.Fatal("Synthesize() expected error from server")
+	}
+	if !strings.Contains(err.Error(), "correlation_id=trace-123") {
+		t.Errorf("error %q does not contain correlation ID", err)
+	}
+}
+
+// failingSpeakClient is a fakeSpeakClient that cannot send text.
+type failingSpeakClient struct {
+	*fakeSpeakClient
+}
+
+func (c *failingSpeakClient) SpeakWithText(text string) error {
+	return io.ErrClosedPipe
+}
+
+func TestSynthesizeStream_CorrelationID(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		return &failingSpeakClient{&fakeSpeakClient{handler: handler}}, nil
+	}
+
+	ctx := omnivoice.WithCorrelationID(context.Background(), "trace-123")
+	chunks, err := p.SynthesizeStream(ctx, "Hello", tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+
+	var streamErr error
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			streamErr = chunk.Error
+		}
+	}
+	if streamErr == nil {
+		t.Fatal("SynthesizeStream() delivered no error")
+	}
+	if !strings.Contains(streamErr.Error(), "correlation_id=trace-123") {
+		t.Errorf("stream error %q does not contain correlation ID", streamErr)
+	}
+	if !errors.Is(streamErr, io.ErrClosedPipe) {
+		t.Errorf("stream error %v does not wrap the send error", streamErr)
+	}
+}
+
+func TestSynthesize_Extra(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write(make([]byte, 16))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	config := tts.SynthesisConfig{Extensions: map[string]any{
+		omnivoice.ExtExtra: map[string]string{"tenant": "acme", "job": "1234"},
+	}}
+	if _, err := p.Synthesize(context.Background(), "Hello", config); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if want := []string{"job:1234", "tenant:acme"}; !slices.Equal(query["extra"], want) {
+		t.Errorf("extra = %v, want %v", query["extra"], want)
+	}
+
+	if _, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if _, ok := query["extra"]; ok {
+		t.Errorf("request sent extra=%v without the extension", query["extra"])
+	}
+}
+
+func TestSynthesize_TextSentVerbatim(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write(make([]byte, 16))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := `Wait... <break time="1s"/> R&D said "hold on"`
+	if _, err := p.Synthesize(context.Background(), text, tts.SynthesisConfig{}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	var req struct{ Text string }
+	if err := json.Unmarshal(body, &req); err != nil || req.Text != text {
+		t.Errorf("request text = %q (err %v), want %q", req.Text, err, text)
+	}
+}
+
+func TestSynthesizeStream_Extra(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var params map[string][]string
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		params, _ = ctx.Value(interfaces.ParametersContext{}).(map[string][]string)
+		return &fakeSpeakClient{handler: handler}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := tts.SynthesisConfig{Extensions: map[string]any{omnivoice.ExtExtra: map[string]string{"tenant": "acme"}}}
+	if _, err := p.SynthesizeStream(ctx, "Hello", config); err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+	if want := []string{"tenant:acme"}; !slices.Equal(params["extra"], want) {
+		t.Errorf("extra = %v, want %v", params["extra"], want)
+	}
+}
+
+func TestSynthesize_ResultFormat(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write(make([]byte, 16))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		config         tts.SynthesisConfig
+		wantFormat     string
+		wantSampleRate int
+	}{
+		{"unset", tts.SynthesisConfig{}, "linear16", 24000},
+		{"pcm alias", tts.SynthesisConfig{OutputFormat: "pcm", SampleRate: 16000}, "linear16", 16000},
+		{"ulaw alias", tts.SynthesisConfig{OutputFormat: "ulaw"}, "mulaw", 8000},
+		{"flac default rate", tts.SynthesisConfig{OutputFormat: "flac"}, "flac", 48000},
+		{"mp3 fixed rate", tts.SynthesisConfig{OutputFormat: "mp3", SampleRate: 16000}, "mp3", 22050},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Synthesize(context.Background(), "Hello", tt.config)
+			if err != nil {
+				t.Fatalf("Synthesize() error = %v", err)
+			}
+			if result.Format != tt.wantFormat || result.SampleRate != tt.wantSampleRate {
+				t.Errorf("result format = %s/%d, want %s/%d", result.Format, result.SampleRate, tt.wantFormat, tt.wantSampleRate)
+			}
+			if got := query["encoding"]; !slices.Equal(got, []string{result.Format}) {
+				t.Errorf("request encoding = %v, want the reported %s", got, result.Format)
+			}
+		})
+	}
+}
+
+func TestSynthesize_WAV(t *testing.T) {
+	pcm := make([]byte, 3200)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write(pcm)
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		config         tts.SynthesisConfig
+		wantSampleRate int
+	}{
+		{"default rate", tts.SynthesisConfig{OutputFormat: "wav"}, 24000},
+		{"configured rate", tts.SynthesisConfig{OutputFormat: "wav", SampleRate: 16000}, 16000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Synthesize(context.Background(), "Hello", tt.config)
+			if err != nil {
+				t.Fatalf("Synthesize() error = %v", err)
+			}
+			if result.Format != "wav" || result.SampleRate != tt.wantSampleRate {
+				t.Errorf("result format = %s/%d, want wav/%d", result.Format, result.SampleRate, tt.wantSampleRate)
+			}
+			if got := query["encoding"]; !slices.Equal(got, []string{"linear16"}) {
+				t.Errorf("request encoding = %v, want linear16", got)
+			}
+			if got := query["container"]; !slices.Equal(got, []string{"none"}) {
+				t.Errorf("request container = %v, want none", got)
+			}
+
+			info, err := omnivoice.ReadWAVInfo(bytes.NewReader(result.Audio))
+			if err != nil {
+				t.Fatalf("ReadWAVInfo() error = %v", err)
+			}
+			want := omnivoice.WAVInfo{
+				AudioFormat:   1,
+				Channels:      1,
+				SampleRate:    tt.wantSampleRate,
+				BitsPerSample: 16,
+				DataOffset:    44,
+				DataSize:      int64(len(pcm)),
+			}
+			if *info != want {
+				t.Errorf("WAV header = %+v, want %+v", *info, want)
+			}
+			if !bytes.Equal(result.Audio[44:], pcm) {
+				t.Error("WAV data does not match the synthesized audio")
+			}
+		})
+	}
+}
+
+func TestSynthesize_DefaultOutputFormat(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write(make([]byte, 16))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithDefaultOutputFormat("mulaw"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		config         tts.SynthesisConfig
+		wantEncoding   string
+		wantFormat     string
+		wantSampleRate int
+	}{
+		{"default applied", tts.SynthesisConfig{}, "mulaw", "mulaw", 8000},
+		{"default with call rate", tts.SynthesisConfig{SampleRate: 16000}, "mulaw", "mulaw", 16000},
+		{"call format wins", tts.SynthesisConfig{OutputFormat: "linear16"}, "linear16", "linear16", 24000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Synthesize(context.Background(), "Hello", tt.config)
+			if err != nil {
+				t.Fatalf("Synthesize() error = %v", err)
+			}
+			if got := query["encoding"]; !slices.Equal(got, []string{tt.wantEncoding}) {
+				t.Errorf("encoding = %v, want %s", got, tt.wantEncoding)
+			}
+			if result.Format != tt.wantFormat || result.SampleRate != tt.wantSampleRate {
+				t.Errorf("result format = %s/%d, want %s/%d", result.Format, result.SampleRate, tt.wantFormat, tt.wantSampleRate)
+			}
+		})
+	}
+}
+
+func TestSynthesizeStream_DefaultOutputFormat(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithDefaultOutputFormat("mulaw"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var encoding string
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		encoding = opts.Encoding
+		return &fakeSpeakClient{handler: handler, audio: [][]byte{make([]byte, 800)}}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunks, err := p.SynthesizeStreamWithTiming(ctx, "Hello", tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeStreamWithTiming() error = %v", err)
+	}
+	if encoding != "mulaw" {
+		t.Errorf("stream encoding = %q, want mulaw", encoding)
+	}
+	for chunk := range chunks {
+		if chunk.IsFinal {
+			cancel()
+			continue
+		}
+		// 800 bytes of 8 kHz mulaw is 100ms; linear16 timing would differ
+		if chunk.Encoding != "mulaw" || chunk.SampleRate != 8000 || chunk.Duration != 100*time.Millisecond {
+			t.Errorf("chunk = %s/%d %v, want mulaw/8000 100ms", chunk.Encoding, chunk.SampleRate, chunk.Duration)
+		}
+	}
+}
+
+func TestSynthesize_MP3SampleRate(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write([]byte("ID3"))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{OutputFormat: "mp3", SampleRate: 24000})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	if _, ok := query["sample_rate"]; ok {
+		t.Errorf("request sent sample_rate=%v for mp3", query["sample_rate"])
+	}
+	if result.SampleRate != 22050 {
+		t.Errorf("SampleRate = %d, want effective mp3 rate 22050", result.SampleRate)
+	}
+}
+
+func TestSynthesize_ModelFallback(t *testing.T) {
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		model := r.URL.Query().Get("model")
+		models = append(models, model)
+		if model == "aura-2-thalia-en" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"err_code":"INVALID_QUERY_PARAMETER","err_msg":"Failed to resolve model"}`))
+			return
+		}
+		w.Header().Set("char-count", "5")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithModelFallback([]string{"aura-asteria-en"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{Model: "aura-2-thalia-en"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	if want := []string{"aura-2-thalia-en", "aura-asteria-en"}; strings.Join(models, ",") != strings.Join(want, ",") {
+		t.Errorf("requested models = %q, want %q", models, want)
+	}
+	if string(result.Audio) != "audio" || result.CharacterCount != 5 {
+		t.Errorf("result audio=%q chars=%d, want audio/5", result.Audio, result.CharacterCount)
+	}
+}
+
+func TestSynthesize_ModelFallbackSkipsAuthError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	t.Setenv("DEEPGRAM_HOST", srv.URL)
+
+	p, err := New(WithAPIKey("test-key"), WithModelFallback([]string{"aura-asteria-en"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.Synthesize(context.Background(), "Hello", tts.SynthesisConfig{Model: "aura-2-thalia-en"}); err == nil {
+		t.Fatal("Synthesize() expected error from server")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+// fakeSpeakClient records the text sent to Deepgram and answers each flush
+// with one audio chunk per spoken text, or the given audio chunks if set,
+// followed by a Flushed event.
+type fakeSpeakClient struct {
+	mu      sync.Mutex
+	handler wsinterfaces.SpeakMessageCallback
+	audio   [][]byte
+	texts   []string
+	pending []string
+	flushed time.Time
+	batches [][]string
+
+	resets int
+	stops  int
+}
+
+func (c *fakeSpeakClient) SpeakWithText(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.texts = append(c.texts, text)
+	c.pending = append(c.pending, text)
+	return nil
+}
+
+func (c *fakeSpeakClient) Flush() error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.flushed = time.Now()
+	c.batches = append(c.batches, pending)
+	c.mu.Unlock()
+
+	audio := c.audio
+	if audio == nil {
+		for _, text := range pending {
+			audio = append(audio, []byte(text))
+		}
+	}
+	for _, data := range audio {
+		_ = c.handler.Binary(data)
+	}
+	return c.handler.Flush(nil)
+}
+
+// Reset drops the pending text and confirms the clear.
+func (c *fakeSpeakClient) Reset() error {
+	c.mu.Lock()
+	c.pending = nil
+	c.resets++
+	c.mu.Unlock()
+	return c.handler.Clear(nil)
+}
+
+// Stop closes the connection. Like the SDK client, stopping an open
+// connection reports the close to the handler.
+func (c *fakeSpeakClient) Stop() {
+	c.mu.Lock()
+	c.stops++
+	open := c.stops == 1
+	handler := c.handler
+	c.mu.Unlock()
+
+	if open && handler != nil {
+		_ = handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+	}
+}
+
+// waitStopped waits for the connection to be stopped, which happens just
+// after the session's channel is closed, and reports whether it was.
+func (c *fakeSpeakClient) waitStopped() bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		c.mu.Lock()
+		stops := c.stops
+		c.mu.Unlock()
+		if stops > 0 || time.Now().After(deadline) {
+			return stops > 0
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSynthesizeFromReader_DeadlineFlush(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	// A slow reader that never reaches EOF, leaving an incomplete sentence buffered
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() { _, _ = pw.Write([]byte("Hello there. How are")) }()
+
+	deadline := time.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	chunks, err := p.SynthesizeFromReader(ctx, pr, tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReader() error = %v", err)
+	}
+
+	var audio []string
+	var final bool
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		if len(chunk.Audio) > 0 {
+			audio = append(audio, string(chunk.Audio))
+		}
+		final = final || chunk.IsFinal
+	}
+	if !fake.waitStopped() {
+		t.Error("connection not closed after the session ended")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if want := []string{"Hello there.", "How are"}; strings.Join(fake.texts, "|") != strings.Join(want, "|") {
+		t.Errorf("spoken texts = %q, want %q", fake.texts, want)
+	}
+	if strings.Join(audio, "|") != strings.Join(fake.texts, "|") {
+		t.Errorf("audio chunks = %q, want one per spoken text", audio)
+	}
+	if !final {
+		t.Error("no final chunk after the deadline flush")
+	}
+	if fake.flushed.IsZero() || !fake.flushed.Before(deadline) {
+		t.Errorf("flushed at %v, want before deadline %v", fake.flushed, deadline)
+	}
+}
+
+func TestSynthesizeStreamWithTiming(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// 100ms, 50ms, and 250ms of 16 kHz linear16
+	fake := &fakeSpeakClient{audio: [][]byte{make([]byte, 3200), make([]byte, 1600), make([]byte, 8000)}}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunks, err := p.SynthesizeStreamWithTiming(ctx, "Hello", tts.SynthesisConfig{OutputFormat: "linear16", SampleRate: 16000})
+	if err != nil {
+		t.Fatalf("SynthesizeStreamWithTiming() error = %v", err)
+	}
+
+	wantOffsets := []time.Duration{0, 100 * time.Millisecond, 150 * time.Millisecond}
+	wantDurations := []time.Duration{100 * time.Millisecond, 50 * time.Millisecond, 250 * time.Millisecond}
+	var i int
+	for chunk := range chunks {
+		if chunk.IsFinal {
+			cancel()
+			continue
+		}
+		if i >= len(wantOffsets) {
+			t.Fatalf("unexpected chunk %d", i)
+		}
+		if chunk.Offset != wantOffsets[i] || chunk.Duration != wantDurations[i] {
+			t.Errorf("chunk %d offset/duration = %v/%v, want %v/%v", i, chunk.Offset, chunk.Duration, wantOffsets[i], wantDurations[i])
+		}
+		i++
+	}
+	if i != len(wantOffsets) {
+		t.Errorf("got %d audio chunks, want %d", i, len(wantOffsets))
+	}
+}
+
+func TestSynthesizeStreamWithTiming_DeliveredFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         tts.SynthesisConfig
+		metadata       string
+		wantEncoding   string
+		wantSampleRate int
+	}{
+		{
+			name:           "defaults filled in",
+			config:         tts.SynthesisConfig{OutputFormat: "pcm"},
+			wantEncoding:   "linear16",
+			wantSampleRate: 24000,
+		},
+		{
+			name:           "fixed rate overrides request",
+			config:         tts.SynthesisConfig{OutputFormat: "mp3", SampleRate: 48000},
+			wantEncoding:   "mp3",
+			wantSampleRate: 22050,
+		},
+		{
+			name:           "reported by metadata",
+			config:         tts.SynthesisConfig{OutputFormat: "pcm"},
+			metadata:       `{"type":"Metadata","request_id":"req-1","model_name":"aura-asteria-en","encoding":"mulaw","sample_rate":8000}`,
+			wantEncoding:   "mulaw",
+			wantSampleRate: 8000,
+		},
+		{
+			name:           "resampled output",
+			config:         tts.SynthesisConfig{SampleRate: 48000, Extensions: map[string]any{omnivoice.ExtOutputSampleRate: 16000}},
+			wantEncoding:   "linear16",
+			wantSampleRate: 16000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(WithAPIKey("test-key"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			fake := &fakeSpeakClient{audio: [][]byte{make([]byte, 960)}}
+			p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+				fake.handler = handler
+				_ = handler.Metadata(&wsinterfaces.MetadataResponse{Type: "Metadata", RequestID: "req-1"})
+				if tt.metadata != "" {
+					handler.(metadataReader).rawMetadata([]byte(tt.metadata))
+				}
+				return fake, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			chunks, err := p.SynthesizeStreamWithTiming(ctx, "Hello", tt.config)
+			if err != nil {
+				t.Fatalf("SynthesizeStreamWithTiming() error = %v", err)
+			}
+
+			var n int
+			for chunk := range chunks {
+				if chunk.IsFinal {
+					cancel()
+				}
+				if chunk.Encoding != tt.wantEncoding || chunk.SampleRate != tt.wantSampleRate {
+					t.Errorf("chunk format = %s/%d, want %s/%d", chunk.Encoding, chunk.SampleRate, tt.wantEncoding, tt.wantSampleRate)
+				}
+				n++
+			}
+			if n == 0 {
+				t.Error("got no chunks")
+			}
+		})
+	}
+}
+
+func TestSynthesizeStream_OutputSampleRate(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// 100ms and 50ms of Deepgram's default 24 kHz linear16
+	fake := &fakeSpeakClient{audio: [][]byte{make([]byte, 4800), make([]byte, 2400)}}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := tts.SynthesisConfig{Extensions: map[string]any{omnivoice.ExtOutputSampleRate: 16000}}
+	chunks, err := p.SynthesizeStreamWithTiming(ctx, "Hello", config)
+	if err != nil {
+		t.Fatalf("SynthesizeStreamWithTiming() error = %v", err)
+	}
+
+	wantLengths := []int{3200, 1600}
+	wantDurations := []time.Duration{100 * time.Millisecond, 50 * time.Millisecond}
+	var i int
+	for chunk := range chunks {
+		if chunk.IsFinal {
+			cancel()
+			continue
+		}
+		if i >= len(wantLengths) {
+			t.Fatalf("unexpected chunk %d", i)
+		}
+		if len(chunk.Audio) != wantLengths[i] || chunk.Duration != wantDurations[i] {
+			t.Errorf("chunk %d length/duration = %d/%v, want %d/%v", i, len(chunk.Audio), chunk.Duration, wantLengths[i], wantDurations[i])
+		}
+		i++
+	}
+	if i != len(wantLengths) {
+		t.Errorf("got %d audio chunks, want %d", i, len(wantLengths))
+	}
+}
+
+// closingSpeakClient is a fakeSpeakClient whose connection Deepgram closes
+// instead of answering a flush.
+type closingSpeakClient struct {
+	*fakeSpeakClient
+}
+
+func (c *closingSpeakClient) Flush() error {
+	return c.handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+}
+
+func TestSynthesizeStream_ClosesWhenDone(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    func(fake *fakeSpeakClient) SpeakClient
+		wantAudio int
+	}{
+		{
+			name:      "flushed",
+			client:    func(fake *fakeSpeakClient) SpeakClient { return fake },
+			wantAudio: 1,
+		},
+		{
+			name:   "closed by deepgram",
+			client: func(fake *fakeSpeakClient) SpeakClient { return &closingSpeakClient{fake} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(WithAPIKey("test-key"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			fake := &fakeSpeakClient{}
+			p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+				fake.handler = handler
+				return tt.client(fake), nil
+			}
+
+			chunks, err := p.SynthesizeStream(context.Background(), "Hello", tts.SynthesisConfig{})
+			if err != nil {
+				t.Fatalf("SynthesizeStream() error = %v", err)
+			}
+
+			var audio int
+			timeout := time.After(5 * time.Second)
+			for done := false; !done; {
+				select {
+				case chunk, ok := <-chunks:
+					if !ok {
+						done = true
+					} else if len(chunk.Audio) > 0 {
+						audio++
+					}
+				case <-timeout:
+					t.Fatal("channel not closed after synthesis finished")
+				}
+			}
+
+			if audio != tt.wantAudio {
+				t.Errorf("received %d audio chunks, want %d", audio, tt.wantAudio)
+			}
+			if !fake.waitStopped() {
+				t.Error("connection not closed after the stream ended")
+			}
+		})
+	}
+}
+
+func TestSynthesizeStream_SlowReader(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// More chunks than the channel buffers, each filled with its index
+	const chunkCount = 250
+	audio := make([][]byte, chunkCount)
+	for i := range audio {
+		audio[i] = bytes.Repeat([]byte{byte(i)}, 480)
+	}
+	fake := &fakeSpeakClient{audio: audio}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chunks, err := p.SynthesizeStream(ctx, "Hello", tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+
+	var got []byte
+	for chunk := range chunks {
+		if chunk.IsFinal {
+			cancel()
+			continue
+		}
+		got = append(got, chunk.Audio...)
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	if want := bytes.Join(audio, nil); !bytes.Equal(got, want) {
+		t.Errorf("received %d bytes of audio, want the %d bytes sent in order", len(got), len(want))
+	}
+}
+
+// gatedReader returns text on its first Read, then signals waiting and
+// blocks until released, returning rest if set and then EOF.
+type gatedReader struct {
+	text    string
+	rest    string
+	waiting chan struct{}
+	release chan struct{}
+	reads   int
+}
+
+func (r *gatedReader) Read(p []byte) (int, error) {
+	r.reads++
+	switch {
+	case r.reads == 1:
+		return copy(p, r.text), nil
+	case r.reads == 2:
+		close(r.waiting)
+		<-r.release
+		if r.rest != "" {
+			return copy(p, r.rest), nil
+		}
+	}
+	return 0, io.EOF
+}
+
+func TestSynthesizeFromReaderWithFlush_ExplicitFlush(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	reader := &gatedReader{
+		text:    "Hello there, how are",
+		waiting: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, chunks, err := p.SynthesizeFromReaderWithFlush(ctx, reader, tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReaderWithFlush() error = %v", err)
+	}
+
+	// Once the reader blocks, its text has been handed to the session
+	<-reader.waiting
+	if err := stream.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// The incomplete sentence is synthesized before EOF
+	var audio string
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		audio += string(chunk.Audio)
+		if chunk.IsFinal {
+			break
+		}
+	}
+	if audio != "Hello there, how are" {
+		t.Errorf("audio before EOF = %q, want %q", audio, "Hello there, how are")
+	}
+
+	close(reader.release)
+	cancel()
+	for range chunks {
+	}
+	if err := stream.Flush(); err != io.ErrClosedPipe {
+		t.Errorf("Flush() after close error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+func TestReaderStream_SetVoice(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Each connection gets its own client, whose audio names its voice
+	var mu sync.Mutex
+	var models []string
+	clients := map[string]*fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		models = append(models, opts.Model)
+		fake := &fakeSpeakClient{handler: handler, audio: [][]byte{[]byte(opts.Model)}}
+		clients[opts.Model] = fake
+		return fake, nil
+	}
+
+	reader := &gatedReader{
+		text:    "Hello there.",
+		rest:    "How are you?",
+		waiting: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, chunks, err := p.SynthesizeFromReaderWithFlush(ctx, reader, tts.SynthesisConfig{VoiceID: "aura-2-thalia-en"})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReaderWithFlush() error = %v", err)
+	}
+
+	<-reader.waiting
+	if err := stream.SetVoice(""); !errors.Is(err, tts.ErrInvalidConfig) {
+		t.Errorf("SetVoice(\"\") error = %v, want %v", err, tts.ErrInvalidConfig)
+	}
+	if err := stream.SetVoice("aura-2-orion-en"); err != nil {
+		t.Fatalf("SetVoice() error = %v", err)
+	}
+	close(reader.release)
+
+	// Text read before the switch keeps the old voice, and the rest uses the new one
+	var audio []string
+	for finals := 0; finals < 2; {
+		chunk := <-chunks
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		if len(chunk.Audio) > 0 {
+			audio = append(audio, string(chunk.Audio))
+		}
+		if chunk.IsFinal {
+			finals++
+		}
+	}
+	cancel()
+	for range chunks {
+	}
+	if want := []string{"aura-2-thalia-en", "aura-2-orion-en"}; !slices.Equal(audio, want) {
+		t.Errorf("audio = %q, want %q", audio, want)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"aura-2-thalia-en", "aura-2-orion-en"}; !slices.Equal(models, want) {
+		t.Errorf("connection models = %q, want %q", models, want)
+	}
+	if got := clients["aura-2-thalia-en"].texts; !slices.Equal(got, []string{"Hello there."}) {
+		t.Errorf("old voice texts = %q, want %q", got, []string{"Hello there."})
+	}
+	if got := clients["aura-2-orion-en"].texts; !slices.Equal(got, []string{"How are you?"}) {
+		t.Errorf("new voice texts = %q, want %q", got, []string{"How are you?"})
+	}
+	if got := clients["aura-2-thalia-en"].stops; got != 1 {
+		t.Errorf("old connection stopped %d times, want 1", got)
+	}
+
+	if err := stream.SetVoice("aura-2-thalia-en"); err != io.ErrClosedPipe {
+		t.Errorf("SetVoice() after close error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+// lateClosingClient is a fakeSpeakClient whose close is reported after
+// Stop returns, closing closed once the handler has seen it.
+type lateClosingClient struct {
+	*fakeSpeakClient
+	closed chan struct{}
+}
+
+func (c *lateClosingClient) Stop() {
+	c.mu.Lock()
+	c.stops++
+	c.mu.Unlock()
+
+	go func() {
+		_ = c.handler.Close(&wsinterfaces.CloseResponse{Type: "Close"})
+		close(c.closed)
+	}()
+}
+
+// waitingFlushClient is a fakeSpeakClient that answers a flush only once
+// ready is closed.
+type waitingFlushClient struct {
+	*fakeSpeakClient
+	ready <-chan struct{}
+}
+
+func (c *waitingFlushClient) Flush() error {
+	go func() {
+		<-c.ready
+		_ = c.fakeSpeakClient.Flush()
+	}()
+	return nil
+}
+
+func TestReaderStream_SetVoiceIgnoresStaleClose(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The old connection reports its close late, and the new one answers
+	// only after that, so a stale close would end the session first
+	oldClosed := make(chan struct{})
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake := &fakeSpeakClient{handler: handler, audio: [][]byte{[]byte(opts.Model)}}
+		if opts.Model == "aura-2-thalia-en" {
+			return &lateClosingClient{fakeSpeakClient: fake, closed: oldClosed}, nil
+		}
+		return &waitingFlushClient{fakeSpeakClient: fake, ready: oldClosed}, nil
+	}
+
+	reader := &gatedReader{
+		text:    "Hello there.",
+		rest:    "How are you?",
+		waiting: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, chunks, err := p.SynthesizeFromReaderWithFlush(ctx, reader, tts.SynthesisConfig{VoiceID: "aura-2-thalia-en"})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReaderWithFlush() error = %v", err)
+	}
+
+	<-reader.waiting
+	if err := stream.SetVoice("aura-2-orion-en"); err != nil {
+		t.Fatalf("SetVoice() error = %v", err)
+	}
+	close(reader.release)
+
+	var audio []string
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		if len(chunk.Audio) > 0 {
+			audio = append(audio, string(chunk.Audio))
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("session did not end after EOF")
+	}
+	if want := []string{"aura-2-thalia-en", "aura-2-orion-en"}; !slices.Equal(audio, want) {
+		t.Errorf("audio = %q, want %q", audio, want)
+	}
+}
+
+// unitReader returns one unit per Read, then EOF.
+type unitReader struct {
+	units []string
+}
+
+func (r *unitReader) Read(p []byte) (int, error) {
+	if len(r.units) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.units[0])
+	r.units = r.units[1:]
+	return n, nil
+}
+
+func TestSynthesizeFromReader_MinChunkLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		minLength int
+		want      []string
+	}{
+		{"each sentence", 0, []string{"Hi.", "Yes.", "That is great.", "Bye"}},
+		{"grouped", 10, []string{"Hi. Yes. That is great.", "Bye"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(WithAPIKey("test-key"), WithMinChunkLength(tt.minLength))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			fake := &fakeSpeakClient{}
+			p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+				fake.handler = handler
+				return fake, nil
+			}
+
+			reader := &unitReader{units: []string{"Hi. ", "Yes. ", "That is great. ", "Bye"}}
+			chunks, err := p.SynthesizeFromReader(context.Background(), reader, tts.SynthesisConfig{})
+			if err != nil {
+				t.Fatalf("SynthesizeFromReader() error = %v", err)
+			}
+			for range chunks {
+			}
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if !slices.Equal(fake.texts, tt.want) {
+				t.Errorf("sent %q, want %q", fake.texts, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynthesizeFromReader_IdleFlush(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithIdleFlush(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	// The reader pauses mid-sentence, as an LLM might between tokens
+	reader := &gatedReader{
+		text:    "Let me check that for",
+		rest:    " you. Done.",
+		waiting: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	chunks, err := p.SynthesizeFromReader(context.Background(), reader, tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReader() error = %v", err)
+	}
+
+	// The partial sentence is spoken while the reader is still blocked
+	<-reader.waiting
+	var audio string
+	timeout := time.After(5 * time.Second)
+	for audio == "" {
+		select {
+		case chunk := <-chunks:
+			audio += string(chunk.Audio)
+		case <-timeout:
+			t.Fatal("partial sentence not flushed while the reader was idle")
+		}
+	}
+	if audio != "Let me check that for" {
+		t.Errorf("audio while idle = %q, want %q", audio, "Let me check that for")
+	}
+
+	close(reader.release)
+	for range chunks {
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	want := [][]string{{"Let me check that for"}, {"you.", "Done."}}
+	if len(fake.batches) != len(want) {
+		t.Fatalf("flushed batches = %q, want %q", fake.batches, want)
+	}
+	for i := range want {
+		if !slices.Equal(fake.batches[i], want[i]) {
+			t.Errorf("flush %d sent %q, want %q", i, fake.batches[i], want[i])
+		}
+	}
+}
+
+func TestSynthesizeFromReader_RawChunking(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"), WithRawChunking(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Sentence mode would split the first unit and merge it with the second
+	reader := &unitReader{units: []string{"Hello there. How ", "are you\n", "   ", " Fine. Thanks"}}
+	chunks, err := p.SynthesizeFromReader(ctx, reader, tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReader() error = %v", err)
+	}
+
+	// One flush per unit, then the final flush at EOF
+	var finals int
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			t.Fatalf("chunk error = %v", chunk.Error)
+		}
+		if chunk.IsFinal {
+			if finals++; finals == 4 {
+				cancel()
+			}
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	want := [][]string{{"Hello there. How"}, {"are you"}, {"Fine. Thanks"}, nil}
+	if len(fake.batches) != len(want) {
+		t.Fatalf("flushed batches = %q, want %q", fake.batches, want)
+	}
+	for i := range want {
+		if !slices.Equal(fake.batches[i], want[i]) {
+			t.Errorf("flush %d sent %q, want %q", i, fake.batches[i], want[i])
+		}
+	}
+}
+
+func TestSynthesizeFromReader_ClosesAfterEOF(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	chunks, err := p.SynthesizeFromReader(context.Background(), strings.NewReader("Hello there. How are you?"), tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeFromReader() error = %v", err)
+	}
+
+	var audio []string
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				done = true
+			} else if len(chunk.Audio) > 0 {
+				audio = append(audio, string(chunk.Audio))
+			}
+		case <-timeout:
+			t.Fatal("channel not closed after the reader's audio was delivered")
+		}
+	}
+
+	if want := []string{"Hello there.", "How are you?"}; !slices.Equal(audio, want) {
+		t.Errorf("audio = %q, want %q", audio, want)
+	}
+	if !fake.waitStopped() {
+		t.Error("connection not closed after the session ended")
+	}
+}
+
+func TestTTSCallbackHandler_CloseReleasesBlockedSend(t *testing.T) {
+	var observed atomic.Int64
+	handler := &ttsCallbackHandler{
+		chunkCh:  make(chan tts.StreamChunk, 1),
+		ctx:      context.Background(),
+		observer: func(any) { observed.Add(1) },
+		done:     make(chan struct{}),
+	}
+
+	// The second chunk waits on the full channel
+	handler.sendChunk(tts.StreamChunk{Audio: []byte("one")})
+	sent := make(chan struct{})
+	go func() {
+		handler.sendChunk(tts.StreamChunk{Audio: []byte("two")})
+		close(sent)
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		handler.close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close() blocked behind a send to a full channel")
+	}
+	<-sent
+
+	// Chunks after the close are dropped without being observed
+	handler.sendChunk(tts.StreamChunk{Audio: []byte("three")})
+
+	var audio []string
+	for chunk := range handler.chunkCh {
+		audio = append(audio, string(chunk.Audio))
+	}
+	if want := []string{"one"}; !slices.Equal(audio, want) {
+		t.Errorf("delivered %q, want %q", audio, want)
+	}
+	if got := observed.Load(); got != 1 {
+		t.Errorf("observed %d chunks, want only the 1 delivered", got)
+	}
+}
+
+func TestSynthesizeStream_Observer(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		observed []tts.StreamChunk
+	)
+	p, err := New(WithAPIKey("test-key"), WithObserver(func(item any) {
+		chunk, ok := item.(tts.StreamChunk)
+		if !ok {
+			t.Errorf("observed %T, want tts.StreamChunk", item)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		observed = append(observed, chunk)
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fake := &fakeSpeakClient{audio: [][]byte{[]byte("one"), []byte("two")}}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunks, err := p.SynthesizeStream(ctx, "Hello", tts.SynthesisConfig{})
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+
+	var delivered int
+	for chunk := range chunks {
+		delivered++
+		if chunk.IsFinal {
+			cancel()
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(observed) != 3 || delivered != 3 {
+		t.Fatalf("observed %d and delivered %d chunks, want 3 each", len(observed), delivered)
+	}
+	if string(observed[0].Audio) != "one" || string(observed[1].Audio) != "two" || !observed[2].IsFinal {
+		t.Errorf("observed = %+v, want both audio chunks then the final chunk", observed)
+	}
+}
@@ -0,0 +1,65 @@
+package tts
+
+import (
+	"context"
+
+	"github.com/deepgram/deepgram-go-sdk/v3/pkg/api/version"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// Request describes the Deepgram requests the provider would send for a
+// SynthesisConfig, after all defaults and mappings are applied.
+type Request struct {
+	// SpeakURL is the REST endpoint used by Synthesize.
+	SpeakURL string
+
+	// Speak holds the options sent by Synthesize.
+	Speak *interfaces.SpeakOptions
+
+	// StreamURL is the WebSocket endpoint used by the streaming methods.
+	StreamURL string
+
+	// Stream holds the options sent by the streaming methods.
+	Stream *interfaces.WSSpeakOptions
+
+	// SampleRate is the sample rate of the audio Synthesize would return.
+	SampleRate int
+}
+
+// BuildRequest returns the Deepgram requests that would be sent for config
+// without contacting Deepgram, for debugging and cost estimation.
+func (p *Provider) BuildRequest(config tts.SynthesisConfig) (*Request, error) {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return nil, err
+	}
+
+	ctx := omnivoice.SpeakContext(context.Background(), config)
+
+	clientOptions := p.endpoint.RESTOptions()
+	clientOptions.APIKey = p.apiKey
+	if err := clientOptions.Parse(); err != nil {
+		return nil, err
+	}
+	streamHost := p.endpoint.StreamHost(clientOptions.Host)
+
+	req := &Request{
+		Speak:  omnivoice.ConfigToSpeakOptions(config),
+		Stream: omnivoice.ConfigToWSSpeakOptions(config),
+	}
+	req.SampleRate = omnivoice.EffectiveSampleRate(req.Speak)
+
+	var err error
+	req.SpeakURL, err = version.GetSpeakAPI(ctx, clientOptions.Host, clientOptions.APIVersion, clientOptions.Path, req.Speak)
+	if err != nil {
+		return nil, err
+	}
+	req.StreamURL, err = version.GetSpeakStreamAPI(ctx, streamHost, clientOptions.APIVersion, clientOptions.Path, req.Stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
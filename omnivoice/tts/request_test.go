@@ -0,0 +1,56 @@
+package tts
+
+import (
+	"testing"
+
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+func TestBuildRequest(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, err := p.BuildRequest(tts.SynthesisConfig{
+		VoiceID:      "aura-2-thalia-en",
+		OutputFormat: "mp3",
+		SampleRate:   24000,
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	if want := "https://api.deepgram.com/v1/speak?encoding=mp3&model=aura-2-thalia-en"; req.SpeakURL != want {
+		t.Errorf("SpeakURL = %s, want %s", req.SpeakURL, want)
+	}
+	if want := "wss://api.deepgram.com/v1/speak?encoding=mp3&model=aura-2-thalia-en&sample_rate=24000"; req.StreamURL != want {
+		t.Errorf("StreamURL = %s, want %s", req.StreamURL, want)
+	}
+	if req.SampleRate != 22050 {
+		t.Errorf("SampleRate = %d, want 22050", req.SampleRate)
+	}
+}
+
+func TestBuildRequest_Extra(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, err := p.BuildRequest(tts.SynthesisConfig{
+		VoiceID:    "aura-2-thalia-en",
+		Extensions: map[string]any{omnivoice.ExtExtra: map[string]string{"tenant": "acme"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	if want := "https://api.deepgram.com/v1/speak?encoding=linear16&extra=tenant%3Aacme&model=aura-2-thalia-en"; req.SpeakURL != want {
+		t.Errorf("SpeakURL = %s, want %s", req.SpeakURL, want)
+	}
+	if want := "wss://api.deepgram.com/v1/speak?encoding=linear16&extra=tenant%3Aacme&model=aura-2-thalia-en"; req.StreamURL != want {
+		t.Errorf("StreamURL = %s, want %s", req.StreamURL, want)
+	}
+}
@@ -0,0 +1,58 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/deepgram/deepgram-go-sdk/v3/pkg/api/version"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+)
+
+// speakRequest is the JSON body of a Deepgram speak request.
+type speakRequest struct {
+	Text string `json:"text"`
+}
+
+// speak synthesizes text into buf and returns the number of characters
+// Deepgram billed.
+//
+// It replaces the SDK's ToStream, which discards HTTP errors and reports
+// them as a failure to parse the character count, so that callers can
+// inspect the Deepgram status and error message.
+func (p *Provider) speak(ctx context.Context, text string, opts *interfaces.SpeakOptions, buf *interfaces.RawResponse) (int, error) {
+	if err := opts.Check(); err != nil {
+		return 0, err
+	}
+
+	c := p.client.Client
+	uri, err := version.GetSpeakAPI(ctx, c.Options.Host, c.Options.APIVersion, c.Options.Path, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(speakRequest{Text: text})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := c.SetupRequest(ctx, http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	var headers map[string]string
+	err = c.HTTPClient.Do(ctx, req, func(res *http.Response) error {
+		var err error
+		headers, err = c.HandleResponse(res, []string{"char-count"}, buf)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	chars, _ := strconv.Atoi(headers["char-count"])
+	return chars, nil
+}
@@ -0,0 +1,104 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+// StreamToWAV synthesizes text with SynthesizeStream and writes the audio to
+// w as a WAV file, starting at the current offset of w.
+//
+// The length of streamed audio is not known until it ends, so a placeholder
+// header is written first and patched with the final sizes once Deepgram has
+// finished. If synthesis fails part way, the header is still patched to
+// describe the audio written so far and the error is returned.
+//
+// Only linear16 (the default), mulaw, and alaw output can be stored in WAV
+// without re-encoding; other formats return tts.ErrInvalidConfig.
+func (p *Provider) StreamToWAV(ctx context.Context, text string, config tts.SynthesisConfig, w io.WriteSeeker) error {
+	config = p.withDefaults(config)
+	if err := omnivoice.ValidateSynthesisConfig(config); err != nil {
+		return err
+	}
+
+	opts := omnivoice.ConfigToWSSpeakOptions(config)
+	sampleRate := streamSampleRate(opts)
+	if rate := omnivoice.OutputSampleRate(config); rate > 0 {
+		sampleRate = rate
+	}
+	info, ok := omnivoice.NewWAVInfo(opts.Encoding, sampleRate)
+	if !ok {
+		return fmt.Errorf("%w: WAV output requires linear16, mulaw, or alaw, got %q", tts.ErrInvalidConfig, config.OutputFormat)
+	}
+
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to locate WAV header: %w", err)
+	}
+	if _, err := w.Write(info.Header()); err != nil {
+		return fmt.Errorf("failed to write WAV header: %w", err)
+	}
+
+	chunks, err := p.SynthesizeStream(ctx, text, config)
+	if err != nil {
+		return err
+	}
+
+	// Read the stream to its end even after a failure, so that its sender
+	// is not left blocked on a full channel
+	var streamErr error
+	for chunk := range chunks {
+		if streamErr != nil {
+			continue
+		}
+		if chunk.Error != nil {
+			streamErr = chunk.Error
+			continue
+		}
+		if len(chunk.Audio) > 0 {
+			n, err := w.Write(chunk.Audio)
+			info.DataSize += int64(n)
+			if err != nil {
+				streamErr = fmt.Errorf("failed to write WAV audio: %w", err)
+			}
+		}
+	}
+	if streamErr == nil {
+		streamErr = ctx.Err()
+	}
+
+	if err := patchWAVHeader(w, start, info); err != nil {
+		return err
+	}
+	return streamErr
+}
+
+// patchWAVHeader pads the audio in w to an even length, rewrites the header
+// at offset start with the sizes in info, and leaves w positioned after the
+// audio.
+func patchWAVHeader(w io.WriteSeeker, start int64, info *omnivoice.WAVInfo) error {
+	if info.DataSize%2 != 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return fmt.Errorf("failed to pad WAV audio: %w", err)
+		}
+	}
+	end, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to locate end of WAV audio: %w", err)
+	}
+
+	if _, err := w.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to WAV header: %w", err)
+	}
+	if _, err := w.Write(info.Header()); err != nil {
+		return fmt.Errorf("failed to patch WAV header: %w", err)
+	}
+	if _, err := w.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek past WAV audio: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wsinterfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/speak/v1/websocket/interfaces"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	"github.com/plexusone/omnivoice-core/tts"
+	"github.com/plexusone/omnivoice-deepgram/omnivoice"
+)
+
+func TestStreamToWAV(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	audio := [][]byte{bytes.Repeat([]byte{1}, 3200), bytes.Repeat([]byte{2}, 1600)}
+	fake := &fakeSpeakClient{audio: audio}
+	p.dial = func(ctx context.Context, opts *interfaces.WSSpeakOptions, handler wsinterfaces.SpeakMessageCallback) (SpeakClient, error) {
+		fake.handler = handler.(*ttsCallbackHandler)
+		return fake, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "out.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer f.Close()
+
+	config := tts.SynthesisConfig{OutputFormat: "linear16", SampleRate: 16000}
+	if err := p.StreamToWAV(context.Background(), "Hello", config, f); err != nil {
+		t.Fatalf("StreamToWAV() error = %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	info, err := omnivoice.ReadWAVInfo(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadWAVInfo() error = %v", err)
+	}
+	if info.AudioFormat != 1 || info.Channels != 1 || info.SampleRate != 16000 || info.BitsPerSample != 16 {
+		t.Errorf("format = %+v, want 16 kHz mono 16-bit PCM", info)
+	}
+	if info.DataSize != 4800 || int64(len(data)) != info.DataOffset+info.DataSize {
+		t.Errorf("DataSize = %d, file length = %d, want 4800 bytes of audio after the header", info.DataSize, len(data))
+	}
+	if err := omnivoice.CheckWAVLength(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Errorf("CheckWAVLength() error = %v", err)
+	}
+	if want := append(audio[0], audio[1]...); !bytes.Equal(data[info.DataOffset:], want) {
+		t.Error("WAV audio does not match the streamed chunks")
+	}
+}
+
+func TestStreamToWAV_UnsupportedFormat(t *testing.T) {
+	p, err := New(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer f.Close()
+
+	err = p.StreamToWAV(context.Background(), "Hello", tts.SynthesisConfig{OutputFormat: "mp3"}, f)
+	if !errors.Is(err, tts.ErrInvalidConfig) {
+		t.Errorf("StreamToWAV() error = %v, want ErrInvalidConfig", err)
+	}
+}
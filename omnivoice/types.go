@@ -0,0 +1,415 @@
+package omnivoice
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+// EventWarning is the type of stream events carrying a non-fatal notice
+// from Deepgram, such as a deprecated parameter. The notice is in
+// StreamEvent.Warning.
+const EventWarning stt.StreamEventType = "warning"
+
+// Warning is a non-fatal notice sent by Deepgram on a stream.
+type Warning struct {
+	// Code identifies the kind of warning, when Deepgram provides one.
+	Code string
+
+	// Message describes the warning.
+	Message string
+}
+
+// EventClose is the type of the last event of a stream, sent just before
+// its event channel is closed. Why the stream ended is in
+// StreamEvent.Close; for closes caused by an error, StreamEvent.Error is
+// also set.
+const EventClose stt.StreamEventType = "close"
+
+// EventMetadata is the type of stream events carrying the metadata Deepgram
+// sends about a stream, usually once it has processed all the audio. The
+// metadata is in StreamEvent.Metadata.
+const EventMetadata stt.StreamEventType = "metadata"
+
+// Metadata describes the Deepgram request that produced a stream, for
+// auditing usage and quoting in support tickets.
+type Metadata struct {
+	// RequestID is Deepgram's identifier for the request.
+	RequestID string
+
+	// Duration is the length of the audio Deepgram processed.
+	Duration time.Duration
+
+	// Channels is the number of audio channels.
+	Channels int
+
+	// Models lists the models Deepgram reports using, as in
+	// TranscriptionResult.Models.
+	Models []ModelInfo
+}
+
+// CloseReason identifies what ended a stream.
+type CloseReason string
+
+const (
+	// CloseClient means the caller closed the stream, or a provider helper
+	// feeding it audio from a reader stopped on an error.
+	CloseClient CloseReason = "client"
+
+	// CloseContext means the stream's context was canceled or timed out.
+	CloseContext CloseReason = "context"
+
+	// CloseIdle means the stream was closed by its idle timeout.
+	CloseIdle CloseReason = "idle"
+
+	// CloseServer means Deepgram or the network closed the connection.
+	CloseServer CloseReason = "server"
+)
+
+// StreamClose describes the end of a stream.
+type StreamClose struct {
+	// Reason is what ended the stream.
+	Reason CloseReason
+
+	// Err is the error that ended the stream, or nil for a clean close:
+	// the context error for CloseContext, the idle timeout for CloseIdle,
+	// the last error Deepgram reported, if any, for CloseServer, and the
+	// read or write error of a helper feeding audio for CloseClient.
+	Err error
+
+	// ProcessedDuration is the audio Deepgram had transcribed when the
+	// stream ended; see Stream.ProcessedDuration in the stt package.
+	ProcessedDuration time.Duration
+}
+
+// StreamEvent is a streaming transcription event carrying Deepgram-specific
+// detail on top of the core OmniVoice event.
+type StreamEvent struct {
+	stt.StreamEvent
+
+	// FromFinalize is set on the final result Deepgram produces in response
+	// to a Finalize request (see Stream.Reset). It is a forced final rather
+	// than one ending at a natural pause, so turn-taking logic should not
+	// treat it as the speaker having finished.
+	FromFinalize bool
+
+	// Words contains the words of the current transcript with stable IDs.
+	// Populated for both interim and final results.
+	Words []WordInfo
+
+	// DebugWords contains Deepgram's unmodified word objects for the top
+	// alternative. Only populated when debug words are enabled.
+	DebugWords []Word
+
+	// Warning is the notice carried by an EventWarning event.
+	Warning *Warning
+
+	// Turns splits the event's words into one segment per speaker turn.
+	// Only populated with DiarizationPerTurn grouping, in which case
+	// Segment.Speaker is also set when the event has a single speaker.
+	Turns []stt.Segment
+
+	// Close describes why the stream ended on an EventClose event.
+	Close *StreamClose
+
+	// Alternatives lists every transcript Deepgram returned for the
+	// message, best first, when ExtAlternatives asks for more than one.
+	// The first is the one in Transcript and Words. Nil otherwise.
+	Alternatives []TranscriptAlternative
+
+	// Metadata is the request metadata carried by an EventMetadata event.
+	Metadata *Metadata
+}
+
+// TranscriptionResult is a batch transcription result carrying
+// Deepgram-specific detail on top of the core OmniVoice result.
+type TranscriptionResult struct {
+	stt.TranscriptionResult
+
+	// Words contains the words of the top alternative of the first channel,
+	// with the per-word detail the core Word type cannot carry.
+	Words []WordInfo
+
+	// DebugWords contains Deepgram's unmodified word objects for the top
+	// alternative of the first channel. Only populated when debug words are
+	// enabled.
+	DebugWords []Word
+
+	// ProcessingDuration is the wall-clock time from sending the request
+	// that produced this result to receiving Deepgram's response. It
+	// includes network transfer, since Deepgram does not report its own
+	// processing time. Zero when no request was sent, such as for empty
+	// audio.
+	ProcessingDuration time.Duration
+
+	// RequestID is Deepgram's identifier for the request, for auditing and
+	// support tickets. Empty when no request was sent.
+	RequestID string
+
+	// ModelName and ModelVersion identify the model that served the
+	// request, such as "general-nova-3" and "2024-12-20.0". When Deepgram
+	// used several models, they describe the first one listed; see Models.
+	// Empty if Deepgram did not report model details.
+	ModelName    string
+	ModelVersion string
+
+	// Models lists every model Deepgram reports using for the request, in
+	// the order of the response metadata. Multichannel audio and features
+	// such as language detection can involve more than one.
+	Models []ModelInfo
+
+	// Searches holds the hits for each term requested with ExtSearch, in
+	// the order Deepgram returns them, for the first channel.
+	Searches []SearchResult
+
+	// Summary is Deepgram's summary of the transcript when requested with
+	// ExtSummarize. Empty if none was requested or Deepgram could not
+	// produce one, such as for very short audio.
+	Summary string
+
+	// Topics lists the topics Deepgram detected when requested with
+	// ExtTopics, one per topic of each transcript span, in transcript
+	// order.
+	Topics []Topic
+
+	// Intents lists the speaker intents Deepgram recognized when requested
+	// with ExtIntents, one per intent of each transcript span, in
+	// transcript order.
+	Intents []Intent
+
+	// Sentiments lists the sentiment of each transcript span when
+	// requested with ExtSentiment, in transcript order.
+	Sentiments []Sentiment
+
+	// OverallSentiment is the average sentiment of the whole transcript
+	// when requested with ExtSentiment, with a zero Span. Nil if Deepgram
+	// did not report one.
+	OverallSentiment *Sentiment
+
+	// Entities lists the named entities Deepgram detected in the first
+	// channel when requested with ExtEntities, in transcript order.
+	Entities []Entity
+
+	// Alternatives lists every transcript Deepgram returned for the first
+	// channel, best first, when ExtAlternatives asks for more than one.
+	// The first is the one in Text and Words. Nil otherwise.
+	Alternatives []TranscriptAlternative
+
+	// Paragraphs groups the first channel's transcript into paragraphs of
+	// sentences for display. Deepgram returns them when requested with
+	// ExtParagraphs, and also with smart formatting. Nil when absent.
+	Paragraphs []Paragraph
+}
+
+// Paragraph is a paragraph of a batch transcript.
+type Paragraph struct {
+	// Text is the paragraph's sentences joined with spaces.
+	Text string
+
+	// Start and End bound the paragraph in the audio.
+	Start time.Duration
+	End   time.Duration
+
+	// Speaker identifies the speaker of the paragraph when diarization is
+	// enabled.
+	Speaker string
+
+	// Sentences lists the sentences of the paragraph in order.
+	Sentences []Sentence
+}
+
+// Sentence is a sentence of a Paragraph.
+type Sentence struct {
+	// Text is the sentence.
+	Text string
+
+	// Start and End bound the sentence in the audio.
+	Start time.Duration
+	End   time.Duration
+}
+
+// TranscriptAlternative is one of the transcripts Deepgram considered for
+// the same audio, for reranking downstream.
+type TranscriptAlternative struct {
+	// Transcript is the text of the alternative.
+	Transcript string
+
+	// Confidence is Deepgram's confidence in the alternative, from 0 to 1.
+	Confidence float64
+
+	// Words contains the words of the alternative.
+	Words []WordInfo
+}
+
+// TextSpan is a span of a batch transcript that topics, intents, and
+// sentiment are detected in.
+type TextSpan struct {
+	// Text is the transcript text of the span.
+	Text string
+
+	// StartWord and EndWord are the indexes of the span's first and last
+	// words in TranscriptionResult.Words.
+	StartWord int
+	EndWord   int
+
+	// Start and End bound the span in the audio, from the timings of its
+	// first and last words. Zero if the result has no such words.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Topic is a topic detected in a span of a transcript.
+type Topic struct {
+	// Label names the topic, such as "Billing".
+	Label string
+
+	// Confidence is Deepgram's confidence in the topic, from 0 to 1.
+	Confidence float64
+
+	// Span is the part of the transcript the topic was detected in.
+	Span TextSpan
+}
+
+// Sentiment is the sentiment of a span of a transcript.
+type Sentiment struct {
+	// Label is "positive", "neutral", or "negative".
+	Label string
+
+	// Score ranges from -1, most negative, to 1, most positive.
+	Score float64
+
+	// Span is the part of the transcript the sentiment applies to.
+	Span TextSpan
+}
+
+// Entity is a named entity detected in a transcript, such as a person,
+// date, or location.
+type Entity struct {
+	// Label is the kind of entity, such as "NAME", "DATE", or "LOCATION".
+	Label string
+
+	// Value is the entity as transcribed.
+	Value string
+
+	// Confidence is Deepgram's confidence in the entity, from 0 to 1.
+	Confidence float64
+
+	// Span is the part of the transcript holding the entity; its Text is
+	// Value.
+	Span TextSpan
+}
+
+// Intent is a speaker intent recognized in a span of a transcript.
+type Intent struct {
+	// Label names the intent, such as "Request refund".
+	Label string
+
+	// Confidence is Deepgram's confidence in the intent, from 0 to 1.
+	Confidence float64
+
+	// Span is the part of the transcript the intent was recognized in.
+	Span TextSpan
+}
+
+// SearchResult holds the places a term requested with ExtSearch was heard.
+type SearchResult struct {
+	// Query is the searched term.
+	Query string
+
+	// Hits lists the matches, which Deepgram finds acoustically rather
+	// than in the transcript text, so they may include low-confidence
+	// near matches.
+	Hits []SearchHit
+}
+
+// SearchHit is one match of a searched term.
+type SearchHit struct {
+	// Confidence is Deepgram's confidence in the match, from 0 to 1.
+	Confidence float64
+
+	// Start and End bound the match in the audio.
+	Start time.Duration
+	End   time.Duration
+
+	// Snippet is the transcript text at the match.
+	Snippet string
+}
+
+// ModelInfo describes a Deepgram model that served a request.
+type ModelInfo struct {
+	// UUID is Deepgram's identifier for the model.
+	UUID string
+
+	// Name is the model name, such as "general-nova-3".
+	Name string
+
+	// Version is the model version, such as "2024-12-20.0".
+	Version string
+
+	// Arch is the model architecture, such as "nova-3".
+	Arch string
+}
+
+// WordInfo is a transcribed word with a stable identity across interim
+// updates of the same utterance.
+type WordInfo struct {
+	stt.Word
+
+	// ID identifies the word across successive interim and final results.
+	// It is derived from the word's start time within the utterance, so a
+	// word keeps its ID while Deepgram revises its text or confidence, and
+	// UIs can update it in place rather than re-rendering the whole
+	// transcript.
+	ID string
+
+	// Index is the position of the word within the current transcript.
+	Index int
+
+	// PunctuatedWord is the word with the punctuation and capitalization
+	// Deepgram applies to the transcript, such as "Hello," for "hello".
+	// Together with Speaker and the word timings it is enough to render a
+	// readable diarized transcript. Empty when punctuation is disabled.
+	PunctuatedWord string
+
+	// Language is the language Deepgram detected for this word, as returned
+	// by multilingual models. Empty when not provided.
+	Language string
+}
+
+// WordID returns the stable ID for a word starting at the given offset
+// into the stream.
+func WordID(start time.Duration) string {
+	return "w" + strconv.Itoa(int(start.Round(time.Millisecond).Milliseconds()))
+}
+
+// StreamChunk is a streaming synthesis chunk carrying playback timing on
+// top of the core OmniVoice chunk, for scheduling audio against captions.
+//
+// Timing is derived from the number of audio bytes received and is only
+// known for the headerless PCM encodings linear16, mulaw, and alaw. For
+// other formats Offset and Duration are zero.
+//
+// Encoding and SampleRate describe the audio as delivered, which can differ
+// from the request. They are taken from Deepgram's Metadata message when
+// it reports them, and otherwise derived from the connection's options:
+// Deepgram produces mp3, aac, and opus at a fixed rate whatever rate is
+// asked for, and fills in defaults for unset fields. Audio resampled with
+// ExtOutputSampleRate has that rate.
+type StreamChunk struct {
+	tts.StreamChunk
+
+	// Encoding is the Deepgram encoding of Audio, such as "linear16".
+	Encoding string
+
+	// SampleRate is the sample rate of Audio in Hz.
+	SampleRate int
+
+	// Offset is the playback position of the start of Audio, measured from
+	// the start of the stream.
+	Offset time.Duration
+
+	// Duration is the playback length of Audio.
+	Duration time.Duration
+}
@@ -0,0 +1,177 @@
+package omnivoice
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+// ValidateTranscriptionConfig checks config for values Deepgram would
+// reject or silently ignore. It reports every problem found, joined with
+// errors.Join, and each wraps stt.ErrInvalidConfig. A nil result means the
+// config is valid.
+func ValidateTranscriptionConfig(config stt.TranscriptionConfig) error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{stt.ErrInvalidConfig}, args...)...))
+	}
+
+	if err := ValidateEncoding(config.Encoding); err != nil {
+		errs = append(errs, err)
+	}
+	if config.SampleRate < 0 {
+		add("SampleRate must not be negative, got %d", config.SampleRate)
+	}
+	if config.Channels < 0 {
+		add("Channels must not be negative, got %d", config.Channels)
+	}
+	if config.MaxSpeakers < 0 {
+		add("MaxSpeakers must not be negative, got %d", config.MaxSpeakers)
+	}
+	if config.MaxSpeakers > 0 && !config.EnableSpeakerDiarization {
+		add("MaxSpeakers requires EnableSpeakerDiarization")
+	}
+	if slices.Contains(config.Keywords, "") {
+		add("Keywords must not contain empty strings")
+	}
+	for _, key := range []string{ExtNumerals, ExtMeasurements, ExtSmartFormat, ExtInterimResults, ExtDictation, ExtProfanityFilter, ExtTopics, ExtIntents, ExtSentiment, ExtEntities, ExtDetectLanguage, ExtParagraphs} {
+		if v, ok := config.Extensions[key]; ok {
+			if _, isBool := v.(bool); !isBool {
+				add("extension %s must be a bool, got %T", key, v)
+			}
+		}
+	}
+	for _, key := range []string{ExtFormatLocale, ExtDiarizationGrouping} {
+		if v, ok := config.Extensions[key]; ok {
+			if _, isString := v.(string); !isString {
+				add("extension %s must be a string, got %T", key, v)
+			}
+		}
+	}
+	if v, ok := config.Extensions[ExtRedact]; ok {
+		if categories, isSlice := v.([]string); !isSlice {
+			add("extension %s must be a []string, got %T", ExtRedact, v)
+		} else if slices.ContainsFunc(categories, func(c string) bool { return strings.TrimSpace(c) == "" }) {
+			add("extension %s must not contain empty categories", ExtRedact)
+		}
+	}
+	if v, ok := config.Extensions[ExtAlternatives]; ok {
+		if n, isInt := v.(int); !isInt {
+			add("extension %s must be an int, got %T", ExtAlternatives, v)
+		} else if n < 1 {
+			add("extension %s must be at least 1, got %d", ExtAlternatives, n)
+		}
+	}
+	if v, ok := config.Extensions[ExtSummarize]; ok {
+		switch v.(type) {
+		case bool, string:
+		default:
+			add("extension %s must be a bool or a string, got %T", ExtSummarize, v)
+		}
+	}
+	for _, key := range []string{ExtSearch, ExtReplace} {
+		if v, ok := config.Extensions[key]; ok {
+			if terms, isSlice := v.([]string); !isSlice {
+				add("extension %s must be a []string, got %T", key, v)
+			} else if slices.Contains(terms, "") {
+				add("extension %s must not contain empty strings", key)
+			}
+		}
+	}
+	if extensionBool(config, ExtDictation) && !config.EnablePunctuation {
+		add("extension %s requires EnablePunctuation", ExtDictation)
+	}
+	if v, ok := config.Extensions[ExtUtteranceEnd]; ok {
+		silence, isDuration := utteranceEnd(config)
+		switch {
+		case !isDuration:
+			add("extension %s must be an int or a time.Duration, got %T", ExtUtteranceEnd, v)
+		case silence < time.Millisecond:
+			add("extension %s must be at least 1ms, got %s", ExtUtteranceEnd, silence)
+		case !interimResults(config):
+			add("extension %s requires interim results", ExtUtteranceEnd)
+		}
+	}
+	switch g := DiarizationGroupingFor(config); g {
+	case DiarizationPerWord, DiarizationPerTurn:
+	default:
+		add("extension %s must be %q or %q, got %q", ExtDiarizationGrouping, DiarizationPerWord, DiarizationPerTurn, g)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateSynthesisConfig checks config for values Deepgram would reject.
+// It reports every problem found, joined with errors.Join, and each wraps
+// tts.ErrInvalidConfig. A nil result means the config is valid.
+func ValidateSynthesisConfig(config tts.SynthesisConfig) error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{tts.ErrInvalidConfig}, args...)...))
+	}
+
+	if _, ok := ttsEncodingAliases[config.OutputFormat]; !ok && config.OutputFormat != "" {
+		formats := make([]string, 0, len(ttsEncodingAliases))
+		for name := range ttsEncodingAliases {
+			formats = append(formats, name)
+		}
+		slices.Sort(formats)
+		add("unsupported output format %q (supported: %s)", config.OutputFormat, strings.Join(formats, ", "))
+	}
+	if _, ok := ttsEncodingAliases[config.OutputFormat]; ok || config.OutputFormat == "" {
+		model, encoding := ttsModel(config), mapTTSEncoding(config.OutputFormat)
+		if supported, listed := modelEncodings(model); listed && !slices.Contains(supported, encoding) {
+			add("model %q does not support %s output (supported: %s)", model, encoding, strings.Join(supported, ", "))
+		}
+	}
+	if config.SampleRate < 0 {
+		add("SampleRate must not be negative, got %d", config.SampleRate)
+	}
+	if config.Speed < 0 {
+		add("Speed must not be negative, got %g", config.Speed)
+	}
+	if v, ok := config.Extensions[ExtOutputSampleRate]; ok {
+		rate, isInt := v.(int)
+		switch {
+		case !isInt:
+			add("extension %s must be an int, got %T", ExtOutputSampleRate, v)
+		case rate <= 0:
+			add("extension %s must be positive, got %d", ExtOutputSampleRate, rate)
+		case mapTTSEncoding(config.OutputFormat) != "linear16":
+			add("extension %s requires linear16 output, got %q", ExtOutputSampleRate, config.OutputFormat)
+		}
+	}
+	if v, ok := config.Extensions[ExtLanguage]; ok {
+		if _, isString := v.(string); !isString {
+			add("extension %s must be a string, got %T", ExtLanguage, v)
+		}
+	}
+	if v, ok := config.Extensions[ExtBitRate]; ok {
+		rate, isInt := v.(int)
+		encoding := mapTTSEncoding(config.OutputFormat)
+		allowed, lossy := ttsBitRates[encoding]
+		switch {
+		case !isInt:
+			add("extension %s must be an int, got %T", ExtBitRate, v)
+		case lossy && !allowed.allows(rate):
+			add("extension %s for %s must be %s, got %d", ExtBitRate, encoding, allowed, rate)
+		}
+	}
+	if v, ok := config.Extensions[ExtExtra]; ok {
+		extra, isMap := v.(map[string]string)
+		_, emptyKey := extra[""]
+		switch {
+		case !isMap:
+			add("extension %s must be a map[string]string, got %T", ExtExtra, v)
+		case emptyKey:
+			add("extension %s keys must not be empty", ExtExtra)
+		}
+	}
+
+	return errors.Join(errs...)
+}
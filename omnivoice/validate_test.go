@@ -0,0 +1,220 @@
+package omnivoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/omnivoice-core/stt"
+	"github.com/plexusone/omnivoice-core/tts"
+)
+
+func TestValidateTranscriptionConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config stt.TranscriptionConfig
+		want   []string
+	}{
+		{"zero value", stt.TranscriptionConfig{}, nil},
+		{"valid", stt.TranscriptionConfig{Encoding: "mulaw", SampleRate: 8000, EnableSpeakerDiarization: true, MaxSpeakers: 2}, nil},
+		{"encoding typo", stt.TranscriptionConfig{Encoding: "mulw"}, []string{`unsupported encoding "mulw"`}},
+		{"negative values", stt.TranscriptionConfig{SampleRate: -1, Channels: -2}, []string{"SampleRate", "Channels"}},
+		{"max speakers without diarization", stt.TranscriptionConfig{MaxSpeakers: 3}, []string{"requires EnableSpeakerDiarization"}},
+		{"empty keyword", stt.TranscriptionConfig{Keywords: []string{"Deepgram", ""}}, []string{"Keywords"}},
+		{"non-bool extension", stt.TranscriptionConfig{Extensions: map[string]any{ExtNumerals: "yes"}}, []string{ExtNumerals}},
+		{"non-bool smart format", stt.TranscriptionConfig{Extensions: map[string]any{ExtSmartFormat: "off"}}, []string{ExtSmartFormat}},
+		{"utterance end", stt.TranscriptionConfig{Extensions: map[string]any{ExtUtteranceEnd: 1500 * time.Millisecond}}, nil},
+		{"utterance end not a duration", stt.TranscriptionConfig{Extensions: map[string]any{ExtUtteranceEnd: "1s"}}, []string{"must be an int or a time.Duration"}},
+		{"utterance end too short", stt.TranscriptionConfig{Extensions: map[string]any{ExtUtteranceEnd: 0}}, []string{"at least 1ms"}},
+		{
+			"utterance end without interim results",
+			stt.TranscriptionConfig{Extensions: map[string]any{ExtUtteranceEnd: 1000, ExtInterimResults: false}},
+			[]string{"requires interim results"},
+		},
+		{"dictation", stt.TranscriptionConfig{EnablePunctuation: true, Extensions: map[string]any{ExtDictation: true}}, nil},
+		{"dictation without punctuation", stt.TranscriptionConfig{Extensions: map[string]any{ExtDictation: true}}, []string{"requires EnablePunctuation"}},
+		{"redaction", stt.TranscriptionConfig{Extensions: map[string]any{ExtRedact: []string{"pci", "future_category"}}}, nil},
+		{"redaction not a slice", stt.TranscriptionConfig{Extensions: map[string]any{ExtRedact: "pci"}}, []string{"must be a []string"}},
+		{"empty redaction category", stt.TranscriptionConfig{Extensions: map[string]any{ExtRedact: []string{"pci", " "}}}, []string{"empty categories"}},
+		{"search and replace", stt.TranscriptionConfig{Extensions: map[string]any{ExtSearch: []string{"refund"}, ExtReplace: []string{"acme:ACME"}}}, nil},
+		{"search not a slice", stt.TranscriptionConfig{Extensions: map[string]any{ExtSearch: "refund"}}, []string{"must be a []string"}},
+		{"empty replacement", stt.TranscriptionConfig{Extensions: map[string]any{ExtReplace: []string{""}}}, []string{ExtReplace}},
+		{"summarize version", stt.TranscriptionConfig{Extensions: map[string]any{ExtSummarize: "v2"}}, nil},
+		{"summarize wrong type", stt.TranscriptionConfig{Extensions: map[string]any{ExtSummarize: 2}}, []string{"must be a bool or a string"}},
+		{"alternatives", stt.TranscriptionConfig{Extensions: map[string]any{ExtAlternatives: 3}}, nil},
+		{"alternatives below one", stt.TranscriptionConfig{Extensions: map[string]any{ExtAlternatives: 0}}, []string{"at least 1"}},
+		{"per-turn grouping", stt.TranscriptionConfig{Extensions: map[string]any{ExtDiarizationGrouping: "per_turn"}}, nil},
+		{"unknown grouping", stt.TranscriptionConfig{Extensions: map[string]any{ExtDiarizationGrouping: "turns"}}, []string{`got "turns"`}},
+		{
+			"several problems",
+			stt.TranscriptionConfig{Encoding: "pcm16", Channels: -1, MaxSpeakers: -1},
+			[]string{"pcm16", "Channels", "MaxSpeakers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTranscriptionConfig(tt.config)
+			checkProblems(t, err, stt.ErrInvalidConfig, tt.want)
+		})
+	}
+}
+
+func TestValidateSynthesisConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tts.SynthesisConfig
+		want   []string
+	}{
+		{"zero value", tts.SynthesisConfig{}, nil},
+		{"valid", tts.SynthesisConfig{OutputFormat: "ogg_opus", SampleRate: 48000, Speed: 1}, nil},
+		{"format typo", tts.SynthesisConfig{OutputFormat: "mp4"}, []string{`unsupported output format "mp4" (supported: aac, alaw`}},
+		{
+			"output rate",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtOutputSampleRate: 16000}},
+			nil,
+		},
+		{
+			"output rate not an int",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtOutputSampleRate: "16000"}},
+			[]string{"must be an int"},
+		},
+		{
+			"output rate not positive",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtOutputSampleRate: 0}},
+			[]string{"must be positive"},
+		},
+		{
+			"output rate with mp3",
+			tts.SynthesisConfig{OutputFormat: "mp3", Extensions: map[string]any{ExtOutputSampleRate: 16000}},
+			[]string{"requires linear16"},
+		},
+		{
+			"extra",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtExtra: map[string]string{"team": "voice"}}},
+			nil,
+		},
+		{
+			"extra not a string map",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtExtra: map[string]any{"team": "voice"}}},
+			[]string{"must be a map[string]string"},
+		},
+		{
+			"extra with empty key",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtExtra: map[string]string{"": "voice"}}},
+			[]string{"keys must not be empty"},
+		},
+		{
+			"language",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtLanguage: "en-GB"}},
+			nil,
+		},
+		{
+			"language not a string",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtLanguage: 7}},
+			[]string{"must be a string"},
+		},
+		{
+			"mp3 bit rate",
+			tts.SynthesisConfig{OutputFormat: "mp3", Extensions: map[string]any{ExtBitRate: 48000}},
+			nil,
+		},
+		{
+			"mp3 bit rate not offered",
+			tts.SynthesisConfig{OutputFormat: "mp3", Extensions: map[string]any{ExtBitRate: 64000}},
+			[]string{"for mp3 must be 32000 or 48000, got 64000"},
+		},
+		{
+			"opus bit rate out of range",
+			tts.SynthesisConfig{OutputFormat: "opus", Extensions: map[string]any{ExtBitRate: 1000}},
+			[]string{"for opus must be 4000 to 650000"},
+		},
+		{
+			"bit rate not an int",
+			tts.SynthesisConfig{OutputFormat: "aac", Extensions: map[string]any{ExtBitRate: "96k"}},
+			[]string{"must be an int"},
+		},
+		{
+			"bit rate ignored for linear16",
+			tts.SynthesisConfig{Extensions: map[string]any{ExtBitRate: 64000}},
+			nil,
+		},
+		{
+			"several problems",
+			tts.SynthesisConfig{OutputFormat: "wave", SampleRate: -8000, Speed: -1},
+			[]string{"wave", "SampleRate", "Speed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSynthesisConfig(tt.config)
+			checkProblems(t, err, tts.ErrInvalidConfig, tt.want)
+		})
+	}
+}
+
+func TestValidateSynthesisConfig_ModelEncodings(t *testing.T) {
+	saved := ModelEncodings
+	t.Cleanup(func() { ModelEncodings = saved })
+	ModelEncodings = map[string][]string{
+		"aura-":          {"linear16", "mp3"},
+		"aura-2-":        {"linear16", "mulaw"},
+		"aura-2-luna-en": {"linear16"},
+	}
+
+	tests := []struct {
+		name   string
+		config tts.SynthesisConfig
+		want   []string
+	}{
+		{"supported pair", tts.SynthesisConfig{VoiceID: "aura-2-thalia-en", OutputFormat: "ulaw"}, nil},
+		{
+			"unsupported pair",
+			tts.SynthesisConfig{VoiceID: "aura-2-thalia-en", OutputFormat: "mp3"},
+			[]string{`model "aura-2-thalia-en" does not support mp3 output (supported: linear16, mulaw)`},
+		},
+		{"default encoding", tts.SynthesisConfig{Model: "aura-2-luna-en"}, nil},
+		{
+			"exact model overrides prefix",
+			tts.SynthesisConfig{Model: "aura-2-luna-en", OutputFormat: "mulaw"},
+			[]string{`model "aura-2-luna-en" does not support mulaw`},
+		},
+		{
+			"default model",
+			tts.SynthesisConfig{OutputFormat: "flac"},
+			[]string{`model "` + DefaultTTSModel + `" does not support flac`},
+		},
+		{"unlisted model", tts.SynthesisConfig{Model: "custom-voice", OutputFormat: "flac"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSynthesisConfig(tt.config)
+			checkProblems(t, err, tts.ErrInvalidConfig, tt.want)
+		})
+	}
+}
+
+// checkProblems asserts that err is nil when want is empty, and otherwise
+// wraps target and reports one problem per line, each containing the
+// corresponding entry of want.
+func checkProblems(t *testing.T, err, target error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+	if !errors.Is(err, target) {
+		t.Fatalf("error = %v, want %v", err, target)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(lines), len(want), err)
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("problem %d = %q, want it to mention %q", i, lines[i], w)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package omnivoice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotWAV is returned by ReadWAVInfo when the input is not a RIFF/WAVE stream.
+var ErrNotWAV = errors.New("not a WAV stream")
+
+// ErrTruncatedWAV is returned by CheckWAVLength when a WAV stream holds less
+// audio than its header declares.
+var ErrTruncatedWAV = errors.New("truncated WAV stream")
+
+// wavUnknownSize is the data chunk size written by streaming encoders that
+// do not know the final length.
+const wavUnknownSize = 0xFFFFFFFF
+
+// WAVInfo describes the format and data chunk of a RIFF/WAVE stream.
+type WAVInfo struct {
+	// AudioFormat is the WAVE format tag (1 = PCM, 6 = A-law, 7 = mu-law).
+	AudioFormat int
+
+	// Channels is the number of interleaved channels.
+	Channels int
+
+	// SampleRate is the number of samples per second.
+	SampleRate int
+
+	// BitsPerSample is the sample width in bits.
+	BitsPerSample int
+
+	// DataOffset is the byte offset of the first audio sample.
+	DataOffset int64
+
+	// DataSize is the length of the audio data declared by the data chunk header.
+	DataSize int64
+}
+
+// ByteRate returns the number of audio bytes per second.
+func (w *WAVInfo) ByteRate() int {
+	return w.SampleRate * w.Channels * w.BitsPerSample / 8
+}
+
+// Duration returns the playback duration of the declared audio data.
+func (w *WAVInfo) Duration() time.Duration {
+	rate := w.ByteRate()
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(w.DataSize * int64(time.Second) / int64(rate))
+}
+
+// wavFormatTags holds the WAVE format tag of the headerless Deepgram
+// encodings that can be stored in a WAV file as is.
+var wavFormatTags = map[string]int{
+	"linear16": 1,
+	"alaw":     6,
+	"mulaw":    7,
+}
+
+// NewWAVInfo returns the format of mono audio in the given Deepgram encoding
+// at sampleRate, with no data. It returns false for encodings other than
+// linear16, mulaw, and alaw.
+func NewWAVInfo(encoding string, sampleRate int) (*WAVInfo, bool) {
+	tag, ok := wavFormatTags[encoding]
+	if !ok {
+		return nil, false
+	}
+	return &WAVInfo{
+		AudioFormat:   tag,
+		Channels:      1,
+		SampleRate:    sampleRate,
+		BitsPerSample: pcmBytesPerSample[encoding] * 8,
+		DataOffset:    wavHeaderSize,
+	}, true
+}
+
+// wavHeaderSize is the length of the header written by Header.
+const wavHeaderSize = 44
+
+// Header returns a canonical 44-byte RIFF/WAVE header declaring DataSize
+// bytes of audio, followed by a pad byte if DataSize is odd.
+func (w *WAVInfo) Header() []byte {
+	blockAlign := w.Channels * w.BitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.Grow(wavHeaderSize)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(wavHeaderSize-8+w.DataSize+w.DataSize%2))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(w.AudioFormat))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(w.Channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(w.SampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(w.ByteRate()))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(w.BitsPerSample))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(w.DataSize))
+	return buf.Bytes()
+}
+
+// ReadWAVInfo reads a RIFF/WAVE header from r, walking the chunk list up to
+// the start of the data chunk. It returns ErrNotWAV if r does not begin with
+// a RIFF/WAVE signature.
+func ReadWAVInfo(r io.Reader) (*WAVInfo, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotWAV
+		}
+		return nil, err
+	}
+	if !bytes.Equal(riff[0:4], []byte("RIFF")) || !bytes.Equal(riff[8:12], []byte("WAVE")) {
+		return nil, ErrNotWAV
+	}
+
+	info := &WAVInfo{}
+	offset := int64(len(riff))
+	haveFormat := false
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, fmt.Errorf("invalid WAV header: missing data chunk: %w", err)
+		}
+		offset += int64(len(header))
+
+		id := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("invalid WAV header: fmt chunk too short (%d bytes)", size)
+			}
+			fmtChunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return nil, fmt.Errorf("invalid WAV header: truncated fmt chunk: %w", err)
+			}
+			info.AudioFormat = int(binary.LittleEndian.Uint16(fmtChunk[0:2]))
+			info.Channels = int(binary.LittleEndian.Uint16(fmtChunk[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:8]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(fmtChunk[14:16]))
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, errors.New("invalid WAV header: data chunk before fmt chunk")
+			}
+			info.DataOffset = offset
+			info.DataSize = size
+			return info, nil
+		default:
+			// Skip unknown chunks (LIST, fact, ...); chunks are word aligned
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("invalid WAV header: truncated %q chunk: %w", id, err)
+			}
+		}
+
+		offset += size + size%2
+	}
+}
+
+// WAVDuration reads the WAV header from r, a stream of size bytes, and
+// returns the playback duration of its audio. WAV written with a
+// placeholder length of zero or 0xFFFFFFFF by a streaming encoder is
+// measured by the bytes after the header instead, or reported as 0 when
+// size is negative because the length of the stream is unknown.
+func WAVDuration(r io.Reader, size int64) (time.Duration, error) {
+	info, err := ReadWAVInfo(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if info.DataSize == 0 || info.DataSize == wavUnknownSize {
+		if size < 0 {
+			return 0, nil
+		}
+		info.DataSize = max(size-info.DataOffset, 0)
+	}
+	return info.Duration(), nil
+}
+
+// CheckWAVLength reads the WAV header from r, a stream of size bytes, and
+// returns an error wrapping ErrTruncatedWAV if the data chunk declares more
+// audio than the stream holds, or if the header itself is cut short. Input
+// that is not WAV, and WAV written with a placeholder length of zero or
+// 0xFFFFFFFF by a streaming encoder, is not checked.
+func CheckWAVLength(r io.Reader, size int64) error {
+	info, err := ReadWAVInfo(r)
+	if errors.Is(err, ErrNotWAV) {
+		return nil
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: %w", ErrTruncatedWAV, err)
+		}
+		return err
+	}
+
+	if info.DataSize == 0 || info.DataSize == wavUnknownSize {
+		return nil
+	}
+	if actual := size - info.DataOffset; info.DataSize > actual {
+		return fmt.Errorf("%w: data chunk declares %d bytes but only %d are present", ErrTruncatedWAV, info.DataSize, max(actual, 0))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package omnivoice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// buildWAV returns a PCM WAV stream with the given format and data length,
+// optionally preceded by a LIST chunk between fmt and data.
+func buildWAV(sampleRate, channels, bits, dataSize int, withList bool) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*channels*bits/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels*bits/8))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(bits))
+
+	if withList {
+		buf.WriteString("LIST")
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(3))
+		buf.Write([]byte{'a', 'b', 'c', 0}) // odd size plus pad byte
+	}
+
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+func TestReadWAVInfo(t *testing.T) {
+	// 2 seconds of 16 kHz mono 16-bit PCM
+	wav := buildWAV(16000, 1, 16, 64000, false)
+
+	info, err := ReadWAVInfo(bytes.NewReader(wav))
+	if err != nil {
+		t.Fatalf("ReadWAVInfo() error = %v", err)
+	}
+	if info.AudioFormat != 1 || info.Channels != 1 || info.SampleRate != 16000 || info.BitsPerSample != 16 {
+		t.Errorf("ReadWAVInfo() format = %+v", info)
+	}
+	if info.DataOffset != 44 {
+		t.Errorf("DataOffset = %d, want 44", info.DataOffset)
+	}
+	if got := info.Duration(); got != 2*time.Second {
+		t.Errorf("Duration() = %v, want 2s", got)
+	}
+}
+
+func TestReadWAVInfo_SkipsUnknownChunks(t *testing.T) {
+	wav := buildWAV(8000, 1, 8, 8000, true)
+
+	info, err := ReadWAVInfo(bytes.NewReader(wav))
+	if err != nil {
+		t.Fatalf("ReadWAVInfo() error = %v", err)
+	}
+	if info.DataOffset != 56 {
+		t.Errorf("DataOffset = %d, want 56", info.DataOffset)
+	}
+	if got := info.Duration(); got != time.Second {
+		t.Errorf("Duration() = %v, want 1s", got)
+	}
+}
+
+func TestReadWAVInfo_NotWAV(t *testing.T) {
+	for _, input := range [][]byte{
+		nil,
+		[]byte("ID3"),
+		[]byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00"),
+	} {
+		if _, err := ReadWAVInfo(bytes.NewReader(input)); !errors.Is(err, ErrNotWAV) {
+			t.Errorf("ReadWAVInfo(%q) error = %v, want ErrNotWAV", input, err)
+		}
+	}
+}
+
+func TestWAVDuration(t *testing.T) {
+	valid := buildWAV(16000, 1, 16, 32000, false)
+	placeholder := buildWAV(16000, 1, 16, 32000, false)
+	binary.LittleEndian.PutUint32(placeholder[40:44], 0xFFFFFFFF)
+	empty := buildWAV(16000, 1, 16, 32000, false)
+	binary.LittleEndian.PutUint32(empty[40:44], 0)
+
+	tests := []struct {
+		name  string
+		input []byte
+		size  int64
+		want  time.Duration
+	}{
+		{"declared length", valid, int64(len(valid)), time.Second},
+		{"placeholder length", placeholder, int64(len(placeholder)), time.Second},
+		{"zero length", empty, int64(len(empty)), time.Second},
+		{"placeholder length of unknown stream", placeholder, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WAVDuration(bytes.NewReader(tt.input), tt.size)
+			if err != nil {
+				t.Fatalf("WAVDuration() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("WAVDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := WAVDuration(bytes.NewReader([]byte("fLaC\x00\x00\x00\x22")), 8); !errors.Is(err, ErrNotWAV) {
+		t.Errorf("WAVDuration(flac) error = %v, want ErrNotWAV", err)
+	}
+}
+
+func TestCheckWAVLength(t *testing.T) {
+	valid := buildWAV(16000, 1, 16, 3200, false)
+	truncated := valid[:len(valid)-1000]
+	placeholder := buildWAV(16000, 1, 16, 3200, false)
+	binary.LittleEndian.PutUint32(placeholder[40:44], 0xFFFFFFFF)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"truncated data", truncated, true},
+		{"truncated header", valid[:30], true},
+		{"placeholder length", placeholder, false},
+		{"not WAV", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckWAVLength(bytes.NewReader(tt.input), int64(len(tt.input)))
+			if tt.wantErr && !errors.Is(err, ErrTruncatedWAV) {
+				t.Errorf("CheckWAVLength() error = %v, want ErrTruncatedWAV", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("CheckWAVLength() error = %v, want nil", err)
+			}
+		})
+	}
+}